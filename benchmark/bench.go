@@ -18,6 +18,9 @@ const (
 
 	// KeyRange is the range of keys for mixed benchmarks
 	KeyRange = 100000
+
+	// FillCount is the capacity filled from empty by Fill benchmarks
+	FillCount = 100000
 )
 
 // SetupBenchmark ensures we use all CPUs and resets the timer properly.
@@ -28,6 +31,15 @@ func SetupBenchmark(b *testing.B) {
 	b.ResetTimer()
 }
 
+// WithParallelism makes the helpers in this package run n goroutines per
+// GOMAXPROCS instead of one, so a benchmark can sweep goroutine counts to plot
+// contention curves. Call it before Put, Get, Mixed or Delete; it applies to b
+// only, so use it inside a b.Run sub-benchmark per level. n must be positive.
+func WithParallelism(b *testing.B, n int) {
+	b.Helper()
+	b.SetParallelism(n)
+}
+
 // TestMain sets up global runtime parameters for benchmark packages.
 func TestMain(m *testing.M) {
 	runtime.GOMAXPROCS(runtime.NumCPU())
@@ -41,6 +53,12 @@ type PutGetter[K comparable, V any] interface {
 	Shutdown(ctx context.Context)
 }
 
+// PutGetDeleter extends PutGetter with Delete for benchmarks that remove keys.
+type PutGetDeleter[K comparable, V any] interface {
+	PutGetter[K, V]
+	Delete(ctx context.Context, key K) (bool, error)
+}
+
 // PreloadCache loads the given number of entries into a cache before benchmarking.
 func PreloadCache[K comparable, V any](
 	ctx context.Context,
@@ -75,6 +93,36 @@ func Put[K comparable, V any](
 	})
 }
 
+// Fill runs a reusable benchmark that creates a cache and fills it with
+// count entries on each iteration, measuring construction and the initial
+// growth of the cache's internal structures. The cache should be created with
+// a capacity of at least count so that nothing is evicted.
+func Fill[K comparable, V any](
+	b *testing.B,
+	newCache func() PutGetter[K, V],
+	count int,
+	genKey func(int) K,
+	genVal func(int) V,
+) {
+	b.Helper()
+	ctx := context.Background()
+	keys := make([]K, count)
+	vals := make([]V, count)
+	for i := range count {
+		keys[i], vals[i] = genKey(i), genVal(i)
+	}
+	SetupBenchmark(b)
+	for b.Loop() {
+		c := newCache()
+		for i := range count {
+			_ = c.Put(ctx, keys[i], vals[i])
+		}
+		b.StopTimer()
+		c.Shutdown(ctx)
+		b.StartTimer()
+	}
+}
+
 // Get runs a reusable benchmark for Get operations.
 func Get[K comparable, V any](
 	b *testing.B,
@@ -98,6 +146,62 @@ func Get[K comparable, V any](
 	})
 }
 
+// Delete runs a reusable benchmark for Delete operations. The cache is
+// preloaded and a key whose delete misses is put back, so the working set
+// stays populated and each delete exercises the eviction callback and entry
+// release paths rather than a plain lookup miss.
+func Delete[K comparable, V any](
+	b *testing.B,
+	newCache func() PutGetDeleter[K, V],
+	preloadCount int,
+	genKey func(int) K,
+	genVal func(int) V,
+) {
+	b.Helper()
+	ctx := context.Background()
+	c := newCache()
+	defer c.Shutdown(ctx)
+	PreloadCache(ctx, c, preloadCount, genKey, genVal)
+	SetupBenchmark(b)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := i % preloadCount
+			if ok, _ := c.Delete(ctx, genKey(key)); !ok {
+				_ = c.Put(ctx, genKey(key), genVal(key))
+			}
+			i++
+		}
+	})
+}
+
+// Churn runs a reusable benchmark that keeps a cache of the given capacity
+// full while deleting entries from the middle of its age range: each
+// iteration deletes the key put capacity/2 iterations earlier and puts a new
+// one. It catches Put paths whose cost grows with the holes a Delete leaves
+// behind. It runs on one goroutine so that the deleted key is always
+// present.
+func Churn[K comparable, V any](
+	b *testing.B,
+	newCache func() PutGetDeleter[K, V],
+	capacity int,
+	genKey func(int) K,
+	genVal func(int) V,
+) {
+	b.Helper()
+	ctx := context.Background()
+	c := newCache()
+	defer c.Shutdown(ctx)
+	PreloadCache(ctx, c, capacity, genKey, genVal)
+	SetupBenchmark(b)
+	i := capacity
+	for b.Loop() {
+		_, _ = c.Delete(ctx, genKey(i-capacity/2))
+		_ = c.Put(ctx, genKey(i), genVal(i))
+		i++
+	}
+}
+
 // Mixed runs a reusable benchmark for mixed Put/Get operations with a configurable percentage of Put operations.
 func Mixed[K comparable, V any](
 	b *testing.B,
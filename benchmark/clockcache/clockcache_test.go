@@ -0,0 +1,40 @@
+package clockcache_test
+
+import (
+	"testing"
+
+	"github.com/mcphone2004/cache/benchmark"
+	"github.com/mcphone2004/cache/clockcache"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func newCache() benchmark.PutGetter[int, string] {
+	c, _ := clockcache.New[int, string](cachetypes.WithCapacity(benchmark.CacheCapacity))
+	return c
+}
+
+func BenchmarkClockGet(b *testing.B) {
+	benchmark.Get(b,
+		newCache,
+		benchmark.PreloadCount,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func BenchmarkClockPut(b *testing.B) {
+	benchmark.Put(b,
+		newCache,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func BenchmarkClockMixed(b *testing.B) {
+	benchmark.Mixed(b,
+		newCache,
+		benchmark.KeyRange,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
@@ -1,10 +1,13 @@
 package lru_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/mcphone2004/cache/benchmark"
 	"github.com/mcphone2004/cache/lru"
+	"github.com/mcphone2004/cache/lru2"
 	cachetypes "github.com/mcphone2004/cache/types"
 )
 
@@ -69,3 +72,95 @@ func BenchmarkLRUMixedLargeValue(b *testing.B) {
 		benchmark.GenLargeValue,
 	)
 }
+
+// BenchmarkLRUReadHeavy compares lru, which serializes every operation on a
+// single mutex, against lru2, which splits map and queue locks, under a
+// read-heavy (5% Put) workload whose keys fit in the cache.
+func BenchmarkLRUReadHeavy(b *testing.B) {
+	impls := []struct {
+		name     string
+		newCache func() benchmark.PutGetter[int, string]
+	}{
+		{"lru", newCache},
+		{"lru2", func() benchmark.PutGetter[int, string] {
+			c, _ := lru2.New[int, string](cachetypes.WithCapacity(benchmark.CacheCapacity))
+			return c
+		}},
+	}
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			benchmark.MixedPutPercent(b,
+				impl.newCache,
+				benchmark.CacheCapacity,
+				benchmark.GenKey,
+				benchmark.GenValue,
+				5,
+			)
+		})
+	}
+}
+
+// BenchmarkLRUContention runs the read-heavy workload of BenchmarkLRUReadHeavy
+// at increasing goroutine counts, to show how the single-mutex lru and the
+// split-lock lru2 degrade under contention.
+func BenchmarkLRUContention(b *testing.B) {
+	impls := []struct {
+		name     string
+		newCache func() benchmark.PutGetter[int, string]
+	}{
+		{"lru", newCache},
+		{"lru2", func() benchmark.PutGetter[int, string] {
+			c, _ := lru2.New[int, string](cachetypes.WithCapacity(benchmark.CacheCapacity))
+			return c
+		}},
+	}
+	for _, impl := range impls {
+		for _, n := range []int{1, 2, 4, 8, 16, 32} {
+			b.Run(fmt.Sprintf("%s/parallelism=%d", impl.name, n), func(b *testing.B) {
+				benchmark.WithParallelism(b, n)
+				benchmark.MixedPutPercent(b,
+					impl.newCache,
+					benchmark.CacheCapacity,
+					benchmark.GenKey,
+					benchmark.GenValue,
+					5,
+				)
+			})
+		}
+	}
+}
+
+// newDeleteCache sets a no-op eviction callback so that Delete measures the
+// callback dispatch that benchmark.Delete describes.
+func newDeleteCache() benchmark.PutGetDeleter[int, string] {
+	c, _ := lru.New[int, string](
+		cachetypes.WithCapacity(benchmark.CacheCapacity),
+		cachetypes.WithEvictionCB(func(context.Context, int, string) {}),
+	)
+	return c
+}
+
+func BenchmarkLRUDelete(b *testing.B) {
+	benchmark.Delete(b,
+		newDeleteCache,
+		benchmark.CacheCapacity,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func newFillCache() benchmark.PutGetter[int, string] {
+	c, _ := lru.New[int, string](cachetypes.WithCapacity(benchmark.FillCount))
+	return c
+}
+
+// BenchmarkLRUFill fills an empty cache to its capacity; the items map is
+// sized to the capacity up front, so it never rehashes while filling.
+func BenchmarkLRUFill(b *testing.B) {
+	benchmark.Fill(b,
+		newFillCache,
+		benchmark.FillCount,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
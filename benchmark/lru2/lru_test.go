@@ -1,6 +1,7 @@
 package lru2_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/mcphone2004/cache/benchmark"
@@ -69,3 +70,38 @@ func BenchmarkLRU2MixedLargeValue(b *testing.B) {
 		benchmark.GenLargeValue,
 	)
 }
+
+// newDeleteCache sets a no-op eviction callback so that Delete measures the
+// callback dispatch that benchmark.Delete describes.
+func newDeleteCache() benchmark.PutGetDeleter[int, string] {
+	c, _ := lru2.New[int, string](
+		cachetypes.WithCapacity(benchmark.CacheCapacity),
+		cachetypes.WithEvictionCB(func(context.Context, int, string) {}),
+	)
+	return c
+}
+
+func BenchmarkLRU2Delete(b *testing.B) {
+	benchmark.Delete(b,
+		newDeleteCache,
+		benchmark.CacheCapacity,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func newFillCache() benchmark.PutGetter[int, string] {
+	c, _ := lru2.New[int, string](cachetypes.WithCapacity(benchmark.FillCount))
+	return c
+}
+
+// BenchmarkLRU2Fill fills an empty cache to its capacity; the items map is
+// sized to the capacity up front, so it never rehashes while filling.
+func BenchmarkLRU2Fill(b *testing.B) {
+	benchmark.Fill(b,
+		newFillCache,
+		benchmark.FillCount,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
@@ -0,0 +1,40 @@
+package randomcache_test
+
+import (
+	"testing"
+
+	"github.com/mcphone2004/cache/benchmark"
+	"github.com/mcphone2004/cache/randomcache"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func newCache() benchmark.PutGetter[int, string] {
+	c, _ := randomcache.New[int, string](cachetypes.WithCapacity(benchmark.CacheCapacity))
+	return c
+}
+
+func BenchmarkRandomGet(b *testing.B) {
+	benchmark.Get(b,
+		newCache,
+		benchmark.PreloadCount,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func BenchmarkRandomPut(b *testing.B) {
+	benchmark.Put(b,
+		newCache,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func BenchmarkRandomMixed(b *testing.B) {
+	benchmark.Mixed(b,
+		newCache,
+		benchmark.KeyRange,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
@@ -0,0 +1,52 @@
+package ringcache_test
+
+import (
+	"testing"
+
+	"github.com/mcphone2004/cache/benchmark"
+	"github.com/mcphone2004/cache/ringcache"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func newCache() benchmark.PutGetter[int, string] {
+	c, _ := ringcache.New[int, string](cachetypes.WithCapacity(benchmark.CacheCapacity))
+	return c
+}
+
+func BenchmarkRingGet(b *testing.B) {
+	benchmark.Get(b,
+		newCache,
+		benchmark.PreloadCount,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func BenchmarkRingPut(b *testing.B) {
+	benchmark.Put(b,
+		newCache,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func BenchmarkRingMixed(b *testing.B) {
+	benchmark.Mixed(b,
+		newCache,
+		benchmark.KeyRange,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func BenchmarkRingChurn(b *testing.B) {
+	benchmark.Churn(b,
+		func() benchmark.PutGetDeleter[int, string] {
+			c, _ := ringcache.New[int, string](cachetypes.WithCapacity(benchmark.CacheCapacity))
+			return c
+		},
+		benchmark.CacheCapacity,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
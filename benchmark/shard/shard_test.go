@@ -8,6 +8,7 @@ import (
 	"github.com/mcphone2004/cache/lru"
 	"github.com/mcphone2004/cache/lru2"
 	"github.com/mcphone2004/cache/shard"
+	"github.com/mcphone2004/cache/twoq"
 	cachetypes "github.com/mcphone2004/cache/types"
 )
 
@@ -288,3 +289,52 @@ func Benchmark8ShardLRU2MixedLargeValue(b *testing.B) {
 		benchmark.GenLargeValue,
 	)
 }
+
+// new8ShardTwoQCache creates a shard cache with 8 shards, each shard backed by a 2Q cache.
+func new8ShardTwoQCache() benchmark.PutGetter[int, string] {
+	s, _ := shard.New(
+		shard.WithCapacity[int, string](benchmark.CacheCapacity), // each shard can hold 1024 items
+		// minimum of 8 shards
+		shard.WithMinShards[int, string](8), // minimum of 8 shards
+		// simple shard selector: use key mod 8
+		shard.WithShardsFn[int, string](func(key int, maxShard uint) uint {
+			if key < 0 {
+				key = -key // handle negative keys
+			}
+			return uint(key) % maxShard //nolint:gosec // key is non-negative after the guard above
+		}),
+		// each shard is its own 2Q cache
+		shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+			return twoq.New(twoq.WithCapacity[int, string](capacity))
+		}),
+	)
+	return s
+}
+
+func Benchmark8ShardTwoQGet(b *testing.B) {
+	benchmark.Get(
+		b,
+		new8ShardTwoQCache,
+		benchmark.PreloadCount,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func Benchmark8ShardTwoQPut(b *testing.B) {
+	benchmark.Put(
+		b,
+		new8ShardTwoQCache,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
+
+func Benchmark8ShardTwoQMixed(b *testing.B) {
+	benchmark.Mixed(b,
+		new8ShardTwoQCache,
+		benchmark.KeyRange,
+		benchmark.GenKey,
+		benchmark.GenValue,
+	)
+}
@@ -0,0 +1,41 @@
+package ttllru_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mcphone2004/cache/ttllru"
+)
+
+// BenchmarkTTLLRUPutFull measures Put into a full cache whose entries sit in
+// distinct, unexpired expiry buckets, so every Put looks for an expired entry
+// before evicting the least recently used one. The cost per Put should not
+// grow with the number of buckets.
+func BenchmarkTTLLRUPutFull(b *testing.B) {
+	for _, size := range []int{1_000, 20_000} {
+		b.Run(fmt.Sprintf("entries=%d", size), func(b *testing.B) {
+			ctx := context.Background()
+			c, err := ttllru.New[int, string](
+				ttllru.WithCapacity[int, string](uint(size)), //nolint:gosec // size is a small positive constant
+				ttllru.WithBucketSize[int, string](time.Millisecond),
+			)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer c.Shutdown(ctx)
+			// one bucket per entry, all expiring long after the benchmark
+			ttl := func(i int) time.Duration {
+				return time.Hour + time.Duration(i)*time.Millisecond
+			}
+			for i := range size {
+				_ = c.PutWithTTL(ctx, i, "v", ttl(i))
+			}
+			b.ResetTimer()
+			for i := range b.N {
+				_ = c.PutWithTTL(ctx, size+i, "v", ttl(size+i))
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Package clockcache provides a fixed-capacity cache evicted with the CLOCK
+// (second chance) algorithm. Entries live in a circular buffer of slots, each
+// with a reference bit that Get sets. To make room, a hand sweeps the buffer,
+// clearing set bits, and evicts the first entry whose bit is already clear.
+// This approximates LRU without moving list nodes on every access: a hit only
+// sets a bit under a read lock, so concurrent Gets do not serialize.
+package clockcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// slot is one position of the circular buffer.
+type slot[K comparable, V any] struct {
+	key   K
+	value V
+	used  bool
+	// ref is set by Get under the read lock, so it is atomic.
+	ref atomic.Bool
+}
+
+// Cache is a thread-safe CLOCK cache.
+type Cache[K comparable, V any] struct {
+	mu         sync.RWMutex
+	isShutdown bool
+
+	items map[K]int // key to slot index
+	slots []slot[K, V]
+	free  []int // indexes of unused slots
+	hand  int
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+}
+
+// Ensure Cache implements the Cache interface.
+var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
+
+// New creates a new CLOCK cache with the given capacity.
+func New[K comparable, V any](options ...func(o *cachetypes.Options)) (
+	*Cache[K, V], error) {
+	var o cachetypes.Options
+	for _, cb := range options {
+		cb(&o)
+	}
+
+	o1, err := internal.ToOptions[K, V](o)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache[K, V]{
+		items:     make(map[K]int, o1.Capacity),
+		slots:     make([]slot[K, V], o1.Capacity),
+		free:      make([]int, 0, o1.Capacity),
+		evictor:   internal.NewEvictor(o1),
+		sizeLimit: o1.SizeLimit,
+	}
+	c.resetFree()
+	return c, nil
+}
+
+// resetFree marks every slot as unused, handing out the lowest index first.
+func (c *Cache[K, V]) resetFree() {
+	c.free = c.free[:0]
+	for i := len(c.slots) - 1; i >= 0; i-- {
+		c.free = append(c.free, i)
+	}
+}
+
+// Get retrieves a value from the cache and sets its reference bit.
+func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var zero V
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	s := &c.slots[i]
+	s.ref.Store(true)
+	return s.value, true, nil
+}
+
+// Has reports whether the key is present without setting its reference bit.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.items[key]
+	return ok, nil
+}
+
+// Put inserts or updates a value in the cache. Updating an existing key sets
+// its reference bit; a new key starts with the bit clear and, when the cache
+// is full, replaces the entry chosen by the clock hand. It returns
+// cachetypes.ErrEntryTooLarge if the entry exceeds a configured size limit.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	if i, ok := c.items[key]; ok {
+		s := &c.slots[i]
+		s.value = value
+		s.ref.Store(true)
+		c.mu.Unlock()
+		return nil
+	}
+	var evicted cachetypes.Entry[K, V]
+	var hasEvicted bool
+	if len(c.free) == 0 {
+		evicted = c.evict()
+		hasEvicted = true
+	}
+	i := c.free[len(c.free)-1]
+	c.free = c.free[:len(c.free)-1]
+	s := &c.slots[i]
+	s.key, s.value, s.used = key, value, true
+	s.ref.Store(false)
+	c.items[key] = i
+	c.mu.Unlock()
+	if hasEvicted {
+		c.evictor.Evict(ctx, evicted.Key, evicted.Value)
+	}
+	return nil
+}
+
+// evict advances the hand, giving each referenced entry a second chance by
+// clearing its bit, until it reaches an unreferenced entry, which it removes
+// and returns. It must be called with the write lock held and the cache full,
+// and terminates within two turns of the buffer.
+func (c *Cache[K, V]) evict() cachetypes.Entry[K, V] {
+	for {
+		i := c.hand
+		c.hand = (c.hand + 1) % len(c.slots)
+		s := &c.slots[i]
+		if s.ref.Swap(false) {
+			continue
+		}
+		return c.remove(i)
+	}
+}
+
+// remove clears slot i, returns it to the free list and returns its entry.
+// It must be called with the write lock held.
+func (c *Cache[K, V]) remove(i int) cachetypes.Entry[K, V] {
+	s := &c.slots[i]
+	en := cachetypes.Entry[K, V]{Key: s.key, Value: s.value}
+	delete(c.items, s.key)
+	var zero slot[K, V]
+	s.key, s.value, s.used = zero.key, zero.value, false
+	s.ref.Store(false)
+	c.free = append(c.free, i)
+	return en
+}
+
+// Replace updates the value of an existing key, sets its reference bit and
+// returns the previous value. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	s := &c.slots[i]
+	old := s.value
+	s.value = value
+	s.ref.Store(true)
+	return old, true, nil
+}
+
+// Delete removes the entry with the specified key from the cache.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete atomically removes the entry and returns its value.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	var zero V
+	if c.isShutdown {
+		c.mu.Unlock()
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return zero, false, nil
+	}
+	en := c.remove(i)
+	c.mu.Unlock() // Unlock before callback to avoid deadlock
+	c.evictor.Evict(ctx, en.Key, en.Value)
+	return en.Value, true, nil
+}
+
+// Size returns the current number of items in the cache.
+func (c *Cache[K, V]) Size() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return len(c.items), nil
+}
+
+// Capacity returns the maximum number of items the cache can hold.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return len(c.slots), nil
+}
+
+// Reset clears the cache and calls the eviction callback for each removed item.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := c.drain()
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	return nil
+}
+
+// drain empties the buffer and returns its entries; the caller holds mu.
+func (c *Cache[K, V]) drain() []cachetypes.Entry[K, V] {
+	entries := make([]cachetypes.Entry[K, V], 0, len(c.items))
+	var zero slot[K, V]
+	for i := range c.slots {
+		s := &c.slots[i]
+		if s.used {
+			entries = append(entries, cachetypes.Entry[K, V]{Key: s.key, Value: s.value})
+		}
+		s.key, s.value, s.used = zero.key, zero.value, false
+		s.ref.Store(false)
+	}
+	clear(c.items)
+	c.resetFree()
+	c.hand = 0
+	return entries
+}
+
+// Traverse calls fn for each entry in slot order until fn returns false,
+// without setting reference bits. fn runs on a snapshot taken under the lock,
+// so it may call back into the cache.
+func (c *Cache[K, V]) Traverse(ctx context.Context,
+	fn func(context.Context, K, V) bool) error {
+	c.mu.RLock()
+	if c.isShutdown {
+		c.mu.RUnlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := make([]cachetypes.Entry[K, V], 0, len(c.items))
+	for i := range c.slots {
+		if s := &c.slots[i]; s.used {
+			entries = append(entries, cachetypes.Entry[K, V]{Key: s.key, Value: s.value})
+		}
+	}
+	c.mu.RUnlock()
+	for _, en := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(ctx, en.Key, en.Value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Shutdown clears the cache, calling the eviction callback for each item.
+// Every later operation returns ErrShutdown.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return
+	}
+	c.isShutdown = true
+	entries := c.drain()
+	c.items = nil
+	c.slots = nil
+	c.free = nil
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	c.evictor.Close(ctx)
+}
@@ -0,0 +1,146 @@
+package clockcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/clockcache"
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal/testhelper"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func newCache[K comparable, T any](capacity uint, evictionCB func(context.Context, K, T)) (iface.Cache[K, T], error) {
+	return clockcache.New[K, T](
+		cachetypes.WithCapacity(capacity),
+		cachetypes.WithEvictionCB(evictionCB),
+	)
+}
+
+func TestNewCache(t *testing.T) {
+	cache, err := clockcache.New[int, string]()
+	require.Nil(t, cache)
+	var aerr *cachetypes.InvalidOptionsError
+	require.True(t, errors.As(err, &aerr))
+	require.Equal(t, "capacity must be positive", aerr.Error())
+}
+
+func TestReset(t *testing.T) {
+	testhelper.CommonLRUResetTest(t, newCache)
+}
+
+// TestBasic relies on unreferenced entries being evicted in insertion order,
+// which CLOCK shares with LRU.
+func TestBasic(t *testing.T) {
+	testhelper.CommonLRUCacheBasicTest(t, newCache)
+}
+
+func TestUpdate(t *testing.T) {
+	testhelper.CommonLRUCacheUpdateTest(t, newCache)
+}
+
+func TestTraverse(t *testing.T) {
+	testhelper.CommonTraverseTest(t, newCache)
+}
+
+func TestTraverseReentrant(t *testing.T) {
+	testhelper.CommonTraverseReentrantTest(t, newCache)
+}
+
+func TestTraverseCancel(t *testing.T) {
+	testhelper.CommonTraverseCancelTest(t, newCache)
+}
+
+func TestDelete(t *testing.T) {
+	testhelper.CommonDeleteTest(t, newCache)
+	testhelper.CommonDeleteNonExistentTest(t, newCache)
+}
+
+func TestGetMultiIter(t *testing.T) {
+	testhelper.CommonGetMultiIterTest(t, newCache)
+}
+
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache)
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache)
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache)
+}
+
+func TestShutdown(t *testing.T) {
+	testhelper.CommonShutdownTest(t, newCache)
+}
+
+func TestEvictionCallback(t *testing.T) {
+	testhelper.CommonEvictionCallbackTest(t, newCache)
+	testhelper.CommonUpdateNoEvictionTest(t, newCache)
+	testhelper.CommonEvictionCallbackPanicTest(t, newCache)
+}
+
+func TestConcurrent(t *testing.T) {
+	testhelper.CommonConcurrentTest(t, newCache)
+	testhelper.CommonConcurrentStressTest(t, newCache)
+}
+
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache, testhelper.ExactCapacity)
+}
+
+func TestStressShutdown(t *testing.T) {
+	testhelper.CommonStressShutdownTest(t, newCache[int, string])
+}
+
+func TestSecondChance(t *testing.T) {
+	ctx := context.Background()
+	var evicted []int
+	cache, err := clockcache.New[int, string](
+		cachetypes.WithCapacity(3),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for k := 1; k <= 3; k++ {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	// 1 is referenced, so the hand clears its bit and evicts 2 instead
+	_, _, err = cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 4, "v"))
+	require.Equal(t, []int{2}, evicted)
+
+	// Has does not set the bit: 3 is next after the hand and goes
+	_, err = cache.Has(ctx, 3)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 5, "v"))
+	require.Equal(t, []int{2, 3}, evicted)
+
+	// 1 used its second chance; it is evicted when the hand comes round
+	require.NoError(t, cache.Put(ctx, 6, "v"))
+	require.Equal(t, []int{2, 3, 1}, evicted)
+
+	// when every entry is referenced, a full sweep clears them all and the
+	// hand evicts where it started
+	for _, k := range []int{4, 5, 6} {
+		_, found, err := cache.Get(ctx, k)
+		require.NoError(t, err)
+		require.True(t, found)
+	}
+	require.NoError(t, cache.Put(ctx, 7, "v"))
+	require.Equal(t, []int{2, 3, 1, 4}, evicted)
+}
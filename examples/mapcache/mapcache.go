@@ -0,0 +1,56 @@
+// Package main provides an example usage of a map-backed cache.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/mapcache"
+	"github.com/mcphone2004/cache/shard"
+)
+
+func main() {
+	ctx := context.Background()
+
+	// Create an unbounded cache for reference data
+	countries, err := mapcache.New[string, string]()
+	if err != nil {
+		log.Fatalf("failed to create cache: %v", err)
+	}
+	defer countries.Shutdown(ctx)
+
+	_ = countries.Put(ctx, "NZ", "New Zealand")
+	_ = countries.Put(ctx, "JP", "Japan")
+
+	if v, ok, _ := countries.Get(ctx, "NZ"); ok {
+		fmt.Println("NZ is", v)
+	}
+
+	// Capacity 0 means the cache never evicts
+	capacity, _ := countries.Capacity()
+	size, _ := countries.Size()
+	fmt.Println("Capacity:", capacity, "Size:", size)
+
+	// Use map-backed shards when the caller manages the size itself
+	s, err := shard.New(
+		shard.WithCapacity[int, string](1024),
+		shard.WithShardsFn[int, string](func(key int, maxShard uint) uint {
+			return uint(key) % maxShard //nolint:gosec // keys are loop indices 0-9, always non-negative
+		}),
+		shard.WithCacherMaker(func(_ uint) (iface.Cache[int, string], error) {
+			return mapcache.New[int, string]()
+		}),
+	)
+	if err != nil {
+		log.Fatalf("failed to create sharded cache: %v", err)
+	}
+	defer s.Shutdown(ctx)
+
+	for i := range 10 {
+		_ = s.Put(ctx, i, fmt.Sprintf("value-%d", i))
+	}
+	size, _ = s.Size()
+	fmt.Println("Sharded size:", size)
+}
@@ -12,6 +12,9 @@ type Cache[K comparable, V any] interface {
 	// If the key is not found, it returns the zero value of V and false.
 	// If the key is found, it returns the value and true.
 	Get(ctx context.Context, key K) (V, bool, error)
+	// Has reports whether the key is present in the cache without marking it
+	// as recently used.
+	Has(ctx context.Context, key K) (bool, error)
 	// Put inserts or updates a value in the cache.
 	// If the cache exceeds its capacity, it evicts the least recently used item.
 	// If an eviction callback is set, it will be called with the evicted key and
@@ -20,6 +23,14 @@ type Cache[K comparable, V any] interface {
 	// Delete removes an entry from the cache and returns true if the entry was
 	// found and deleted.
 	Delete(ctx context.Context, key K) (bool, error)
+	// GetAndDelete atomically retrieves and removes an entry from the cache.
+	// It returns the removed value and true if the key was found. The eviction
+	// callback, if set, is called exactly once for the removed entry.
+	GetAndDelete(ctx context.Context, key K) (V, bool, error)
+	// Replace atomically updates the value of an existing key and returns
+	// its previous value and true. If the key is absent it does nothing and
+	// returns false.
+	Replace(ctx context.Context, key K, value V) (V, bool, error)
 	// Size returns the current number of items in the cache.
 	Size() (int, error)
 	// Capacity returns the capacity of the cache
@@ -33,3 +44,20 @@ type Cache[K comparable, V any] interface {
 	// Destroy cleans up the cache, releasing any resources it holds.
 	Shutdown(ctx context.Context)
 }
+
+// Toucher is implemented by caches that can mark a key as recently used
+// without returning its value.
+type Toucher[K comparable] interface {
+	// Touch marks the key as recently used and reports whether it was present.
+	Touch(ctx context.Context, key K) (bool, error)
+}
+
+// BatchGetter is implemented by caches that can look up many keys in one
+// call, e.g. a network-backed cache that amortizes round trips. Helpers such
+// as cacheutils.GetMulti use it when available and fall back to Get per key
+// otherwise; in-memory caches need not implement it.
+type BatchGetter[K comparable, V any] interface {
+	// GetBatch returns the values of the keys that were found. Missing keys
+	// are absent from the map.
+	GetBatch(ctx context.Context, keys []K) (map[K]V, error)
+}
@@ -230,6 +230,146 @@ func (_c *MockCache_Get_Call[K, V]) RunAndReturn(run func(ctx context.Context, k
 	return _c
 }
 
+// GetAndDelete provides a mock function for the type MockCache
+func (_mock *MockCache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAndDelete")
+	}
+
+	var r0 V
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, K) (V, bool, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, K) V); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(V)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, K) bool); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, K) error); ok {
+		r2 = returnFunc(ctx, key)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockCache_GetAndDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAndDelete'
+type MockCache_GetAndDelete_Call[K comparable, V any] struct {
+	*mock.Call
+}
+
+// GetAndDelete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key K
+func (_e *MockCache_Expecter[K, V]) GetAndDelete(ctx interface{}, key interface{}) *MockCache_GetAndDelete_Call[K, V] {
+	return &MockCache_GetAndDelete_Call[K, V]{Call: _e.mock.On("GetAndDelete", ctx, key)}
+}
+
+func (_c *MockCache_GetAndDelete_Call[K, V]) Run(run func(ctx context.Context, key K)) *MockCache_GetAndDelete_Call[K, V] {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 K
+		if args[1] != nil {
+			arg1 = args[1].(K)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCache_GetAndDelete_Call[K, V]) Return(v V, b bool, err error) *MockCache_GetAndDelete_Call[K, V] {
+	_c.Call.Return(v, b, err)
+	return _c
+}
+
+func (_c *MockCache_GetAndDelete_Call[K, V]) RunAndReturn(run func(ctx context.Context, key K) (V, bool, error)) *MockCache_GetAndDelete_Call[K, V] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Has provides a mock function for the type MockCache
+func (_mock *MockCache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Has")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, K) (bool, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, K) bool); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, K) error); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCache_Has_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Has'
+type MockCache_Has_Call[K comparable, V any] struct {
+	*mock.Call
+}
+
+// Has is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key K
+func (_e *MockCache_Expecter[K, V]) Has(ctx interface{}, key interface{}) *MockCache_Has_Call[K, V] {
+	return &MockCache_Has_Call[K, V]{Call: _e.mock.On("Has", ctx, key)}
+}
+
+func (_c *MockCache_Has_Call[K, V]) Run(run func(ctx context.Context, key K)) *MockCache_Has_Call[K, V] {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 K
+		if args[1] != nil {
+			arg1 = args[1].(K)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCache_Has_Call[K, V]) Return(b bool, err error) *MockCache_Has_Call[K, V] {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockCache_Has_Call[K, V]) RunAndReturn(run func(ctx context.Context, key K) (bool, error)) *MockCache_Has_Call[K, V] {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Put provides a mock function for the type MockCache
 func (_mock *MockCache[K, V]) Put(ctx context.Context, key K, value V) error {
 	ret := _mock.Called(ctx, key, value)
@@ -293,6 +433,86 @@ func (_c *MockCache_Put_Call[K, V]) RunAndReturn(run func(ctx context.Context, k
 	return _c
 }
 
+// Replace provides a mock function for the type MockCache
+func (_mock *MockCache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	ret := _mock.Called(ctx, key, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Replace")
+	}
+
+	var r0 V
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, K, V) (V, bool, error)); ok {
+		return returnFunc(ctx, key, value)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, K, V) V); ok {
+		r0 = returnFunc(ctx, key, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(V)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, K, V) bool); ok {
+		r1 = returnFunc(ctx, key, value)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, K, V) error); ok {
+		r2 = returnFunc(ctx, key, value)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockCache_Replace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Replace'
+type MockCache_Replace_Call[K comparable, V any] struct {
+	*mock.Call
+}
+
+// Replace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key K
+//   - value V
+func (_e *MockCache_Expecter[K, V]) Replace(ctx interface{}, key interface{}, value interface{}) *MockCache_Replace_Call[K, V] {
+	return &MockCache_Replace_Call[K, V]{Call: _e.mock.On("Replace", ctx, key, value)}
+}
+
+func (_c *MockCache_Replace_Call[K, V]) Run(run func(ctx context.Context, key K, value V)) *MockCache_Replace_Call[K, V] {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 K
+		if args[1] != nil {
+			arg1 = args[1].(K)
+		}
+		var arg2 V
+		if args[2] != nil {
+			arg2 = args[2].(V)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCache_Replace_Call[K, V]) Return(v V, b bool, err error) *MockCache_Replace_Call[K, V] {
+	_c.Call.Return(v, b, err)
+	return _c
+}
+
+func (_c *MockCache_Replace_Call[K, V]) RunAndReturn(run func(ctx context.Context, key K, value V) (V, bool, error)) *MockCache_Replace_Call[K, V] {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Reset provides a mock function for the type MockCache
 func (_mock *MockCache[K, V]) Reset(ctx context.Context) error {
 	ret := _mock.Called(ctx)
@@ -493,3 +713,191 @@ func (_c *MockCache_Traverse_Call[K, V]) RunAndReturn(run func(ctx context.Conte
 	_c.Call.Return(run)
 	return _c
 }
+
+// NewMockToucher creates a new instance of MockToucher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockToucher[K comparable](t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockToucher[K] {
+	mock := &MockToucher[K]{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockToucher is an autogenerated mock type for the Toucher type
+type MockToucher[K comparable] struct {
+	mock.Mock
+}
+
+type MockToucher_Expecter[K comparable] struct {
+	mock *mock.Mock
+}
+
+func (_m *MockToucher[K]) EXPECT() *MockToucher_Expecter[K] {
+	return &MockToucher_Expecter[K]{mock: &_m.Mock}
+}
+
+// Touch provides a mock function for the type MockToucher
+func (_mock *MockToucher[K]) Touch(ctx context.Context, key K) (bool, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Touch")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, K) (bool, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, K) bool); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, K) error); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockToucher_Touch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Touch'
+type MockToucher_Touch_Call[K comparable] struct {
+	*mock.Call
+}
+
+// Touch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key K
+func (_e *MockToucher_Expecter[K]) Touch(ctx interface{}, key interface{}) *MockToucher_Touch_Call[K] {
+	return &MockToucher_Touch_Call[K]{Call: _e.mock.On("Touch", ctx, key)}
+}
+
+func (_c *MockToucher_Touch_Call[K]) Run(run func(ctx context.Context, key K)) *MockToucher_Touch_Call[K] {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 K
+		if args[1] != nil {
+			arg1 = args[1].(K)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockToucher_Touch_Call[K]) Return(b bool, err error) *MockToucher_Touch_Call[K] {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockToucher_Touch_Call[K]) RunAndReturn(run func(ctx context.Context, key K) (bool, error)) *MockToucher_Touch_Call[K] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockBatchGetter creates a new instance of MockBatchGetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockBatchGetter[K comparable, V any](t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockBatchGetter[K, V] {
+	mock := &MockBatchGetter[K, V]{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockBatchGetter is an autogenerated mock type for the BatchGetter type
+type MockBatchGetter[K comparable, V any] struct {
+	mock.Mock
+}
+
+type MockBatchGetter_Expecter[K comparable, V any] struct {
+	mock *mock.Mock
+}
+
+func (_m *MockBatchGetter[K, V]) EXPECT() *MockBatchGetter_Expecter[K, V] {
+	return &MockBatchGetter_Expecter[K, V]{mock: &_m.Mock}
+}
+
+// GetBatch provides a mock function for the type MockBatchGetter
+func (_mock *MockBatchGetter[K, V]) GetBatch(ctx context.Context, keys []K) (map[K]V, error) {
+	ret := _mock.Called(ctx, keys)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBatch")
+	}
+
+	var r0 map[K]V
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []K) (map[K]V, error)); ok {
+		return returnFunc(ctx, keys)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []K) map[K]V); ok {
+		r0 = returnFunc(ctx, keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[K]V)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []K) error); ok {
+		r1 = returnFunc(ctx, keys)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockBatchGetter_GetBatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBatch'
+type MockBatchGetter_GetBatch_Call[K comparable, V any] struct {
+	*mock.Call
+}
+
+// GetBatch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keys []K
+func (_e *MockBatchGetter_Expecter[K, V]) GetBatch(ctx interface{}, keys interface{}) *MockBatchGetter_GetBatch_Call[K, V] {
+	return &MockBatchGetter_GetBatch_Call[K, V]{Call: _e.mock.On("GetBatch", ctx, keys)}
+}
+
+func (_c *MockBatchGetter_GetBatch_Call[K, V]) Run(run func(ctx context.Context, keys []K)) *MockBatchGetter_GetBatch_Call[K, V] {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []K
+		if args[1] != nil {
+			arg1 = args[1].([]K)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBatchGetter_GetBatch_Call[K, V]) Return(m map[K]V, err error) *MockBatchGetter_GetBatch_Call[K, V] {
+	_c.Call.Return(m, err)
+	return _c
+}
+
+func (_c *MockBatchGetter_GetBatch_Call[K, V]) RunAndReturn(run func(ctx context.Context, keys []K) (map[K]V, error)) *MockBatchGetter_GetBatch_Call[K, V] {
+	_c.Call.Return(run)
+	return _c
+}
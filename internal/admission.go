@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"hash/maphash"
+)
+
+const (
+	// sketchDepth is the number of counter rows in the count-min sketch.
+	sketchDepth = 4
+	// sketchMaxCount is where counters saturate, as with 4-bit counters.
+	sketchMaxCount = 15
+	// sketchSampleFactor sets the aging period to this many increments per
+	// counter column.
+	sketchSampleFactor = 10
+	// minSketchWidth keeps small caches from saturating the sketch.
+	minSketchWidth = 256
+)
+
+// Admission is a TinyLFU admission filter. It estimates how often each key
+// is used with a count-min sketch and admits a new key only if it is used
+// more often than the entry it would evict. Counters are halved every
+// sampleSize increments so that old popularity fades. It is not safe for
+// concurrent use; callers serialize access with the cache lock. A nil
+// *Admission records nothing and admits everything.
+type Admission[K comparable] struct {
+	seed       maphash.Seed
+	table      [sketchDepth][]uint8
+	mask       uint64
+	additions  int
+	sampleSize int
+}
+
+// NewAdmission returns an admission filter sized for a cache of capacity.
+func NewAdmission[K comparable](capacity uint) *Admission[K] {
+	width := uint64(minSketchWidth)
+	for width < uint64(capacity) {
+		width <<= 1
+	}
+	a := &Admission[K]{
+		seed:       maphash.MakeSeed(),
+		mask:       width - 1,
+		sampleSize: sketchSampleFactor * int(width), //nolint:gosec // width is bounded by the capacity
+	}
+	for i := range a.table {
+		a.table[i] = make([]uint8, width)
+	}
+	return a
+}
+
+// index returns the counter column for key in row i.
+func (a *Admission[K]) index(h uint64, i int) uint64 {
+	// double hashing: derive the row hashes from the two halves of h
+	return (h + uint64(i)*(h>>32|1)) & a.mask //nolint:gosec // i is a small row number
+}
+
+// Record counts one use of key.
+func (a *Admission[K]) Record(key K) {
+	if a == nil {
+		return
+	}
+	h := maphash.Comparable(a.seed, key)
+	for i := range a.table {
+		if c := &a.table[i][a.index(h, i)]; *c < sketchMaxCount {
+			*c++
+		}
+	}
+	a.additions++
+	if a.additions >= a.sampleSize {
+		a.age()
+	}
+}
+
+// Estimate returns the estimated number of uses of key, which may
+// overcount but never undercounts since the last aging.
+func (a *Admission[K]) Estimate(key K) uint8 {
+	if a == nil {
+		return 0
+	}
+	h := maphash.Comparable(a.seed, key)
+	est := uint8(sketchMaxCount)
+	for i := range a.table {
+		est = min(est, a.table[i][a.index(h, i)])
+	}
+	return est
+}
+
+// Admit reports whether candidate should replace victim in the cache.
+func (a *Admission[K]) Admit(candidate, victim K) bool {
+	if a == nil {
+		return true
+	}
+	return a.Estimate(candidate) > a.Estimate(victim)
+}
+
+// age halves every counter.
+func (a *Admission[K]) age() {
+	for i := range a.table {
+		for j := range a.table[i] {
+			a.table[i][j] >>= 1
+		}
+	}
+	a.additions /= 2
+}
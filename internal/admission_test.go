@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionEstimate(t *testing.T) {
+	a := NewAdmission[int](64)
+	require.Zero(t, a.Estimate(1))
+	for range 5 {
+		a.Record(1)
+	}
+	a.Record(2)
+	require.GreaterOrEqual(t, a.Estimate(1), uint8(5))
+	require.GreaterOrEqual(t, a.Estimate(2), uint8(1))
+	require.True(t, a.Admit(1, 2))
+	require.False(t, a.Admit(2, 1))
+	require.False(t, a.Admit(3, 3), "a tie keeps the victim")
+
+	// counters saturate instead of wrapping
+	for range 100 {
+		a.Record(1)
+	}
+	require.Equal(t, uint8(sketchMaxCount), a.Estimate(1))
+}
+
+func TestAdmissionAging(t *testing.T) {
+	a := NewAdmission[int](16)
+	for range 8 {
+		a.Record(1)
+	}
+	before := a.Estimate(1)
+	// fill the sample with other keys until the counters are halved
+	for k := range a.sampleSize - 8 {
+		a.Record(k + 2)
+	}
+	require.Less(t, a.Estimate(1), before)
+	require.Equal(t, a.sampleSize/2, a.additions)
+}
+
+func TestAdmissionNil(t *testing.T) {
+	var a *Admission[int]
+	a.Record(1)
+	require.Zero(t, a.Estimate(1))
+	require.True(t, a.Admit(1, 2))
+}
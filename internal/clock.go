@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"time"
+
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// SystemClock is the cachetypes.Clock backed by the time package.
+type SystemClock struct{}
+
+// Ensure SystemClock implements the Clock interface.
+var _ cachetypes.Clock = SystemClock{}
+
+// Now returns time.Now().
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer wraps time.NewTimer.
+func (SystemClock) NewTimer(d time.Duration) cachetypes.Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// systemTimer adapts *time.Timer to cachetypes.Timer.
+type systemTimer struct {
+	*time.Timer
+}
+
+// C returns the timer channel.
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// workerKey marks the context passed to callbacks run by an async worker, so
+// that re-entrant calls from the callback can be recognised
+type workerKey struct{}
+
+// evictJob is an eviction callback queued for the async worker
+type evictJob[K comparable, V any] struct {
+	ctx   context.Context
+	key   K
+	value V
+}
+
+// Evictor delivers evicted entries to the user-supplied eviction callback
+// and eviction channel configured in Options.
+//
+// With async eviction the callback runs on a worker goroutine fed by a
+// bounded queue. Dispatch never blocks: when the queue is full the evicting
+// caller runs the callback itself, so a callback that causes evictions in its
+// own cache, with whatever context, cannot wait on a queue that only it
+// drains. A callback that closes the evictor, e.g. by shutting the cache
+// down, must pass on the context it was called with; with any other context
+// Close waits for the worker it is running on and deadlocks.
+type Evictor[K comparable, V any] struct {
+	onEvict   cachetypes.CBFunc[K, V]
+	ch        chan<- cachetypes.Entry[K, V]
+	dropped   atomic.Uint64
+	batch     cachetypes.BatchCBFunc[K, V]
+	batchSize int
+
+	panicHandler func(recovered any)
+
+	// async dispatch; jobs is nil unless AsyncEviction is set
+	mu     sync.RWMutex // guards closed against sends on jobs
+	closed bool
+	jobs   chan evictJob[K, V]
+	done   chan struct{}
+}
+
+// NewEvictor creates an Evictor from the validated options. When async
+// eviction is enabled it starts a worker goroutine that is stopped by Close.
+func NewEvictor[K comparable, V any](o Options[K, V]) *Evictor[K, V] {
+	e := &Evictor[K, V]{
+		onEvict:   o.OnEvict,
+		ch:        o.EvictionChannel,
+		batch:     o.OnBatchEvict,
+		batchSize: int(o.BatchEvictionSize), //nolint:gosec // a chunk larger than MaxInt is never reached
+
+		panicHandler: o.PanicHandler,
+	}
+	if o.AsyncEviction && o.OnEvict != nil {
+		e.jobs = make(chan evictJob[K, V], o.AsyncEvictionQueue)
+		e.done = make(chan struct{})
+		go e.run()
+	}
+	return e
+}
+
+// Callback returns the function to invoke for each evicted entry, or nil
+// when neither a callback nor a channel is configured.
+func (e *Evictor[K, V]) Callback() cachetypes.CBFunc[K, V] {
+	switch {
+	case e.ch == nil && e.onEvict == nil:
+		return nil
+	case e.ch == nil && e.jobs == nil:
+		return e.onEvict
+	}
+	return e.evict
+}
+
+// BatchCallback returns the function to invoke with the entries removed by
+// Reset or Shutdown, or nil when no batch callback is configured.
+func (e *Evictor[K, V]) BatchCallback() cachetypes.BatchCBFunc[K, V] {
+	if e.batch == nil {
+		return nil
+	}
+	return e.evictBatch
+}
+
+// evictBatch sends each entry to the channel, then calls the batch callback
+// once per chunk
+func (e *Evictor[K, V]) evictBatch(ctx context.Context, entries []cachetypes.Entry[K, V]) {
+	for _, en := range entries {
+		e.send(en)
+	}
+	size := e.batchSize
+	if size <= 0 {
+		size = len(entries)
+	}
+	for chunk := range slices.Chunk(entries, max(size, 1)) {
+		e.callBatch(ctx, chunk)
+	}
+}
+
+// callBatch invokes the batch callback for one chunk so that a panic does
+// not prevent the remaining chunks from being delivered
+func (e *Evictor[K, V]) callBatch(ctx context.Context, chunk []cachetypes.Entry[K, V]) {
+	defer recoverPanic(e.panicHandler)
+	e.batch(ctx, chunk)
+}
+
+// send delivers the entry to the eviction channel without blocking
+func (e *Evictor[K, V]) send(en cachetypes.Entry[K, V]) {
+	if e.ch == nil {
+		return
+	}
+	select {
+	case e.ch <- en:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// evict sends the entry to the channel without blocking, then calls the callback
+func (e *Evictor[K, V]) evict(ctx context.Context, key K, value V) {
+	e.send(cachetypes.Entry[K, V]{Key: key, Value: value})
+	switch {
+	case e.jobs != nil:
+		e.dispatch(ctx, key, value)
+	case e.onEvict != nil:
+		e.onEvict(ctx, key, value)
+	}
+}
+
+// dispatch queues the callback for the worker. Once the evictor is closed,
+// when the queue is full, or when called from a callback already running on
+// the worker, the callback runs inline instead: the worker cannot wait for a
+// queue only it drains.
+func (e *Evictor[K, V]) dispatch(ctx context.Context, key K, value V) {
+	if !e.onWorker(ctx) && e.enqueue(ctx, key, value) {
+		return
+	}
+	e.onEvict(ctx, key, value)
+}
+
+// enqueue queues the callback without blocking and reports whether it did
+func (e *Evictor[K, V]) enqueue(ctx context.Context, key K, value V) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return false
+	}
+	select {
+	case e.jobs <- evictJob[K, V]{ctx: context.WithoutCancel(ctx), key: key, value: value}:
+		return true
+	default:
+		return false
+	}
+}
+
+// onWorker reports whether ctx was passed to a callback by this evictor's
+// worker
+func (e *Evictor[K, V]) onWorker(ctx context.Context) bool {
+	w, _ := ctx.Value(workerKey{}).(*Evictor[K, V])
+	return w == e
+}
+
+// run executes queued callbacks until Close
+func (e *Evictor[K, V]) run() {
+	defer close(e.done)
+	for job := range e.jobs {
+		e.call(job)
+	}
+}
+
+// call invokes the callback, recovering from panics like List.OnEvict does
+func (e *Evictor[K, V]) call(job evictJob[K, V]) {
+	defer recoverPanic(e.panicHandler)
+	e.onEvict(context.WithValue(job.ctx, workerKey{}, e), job.key, job.value)
+}
+
+// Close stops the async worker after all queued callbacks have run.
+// It is safe to call more than once and is a no-op for synchronous evictors.
+// Called with the context of a callback running on the worker, e.g. when the
+// callback shuts the cache down, Close does not wait: the worker exits on its
+// own once the remaining callbacks have run.
+func (e *Evictor[K, V]) Close(ctx context.Context) {
+	if e.jobs == nil {
+		return
+	}
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.jobs)
+	}
+	e.mu.Unlock()
+	if e.onWorker(ctx) {
+		return
+	}
+	<-e.done
+}
+
+// Evict delivers a single evicted entry, recovering from a panicking
+// callback. It is for caches that do not keep entries in a List.
+func (e *Evictor[K, V]) Evict(ctx context.Context, key K, value V) {
+	cb := e.Callback()
+	if cb == nil {
+		return
+	}
+	defer recoverPanic(e.panicHandler)
+	cb(ctx, key, value)
+}
+
+// EvictAll delivers entries removed together by Reset or Shutdown, using the
+// batch callback when configured and Evict for each entry otherwise.
+func (e *Evictor[K, V]) EvictAll(ctx context.Context, entries []cachetypes.Entry[K, V]) {
+	if e.batch != nil {
+		e.evictBatch(ctx, entries)
+		return
+	}
+	for _, en := range entries {
+		e.Evict(ctx, en.Key, en.Value)
+	}
+}
+
+// Dropped returns how many entries could not be sent because the eviction
+// channel was full
+func (e *Evictor[K, V]) Dropped() uint64 {
+	return e.dropped.Load()
+}
@@ -0,0 +1,186 @@
+package internal_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestEvictor_NoSinks(t *testing.T) {
+	e := internal.NewEvictor(internal.Options[int, string]{})
+	require.Nil(t, e.Callback())
+}
+
+func TestEvictor_CallbackOnly(t *testing.T) {
+	called := 0
+	e := internal.NewEvictor(internal.Options[int, string]{
+		OnEvict: func(context.Context, int, string) { called++ },
+	})
+	e.Callback()(context.Background(), 1, "one")
+	require.Equal(t, 1, called)
+}
+
+func TestEvictor_ChannelAndCallback(t *testing.T) {
+	ch := make(chan cachetypes.Entry[int, string], 1)
+	called := 0
+	e := internal.NewEvictor(internal.Options[int, string]{
+		OnEvict:         func(context.Context, int, string) { called++ },
+		EvictionChannel: ch,
+	})
+	cb := e.Callback()
+	cb(context.Background(), 1, "one")
+	cb(context.Background(), 2, "two") // channel full: dropped, callback still fires
+
+	require.Equal(t, 2, called)
+	require.Equal(t, uint64(1), e.Dropped())
+	require.Equal(t, cachetypes.Entry[int, string]{Key: 1, Value: "one"}, <-ch)
+}
+
+func TestEvictor_AsyncCloseDrains(t *testing.T) {
+	var mu sync.Mutex
+	var got []int
+	e := internal.NewEvictor(internal.Options[int, string]{
+		OnEvict: func(context.Context, int, string) {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			got = append(got, len(got))
+			mu.Unlock()
+		},
+		AsyncEviction:      true,
+		AsyncEvictionQueue: 2,
+	})
+	cb := e.Callback()
+	for i := range 5 {
+		cb(context.Background(), i, "v")
+	}
+	e.Close(context.Background())
+	require.Len(t, got, 5)
+
+	// after Close callbacks run inline
+	cb(context.Background(), 5, "v")
+	require.Len(t, got, 6)
+	e.Close(context.Background()) // idempotent
+}
+
+func TestEvictor_AsyncPanicRecovered(t *testing.T) {
+	var calls atomic.Int32
+	e := internal.NewEvictor(internal.Options[int, string]{
+		OnEvict: func(context.Context, int, string) {
+			calls.Add(1)
+			panic("boom")
+		},
+		AsyncEviction: true,
+	})
+	// Evict recovers a callback that runs inline because the worker is busy
+	e.Evict(context.Background(), 1, "one")
+	e.Evict(context.Background(), 2, "two")
+	e.Close(context.Background())
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestEvictor_BatchChunks(t *testing.T) {
+	ch := make(chan cachetypes.Entry[int, string], 3)
+	var sizes []int
+	e := internal.NewEvictor(internal.Options[int, string]{
+		EvictionChannel: ch,
+		OnBatchEvict: func(_ context.Context, es []cachetypes.Entry[int, string]) {
+			sizes = append(sizes, len(es))
+			if len(sizes) == 1 {
+				panic("boom") // later chunks are still delivered
+			}
+		},
+		BatchEvictionSize: 2,
+	})
+	require.Nil(t, internal.NewEvictor(internal.Options[int, string]{}).BatchCallback())
+
+	entries := make([]cachetypes.Entry[int, string], 5)
+	e.BatchCallback()(context.Background(), entries)
+	require.Equal(t, []int{2, 2, 1}, sizes)
+	require.Len(t, ch, 3)
+	require.Equal(t, uint64(2), e.Dropped())
+}
+
+func TestEvictor_AsyncPanicHandler(t *testing.T) {
+	recovered := make(chan any, 1)
+	e := internal.NewEvictor(internal.Options[int, string]{
+		OnEvict:       func(context.Context, int, string) { panic("boom") },
+		AsyncEviction: true,
+		PanicHandler:  func(r any) { recovered <- r },
+	})
+	e.Evict(context.Background(), 1, "one")
+	e.Close(context.Background())
+	require.Equal(t, "boom", <-recovered)
+}
+
+func TestEvictor_AsyncReentrantEvict(t *testing.T) {
+	var got []int
+	var e *internal.Evictor[int, string]
+	e = internal.NewEvictor(internal.Options[int, string]{
+		OnEvict: func(ctx context.Context, k int, _ string) {
+			got = append(got, k)
+			if k == 1 {
+				// an eviction caused by the callback itself must not
+				// queue behind it on the unbuffered channel
+				e.Callback()(ctx, 2, "two")
+			}
+		},
+		AsyncEviction: true,
+	})
+	e.Callback()(context.Background(), 1, "one")
+	e.Close(context.Background())
+	require.Equal(t, []int{1, 2}, got)
+}
+
+func TestEvictor_AsyncReentrantEvictOtherContext(t *testing.T) {
+	var mu sync.Mutex
+	var got []int
+	var e *internal.Evictor[int, string]
+	e = internal.NewEvictor(internal.Options[int, string]{
+		OnEvict: func(_ context.Context, k int, _ string) {
+			mu.Lock()
+			got = append(got, k)
+			mu.Unlock()
+			if k == 1 {
+				// without the worker's context these are not recognised as
+				// re-entrant; once the queue is full they must run inline
+				// rather than wait for the worker running this callback
+				for k := 2; k <= 4; k++ {
+					e.Callback()(context.Background(), k, "v")
+				}
+			}
+		},
+		AsyncEviction:      true,
+		AsyncEvictionQueue: 1,
+	})
+	e.Callback()(context.Background(), 1, "one")
+	e.Close(context.Background())
+	require.ElementsMatch(t, []int{1, 2, 3, 4}, got)
+}
+
+func TestEvictor_AsyncCloseFromCallback(t *testing.T) {
+	var e *internal.Evictor[int, string]
+	// a callback that finds the queue full runs inline, concurrently with the worker
+	var calls atomic.Int32
+	e = internal.NewEvictor(internal.Options[int, string]{
+		OnEvict: func(ctx context.Context, k int, _ string) {
+			calls.Add(1)
+			if k == 1 {
+				e.Close(ctx)
+			}
+		},
+		AsyncEviction:      true,
+		AsyncEvictionQueue: 1,
+	})
+	cb := e.Callback()
+	cb(context.Background(), 1, "one")
+	cb(context.Background(), 2, "two")
+	e.Close(context.Background())
+	require.Equal(t, int32(2), calls.Load())
+}
@@ -5,11 +5,16 @@ import (
 	"time"
 
 	"github.com/mcphone2004/cache/internal/heap"
+	cachetypes "github.com/mcphone2004/cache/types"
 )
 
 // used to determine the right size of set to be put back to the pool
 const avgSetSizeSmoothing = 16
 
+// initial room in the time heap, enough for the buckets of a few seconds at
+// the default millisecond bucket size without growing the slice
+const timeHeapCapacityHint = 64
+
 // Package internal provides an expiring key registration map that supports
 // registering and automatically expiring keys based on a time bucket.
 // It is concurrency-safe and uses a background goroutine to manage expirations.
@@ -40,6 +45,7 @@ type ExpiryMap[K comparable] struct {
 	wakeUp chan struct{}
 
 	onExpiry onExpiryFn[K]
+	clock    cachetypes.Clock
 
 	setPool sync.Pool
 
@@ -59,10 +65,10 @@ const (
 
 // waitEvent waits for either a timer tick, a wake-up signal, or a quit signal.
 // It stops the timer on quit/wake to avoid leaked timers and returns the event type.
-func (r *ExpiryMap[K]) waitEvent(timer *time.Timer) eventType {
+func (r *ExpiryMap[K]) waitEvent(timer cachetypes.Timer) eventType {
 	var timerChan <-chan time.Time
 	if timer != nil {
-		timerChan = timer.C
+		timerChan = timer.C()
 	}
 
 	select {
@@ -90,21 +96,29 @@ func timeHeapLessThan(t1, t2 time.Time) bool {
 // New creates and starts a new ExpiryMap with the given expiry callback and bucket duration.
 // The background expiration goroutine is launched immediately.
 func New[K comparable](onExpiry onExpiryFn[K], bucketSize time.Duration) *ExpiryMap[K] {
-	r := newIntern(onExpiry, bucketSize)
+	return NewWithClock(onExpiry, bucketSize, SystemClock{})
+}
+
+// NewWithClock is like New but measures time with clock.
+func NewWithClock[K comparable](onExpiry onExpiryFn[K], bucketSize time.Duration,
+	clock cachetypes.Clock) *ExpiryMap[K] {
+	r := newIntern(onExpiry, bucketSize, clock)
 	r.wg.Add(1)
 	go r.run()
 	return r
 }
 
 // newIntern initializes a new ExpiryMap instance without starting the goroutine.
-func newIntern[K comparable](onExpiry onExpiryFn[K], bucketSize time.Duration) *ExpiryMap[K] {
+func newIntern[K comparable](onExpiry onExpiryFn[K], bucketSize time.Duration,
+	clock cachetypes.Clock) *ExpiryMap[K] {
 	r := &ExpiryMap[K]{
+		clock:       clock,
 		bucketSize:  bucketSize,
 		expiryTimes: make(map[time.Time]expirySet[K]),
 		quit:        make(chan struct{}),
 		wakeUp:      make(chan struct{}, 1),
 		onExpiry:    onExpiry,
-		timeHeap:    heap.New(timeHeapLessThan),
+		timeHeap:    heap.NewWithCapacity(timeHeapLessThan, timeHeapCapacityHint),
 		setPool: sync.Pool{
 			New: func() any {
 				return make(expirySet[K])
@@ -165,6 +179,38 @@ func (r *ExpiryMap[K]) Unregister(h Handle, key K) {
 	}
 }
 
+// Earliest calls fn for each key in the earliest bucket that still holds keys,
+// until fn returns false. Every key in a later bucket expires after every key
+// in this one, so if none of these has expired yet, no key has. fn is called
+// with the map's lock held and must not call back into the map.
+func (r *ExpiryMap[K]) Earliest(fn func(K) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// buckets emptied by Unregister stay in the heap until they reach the top
+	earliest, found := r.timeHeap.Peep()
+	popped := false
+	for found {
+		if _, ok := r.expiryTimes[earliest]; ok {
+			break
+		}
+		_ = r.timeHeap.Pop()
+		popped = true
+		earliest, found = r.timeHeap.Peep()
+	}
+	if popped {
+		// the run loop may be waiting for a bucket that was just popped
+		r.wakeUpNotify()
+	}
+	if !found {
+		return
+	}
+	for k := range r.expiryTimes[earliest] {
+		if !fn(k) {
+			return
+		}
+	}
+}
+
 // wakeUpNotify signals the run loop to recalculate the next expiration.
 func (r *ExpiryMap[K]) wakeUpNotify() {
 	select {
@@ -175,7 +221,7 @@ func (r *ExpiryMap[K]) wakeUpNotify() {
 
 // setupTimer computes the next expiration delay and returns a time.Timer for it.
 // If there is no upcoming expiration, it returns nil.
-func (r *ExpiryMap[K]) setupTimer(timer *time.Timer) *time.Timer {
+func (r *ExpiryMap[K]) setupTimer(timer cachetypes.Timer) cachetypes.Timer {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	expiredAt, found := r.timeHeap.Peep()
@@ -183,22 +229,23 @@ func (r *ExpiryMap[K]) setupTimer(timer *time.Timer) *time.Timer {
 		return nil
 	}
 	r.nextExpiryTime = expiredAt
-	now := time.Now()
+	now := r.clock.Now()
 	delay := max(expiredAt.Sub(now), 0)
 	if timer == nil {
-		return time.NewTimer(delay)
+		return r.clock.NewTimer(delay)
 	}
 	timer.Reset(delay)
 	return timer
 }
 
 // getExpiryRecords retrieves and removes the next expired bucket of keys from the heap.
-// If the bucket is no longer tracked, it returns nil.
+// If the bucket is no longer tracked, or has not expired because Earliest
+// popped the one the timer was set for, it returns nil.
 func (r *ExpiryMap[K]) getExpiryRecords() expirySet[K] {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	expiredAt, found := r.timeHeap.Peep()
-	if !found {
+	if !found || expiredAt.After(r.clock.Now()) {
 		return nil
 	}
 	_ = r.timeHeap.Pop()
@@ -218,7 +265,7 @@ func (r *ExpiryMap[K]) getExpiryRecords() expirySet[K] {
 func (r *ExpiryMap[K]) run() {
 	defer r.wg.Done()
 
-	var timer *time.Timer
+	var timer cachetypes.Timer
 
 	for {
 		timer = r.setupTimer(timer)
@@ -9,7 +9,7 @@ import (
 
 func TestTimeHeap(t *testing.T) {
 	bucketDuration := 30 * time.Second
-	m := newIntern[int](nil, bucketDuration)
+	m := newIntern[int](nil, bucketDuration, SystemClock{})
 	defer m.Shutdown()
 
 	t1 := time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC)
@@ -85,3 +85,51 @@ func TestTimeHeap(t *testing.T) {
 	r5 := m.getExpiryRecords()
 	require.Nil(t, r5)
 }
+
+func TestEarliest(t *testing.T) {
+	bucketDuration := time.Second
+	m := newIntern[int](nil, bucketDuration, SystemClock{})
+	defer m.Shutdown()
+
+	keys := func() []int {
+		var ks []int
+		m.Earliest(func(k int) bool {
+			ks = append(ks, k)
+			return true
+		})
+		return ks
+	}
+	require.Empty(t, keys())
+
+	t1 := time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC)
+	h1 := m.Register(1, t1.Add(2*time.Second))
+	_ = m.Register(2, t1.Add(3*time.Second))
+	require.Equal(t, []int{1}, keys())
+
+	// an emptied bucket left in the heap is skipped
+	m.Unregister(h1, 1)
+	require.Equal(t, []int{2}, keys())
+	// and popped, so later calls do not pass over it again
+	require.Equal(t, 1, m.timeHeap.Len())
+
+	_ = m.Register(3, t1.Add(2500*time.Millisecond))
+	require.ElementsMatch(t, []int{2, 3}, keys())
+
+	var visited int
+	m.Earliest(func(int) bool {
+		visited++
+		return false
+	})
+	require.Equal(t, 1, visited)
+}
+
+func TestGetExpiryRecordsKeepsFutureBucket(t *testing.T) {
+	m := newIntern[int](nil, time.Second, SystemClock{})
+	defer m.Shutdown()
+
+	// a timer set for a bucket that Earliest has since popped fires before
+	// the new earliest bucket is due
+	_ = m.Register(1, time.Now().Add(time.Hour))
+	require.Nil(t, m.getExpiryRecords())
+	require.Equal(t, 1, m.timeHeap.Len())
+}
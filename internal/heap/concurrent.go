@@ -0,0 +1,50 @@
+package heap
+
+import "sync"
+
+// Concurrent is a Heap guarded by a mutex so it can be used as a priority
+// queue from multiple goroutines. Internal hot paths that already hold a
+// lock should keep using Heap directly.
+type Concurrent[T any] struct {
+	mu sync.Mutex
+	h  Heap[T]
+}
+
+// NewConcurrent creates a new empty Concurrent heap ordered by less.
+func NewConcurrent[T any](less LessFunc[T]) *Concurrent[T] {
+	return &Concurrent[T]{h: Heap[T]{less: less}}
+}
+
+// Len returns the number of elements in the heap.
+func (c *Concurrent[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.h.Len()
+}
+
+// Push inserts x into the heap.
+func (c *Concurrent[T]) Push(x T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.h.Push(x)
+}
+
+// Pop removes and returns the root element. Unlike Heap.Pop it does not
+// panic on an empty heap, since another goroutine may empty the heap between
+// a Len check and the Pop; found is false instead.
+func (c *Concurrent[T]) Pop() (val T, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.h.Len() == 0 {
+		return
+	}
+	return c.h.Pop(), true
+}
+
+// Peep returns the root element without removing it.
+// The second return value indicates whether the heap was non-empty.
+func (c *Concurrent[T]) Peep() (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.h.Peep()
+}
@@ -0,0 +1,76 @@
+package heap
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentBasic(t *testing.T) {
+	c := NewConcurrent(intLess)
+	_, found := c.Pop()
+	require.False(t, found)
+	_, found = c.Peep()
+	require.False(t, found)
+
+	c.Push(3)
+	c.Push(1)
+	c.Push(2)
+	require.Equal(t, 3, c.Len())
+	val, found := c.Peep()
+	require.True(t, found)
+	require.Equal(t, 1, val)
+
+	val, found = c.Pop()
+	require.True(t, found)
+	require.Equal(t, 1, val)
+	require.Equal(t, 2, c.Len())
+}
+
+func TestConcurrentPushPop(t *testing.T) {
+	c := NewConcurrent(intLess)
+	const workers = 8
+	const perWorker = 500
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var popped []int
+	wg.Add(2 * workers)
+	for w := range workers {
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				c.Push(w*perWorker + i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var local []int
+			for range perWorker {
+				if v, ok := c.Pop(); ok {
+					local = append(local, v)
+				}
+			}
+			mu.Lock()
+			popped = append(popped, local...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	for {
+		v, ok := c.Pop()
+		if !ok {
+			break
+		}
+		popped = append(popped, v)
+	}
+
+	// every pushed value is popped exactly once
+	slices.Sort(popped)
+	require.Len(t, popped, workers*perWorker)
+	for i, v := range popped {
+		require.Equal(t, i, v)
+	}
+}
@@ -5,6 +5,11 @@
 // max-heaps, or custom priority orderings. It is not safe for concurrent use without external synchronization.
 package heap
 
+import (
+	"iter"
+	"slices"
+)
+
 // LessFunc defines how to compare two elements of type T.
 // It should return true if a < b in your desired ordering.
 type LessFunc[T any] func(a, b T) bool
@@ -14,6 +19,9 @@ type LessFunc[T any] func(a, b T) bool
 type Heap[T any] struct {
 	data []T
 	less LessFunc[T]
+	// onSwap, when set, is called after the elements at i and j are exchanged
+	// so that wrappers such as IndexedHeap can track element positions.
+	onSwap func(i, j int)
 }
 
 // New creates a new empty Heap using the given LessFunc for ordering.
@@ -22,6 +30,13 @@ func New[T any](less LessFunc[T]) *Heap[T] {
 	return &Heap[T]{less: less}
 }
 
+// NewWithCapacity is like New but pre-allocates room for capacity elements,
+// so that pushing up to that many does not reallocate the backing slice.
+// A negative capacity is treated as 0.
+func NewWithCapacity[T any](less LessFunc[T], capacity int) *Heap[T] {
+	return &Heap[T]{data: make([]T, 0, max(capacity, 0)), less: less}
+}
+
 // Len returns the number of elements currently stored in the heap.
 func (h *Heap[T]) Len() int { return len(h.data) }
 
@@ -45,6 +60,30 @@ func (h *Heap[T]) Pop() T {
 	return x
 }
 
+// PushPop pushes x onto the heap and then pops and returns the root element.
+// It is equivalent to Push followed by Pop but performs at most one sift-down,
+// and returns x directly without touching the heap when x would be the new root.
+func (h *Heap[T]) PushPop(x T) T {
+	if len(h.data) == 0 || !h.less(h.data[0], x) {
+		return x
+	}
+	x, h.data[0] = h.data[0], x
+	h.down(0, len(h.data))
+	return x
+}
+
+// Replace pops and returns the root element and pushes x in a single sift-down.
+// Unlike PushPop, the returned element may be larger than x.
+// Calling Replace on an empty heap will panic.
+func (h *Heap[T]) Replace(x T) T {
+	if len(h.data) == 0 {
+		panic("Replace on empty heap")
+	}
+	x, h.data[0] = h.data[0], x
+	h.down(0, len(h.data))
+	return x
+}
+
 // Peep returns the root element without removing it from the heap.
 // The second return value indicates whether the heap was non-empty.
 func (h *Heap[T]) Peep() (val T, found bool) {
@@ -65,6 +104,66 @@ func (h *Heap[T]) Fix(i int) {
 	}
 }
 
+// Remove removes and returns the element at index i from the heap.
+// The complexity is O(log n). Calling Remove with an out-of-range index will panic.
+func (h *Heap[T]) Remove(i int) T {
+	n := len(h.data) - 1
+	if n != i {
+		h.swap(i, n)
+		if !h.down(i, n) {
+			h.up(i)
+		}
+	}
+	x := h.data[n]
+	h.data = h.data[:n]
+	return x
+}
+
+// Clear removes all elements from the heap, keeping the underlying storage
+// for reuse. Elements are zeroed so the heap does not keep them reachable.
+func (h *Heap[T]) Clear() {
+	clear(h.data)
+	h.data = h.data[:0]
+}
+
+// Clone returns an independent copy of the heap that uses the same LessFunc.
+// Mutating either heap does not affect the other. Elements are copied by
+// value, so pointer elements are shared.
+func (h *Heap[T]) Clone() *Heap[T] {
+	return &Heap[T]{
+		data: slices.Clone(h.data),
+		less: h.less,
+	}
+}
+
+// Seq returns an iterator over the heap elements in internal array order,
+// which is not sorted beyond the heap property. It is O(n) and does not
+// modify the heap; it is intended for inspecting the contents, e.g. when
+// debugging. The heap must not be modified during iteration.
+func (h *Heap[T]) Seq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, x := range h.data {
+			if !yield(x) {
+				break
+			}
+		}
+	}
+}
+
+// Sorted returns an iterator over the heap elements in priority order.
+// It pops from a clone of the heap, so it costs O(n) to copy plus
+// O(log n) per element yielded, and leaves the heap unchanged.
+func (h *Heap[T]) Sorted() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		c := h.Clone()
+		for c.Len() > 0 {
+			if !yield(c.Pop()) {
+				break
+			}
+		}
+	}
+}
+
 // lessIndex reports whether h.data[i] < h.data[j] according to the heap's LessFunc.
 func (h *Heap[T]) lessIndex(i, j int) bool {
 	return h.less(h.data[i], h.data[j])
@@ -73,6 +172,9 @@ func (h *Heap[T]) lessIndex(i, j int) bool {
 // swap exchanges the elements at indices i and j in the heap's underlying slice.
 func (h *Heap[T]) swap(i, j int) {
 	h.data[i], h.data[j] = h.data[j], h.data[i]
+	if h.onSwap != nil {
+		h.onSwap(i, j)
+	}
 }
 
 // up moves the element at index j up toward the root until the heap invariant is restored.
@@ -34,6 +34,19 @@ func TestHeapPushPop(t *testing.T) {
 	require.Equal(t, 0, h.Len(), "expected empty heap")
 }
 
+func TestNewWithCapacity(t *testing.T) {
+	h := NewWithCapacity(intLess, 4)
+	require.Zero(t, h.Len())
+	require.Equal(t, 4, cap(h.data))
+	for _, n := range []int{3, 1, 4, 2} {
+		h.Push(n)
+	}
+	require.Equal(t, 4, cap(h.data), "pushing up to the capacity must not reallocate")
+	require.Equal(t, 1, h.Pop())
+
+	require.Zero(t, cap(NewWithCapacity(intLess, -1).data))
+}
+
 func TestHeapFix(t *testing.T) {
 	h := New(intLess)
 	h.Push(5)
@@ -69,3 +82,195 @@ func TestPopOnEmptyPanics(t *testing.T) {
 	h := New(intLess)
 	_ = h.Pop()
 }
+
+func TestHeapRemove(t *testing.T) {
+	h := New(intLess)
+	for _, n := range []int{9, 4, 7, 1, 5, 2} {
+		h.Push(n)
+	}
+	// remove whatever sits at index 2 and verify ordering still holds
+	removed := h.data[2]
+	require.Equal(t, removed, h.Remove(2))
+	require.Equal(t, 5, h.Len())
+
+	last := -1
+	for h.Len() > 0 {
+		cur := h.Pop()
+		require.NotEqual(t, removed, cur)
+		require.GreaterOrEqual(t, cur, last)
+		last = cur
+	}
+
+	// removing the last element must not swap
+	h.Push(1)
+	require.Equal(t, 1, h.Remove(0))
+	require.Zero(t, h.Len())
+}
+
+func TestHeapPushPopMatchesPushThenPop(t *testing.T) {
+	seed := []int{9, 4, 7, 1, 5, 2}
+	for _, x := range []int{0, 1, 3, 6, 10} {
+		a := New(intLess)
+		b := New(intLess)
+		for _, n := range seed {
+			a.Push(n)
+			b.Push(n)
+		}
+		got := a.PushPop(x)
+		b.Push(x)
+		want := b.Pop()
+		require.Equal(t, want, got, "x=%d", x)
+		require.Equal(t, b.Len(), a.Len())
+		for b.Len() > 0 {
+			require.Equal(t, b.Pop(), a.Pop())
+		}
+	}
+
+	// PushPop on an empty heap returns x and leaves the heap empty
+	h := New(intLess)
+	require.Equal(t, 42, h.PushPop(42))
+	require.Zero(t, h.Len())
+}
+
+func TestHeapReplaceMatchesPopThenPush(t *testing.T) {
+	seed := []int{9, 4, 7, 1, 5, 2}
+	for _, x := range []int{0, 1, 3, 6, 10} {
+		a := New(intLess)
+		b := New(intLess)
+		for _, n := range seed {
+			a.Push(n)
+			b.Push(n)
+		}
+		got := a.Replace(x)
+		want := b.Pop()
+		b.Push(x)
+		require.Equal(t, want, got, "x=%d", x)
+		require.Equal(t, b.Len(), a.Len())
+		for b.Len() > 0 {
+			require.Equal(t, b.Pop(), a.Pop())
+		}
+	}
+}
+
+func TestReplaceOnEmptyPanics(t *testing.T) {
+	h := New(intLess)
+	require.Panics(t, func() { _ = h.Replace(1) })
+}
+
+func TestHeapClear(t *testing.T) {
+	h := New(intLess)
+	for _, v := range []int{5, 3, 8} {
+		h.Push(v)
+	}
+	capBefore := cap(h.data)
+	h.Clear()
+	require.Zero(t, h.Len())
+	require.Equal(t, capBefore, cap(h.data), "storage is retained")
+	_, found := h.Peep()
+	require.False(t, found)
+
+	h.Push(7)
+	h.Push(2)
+	require.Equal(t, 2, h.Pop())
+}
+
+func TestHeapClone(t *testing.T) {
+	h := New(intLess)
+	for _, v := range []int{5, 3, 8, 1} {
+		h.Push(v)
+	}
+	c := h.Clone()
+	require.Equal(t, h.Len(), c.Len())
+
+	// mutations of the clone do not affect the original, and vice versa
+	require.Equal(t, 1, c.Pop())
+	c.Push(0)
+	h.Push(4)
+	require.Equal(t, 4, c.Len())
+	require.Equal(t, 5, h.Len())
+
+	var fromOriginal []int
+	for h.Len() > 0 {
+		fromOriginal = append(fromOriginal, h.Pop())
+	}
+	require.Equal(t, []int{1, 3, 4, 5, 8}, fromOriginal)
+	var fromClone []int
+	for c.Len() > 0 {
+		fromClone = append(fromClone, c.Pop())
+	}
+	require.Equal(t, []int{0, 3, 5, 8}, fromClone)
+}
+
+func TestHeapSeq(t *testing.T) {
+	h := New(intLess)
+	in := []int{5, 3, 8, 1, 9, 2}
+	for _, v := range in {
+		h.Push(v)
+	}
+
+	var got []int
+	for v := range h.Seq() {
+		got = append(got, v)
+	}
+	require.ElementsMatch(t, in, got)
+	require.Equal(t, 1, got[0], "root comes first in array order")
+	require.Equal(t, len(in), h.Len(), "Seq must not mutate the heap")
+
+	// early termination
+	n := 0
+	for range h.Seq() {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	require.Equal(t, 2, n)
+}
+
+func TestHeapSorted(t *testing.T) {
+	h := New(intLess)
+	for _, v := range []int{5, 3, 8, 1, 9, 2} {
+		h.Push(v)
+	}
+
+	var got []int
+	for v := range h.Sorted() {
+		got = append(got, v)
+	}
+	require.Equal(t, []int{1, 2, 3, 5, 8, 9}, got)
+	require.Equal(t, 6, h.Len(), "Sorted must not mutate the heap")
+
+	got = got[:0]
+	for v := range h.Sorted() {
+		if v > 3 {
+			break
+		}
+		got = append(got, v)
+	}
+	require.Equal(t, []int{1, 2, 3}, got)
+	require.Equal(t, 1, h.Pop())
+}
+
+const benchPushCount = 100_000
+
+// BenchmarkPush and BenchmarkPushWithCapacity compare the allocations of
+// filling a heap that grows its slice with one that is pre-sized.
+func BenchmarkPush(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		h := New(intLess)
+		for i := range benchPushCount {
+			h.Push(benchPushCount - i)
+		}
+	}
+}
+
+func BenchmarkPushWithCapacity(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		h := NewWithCapacity(intLess, benchPushCount)
+		for i := range benchPushCount {
+			h.Push(benchPushCount - i)
+		}
+	}
+}
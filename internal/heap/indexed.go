@@ -0,0 +1,96 @@
+package heap
+
+// indexedItem is a heap element that remembers its key and current position.
+type indexedItem[K comparable, T any] struct {
+	key   K
+	val   T
+	index int
+}
+
+// IndexedHeap is a keyed binary heap that tracks the position of every element,
+// so an element can be updated or removed by key in O(log n) without a linear scan.
+// It is not safe for concurrent use without external synchronization.
+type IndexedHeap[K comparable, T any] struct {
+	h     Heap[*indexedItem[K, T]]
+	items map[K]*indexedItem[K, T]
+}
+
+// NewIndexed creates a new empty IndexedHeap using the given LessFunc for ordering.
+func NewIndexed[K comparable, T any](less LessFunc[T]) *IndexedHeap[K, T] {
+	ih := &IndexedHeap[K, T]{
+		items: make(map[K]*indexedItem[K, T]),
+	}
+	ih.h.less = func(a, b *indexedItem[K, T]) bool {
+		return less(a.val, b.val)
+	}
+	ih.h.onSwap = func(i, j int) {
+		ih.h.data[i].index = i
+		ih.h.data[j].index = j
+	}
+	return ih
+}
+
+// Len returns the number of elements currently stored in the heap.
+func (ih *IndexedHeap[K, T]) Len() int { return ih.h.Len() }
+
+// Contains reports whether key is present in the heap.
+func (ih *IndexedHeap[K, T]) Contains(key K) bool {
+	_, ok := ih.items[key]
+	return ok
+}
+
+// Push inserts val under key. If key is already present its value is
+// replaced and its position fixed, as with Update.
+func (ih *IndexedHeap[K, T]) Push(key K, val T) {
+	if it, ok := ih.items[key]; ok {
+		it.val = val
+		ih.h.Fix(it.index)
+		return
+	}
+	it := &indexedItem[K, T]{key: key, val: val, index: ih.h.Len()}
+	ih.items[key] = it
+	ih.h.Push(it)
+}
+
+// Pop removes and returns the root element and its key.
+// The complexity is O(log n). Calling Pop on an empty heap will panic.
+func (ih *IndexedHeap[K, T]) Pop() (K, T) {
+	it := ih.h.Pop()
+	delete(ih.items, it.key)
+	return it.key, it.val
+}
+
+// Peep returns the root element and its key without removing it.
+// The third return value indicates whether the heap was non-empty.
+func (ih *IndexedHeap[K, T]) Peep() (key K, val T, found bool) {
+	it, ok := ih.h.Peep()
+	if !ok {
+		return key, val, false
+	}
+	return it.key, it.val, true
+}
+
+// Update replaces the value stored under key and restores the heap ordering.
+// It returns false if key is not present.
+func (ih *IndexedHeap[K, T]) Update(key K, newVal T) bool {
+	it, ok := ih.items[key]
+	if !ok {
+		return false
+	}
+	it.val = newVal
+	ih.h.Fix(it.index)
+	return true
+}
+
+// Remove deletes key from the heap and returns its value.
+// The second return value is false if key was not present.
+func (ih *IndexedHeap[K, T]) Remove(key K) (T, bool) {
+	it, ok := ih.items[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	ih.h.Remove(it.index)
+	delete(ih.items, key)
+	return it.val, true
+}
@@ -0,0 +1,91 @@
+package heap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireIndexesConsistent verifies that every item's recorded index matches its slot.
+func requireIndexesConsistent[K comparable, T any](t *testing.T, ih *IndexedHeap[K, T]) {
+	t.Helper()
+	require.Len(t, ih.items, ih.h.Len())
+	for i, it := range ih.h.data {
+		require.Equal(t, i, it.index, "key %v", it.key)
+		require.Same(t, it, ih.items[it.key])
+	}
+}
+
+func TestIndexedHeapPushPop(t *testing.T) {
+	ih := NewIndexed[string](intLess)
+	_, _, found := ih.Peep()
+	require.False(t, found)
+
+	ih.Push("c", 3)
+	ih.Push("a", 1)
+	ih.Push("b", 2)
+	requireIndexesConsistent(t, ih)
+	require.Equal(t, 3, ih.Len())
+
+	k, v, found := ih.Peep()
+	require.True(t, found)
+	require.Equal(t, "a", k)
+	require.Equal(t, 1, v)
+
+	for _, want := range []string{"a", "b", "c"} {
+		k, _ := ih.Pop()
+		require.Equal(t, want, k)
+		require.False(t, ih.Contains(k))
+		requireIndexesConsistent(t, ih)
+	}
+	require.Zero(t, ih.Len())
+}
+
+func TestIndexedHeapUpdate(t *testing.T) {
+	ih := NewIndexed[string](intLess)
+	ih.Push("a", 10)
+	ih.Push("b", 20)
+	ih.Push("c", 30)
+
+	require.True(t, ih.Update("c", 5))
+	requireIndexesConsistent(t, ih)
+	k, _, _ := ih.Peep()
+	require.Equal(t, "c", k)
+
+	require.True(t, ih.Update("c", 50))
+	requireIndexesConsistent(t, ih)
+	k, _, _ = ih.Peep()
+	require.Equal(t, "a", k)
+
+	require.False(t, ih.Update("missing", 1))
+
+	// Push on an existing key behaves like Update
+	ih.Push("b", 1)
+	require.Equal(t, 3, ih.Len())
+	k, v, _ := ih.Peep()
+	require.Equal(t, "b", k)
+	require.Equal(t, 1, v)
+}
+
+func TestIndexedHeapRemove(t *testing.T) {
+	ih := NewIndexed[int](intLess)
+	for i := range 10 {
+		ih.Push(i, 10-i)
+	}
+	v, ok := ih.Remove(4)
+	require.True(t, ok)
+	require.Equal(t, 6, v)
+	require.False(t, ih.Contains(4))
+	requireIndexesConsistent(t, ih)
+
+	_, ok = ih.Remove(4)
+	require.False(t, ok)
+
+	last := -1
+	for ih.Len() > 0 {
+		_, cur := ih.Pop()
+		require.Greater(t, cur, last)
+		last = cur
+		requireIndexesConsistent(t, ih)
+	}
+}
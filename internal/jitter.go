@@ -0,0 +1,17 @@
+package internal
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter returns ttl randomized uniformly within ±fraction of ttl, so that
+// keys inserted together with the same TTL do not all expire at once.
+// A fraction of 0 returns ttl unchanged.
+func Jitter(ttl time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || ttl <= 0 {
+		return ttl
+	}
+	spread := float64(ttl) * fraction
+	return ttl + time.Duration((rand.Float64()*2-1)*spread) //nolint:gosec // jitter does not need a secure source
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJitter(t *testing.T) {
+	require.Equal(t, time.Second, Jitter(time.Second, 0))
+	require.Zero(t, Jitter(0, 0.5))
+
+	ttl := 10 * time.Second
+	distinct := map[time.Duration]struct{}{}
+	for range 1000 {
+		d := Jitter(ttl, 0.1)
+		require.GreaterOrEqual(t, d, 9*time.Second)
+		require.LessOrEqual(t, d, 11*time.Second)
+		distinct[d] = struct{}{}
+	}
+	require.Greater(t, len(distinct), 1)
+}
+
+// TestJitterBuckets checks that jittered expiries still coalesce into
+// buckets: the keys spread over at most 2*fraction*ttl/bucketSize+1 buckets.
+func TestJitterBuckets(t *testing.T) {
+	bucket := 100 * time.Millisecond
+	m := newIntern[int](nil, bucket, SystemClock{})
+	defer m.Shutdown()
+
+	now := time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC)
+	for k := range 1000 {
+		_ = m.Register(k, now.Add(Jitter(10*time.Second, 0.1)))
+	}
+	require.LessOrEqual(t, len(m.expiryTimes), 21)
+	require.Greater(t, len(m.expiryTimes), 1)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"iter"
-	"sync"
 
 	"github.com/mcphone2004/cache/internal/list"
 	cachetypes "github.com/mcphone2004/cache/types"
@@ -14,6 +13,8 @@ import (
 type Entry[K comparable, V any] struct {
 	Key   K
 	Value V
+	// Meta is nil unless the cache tracks entry metadata.
+	Meta *cachetypes.EntryMeta
 }
 
 // ListEntry represent an entry on a list
@@ -21,33 +22,30 @@ type ListEntry[K comparable, V any] = list.Entry[*Entry[K, V]]
 
 // List represents the cache lru queue
 type List[K comparable, V any] struct {
-	entryPool *sync.Pool
+	entryPool entryPool[K, V]
 	order     list.List[*Entry[K, V]]
 	capacity  int
 	onEvict   cachetypes.CBFunc[K, V]
+	onBatch   cachetypes.BatchCBFunc[K, V]
+
+	panicHandler func(recovered any)
 }
 
 // NewList creates a new list for the given capacity
 func NewList[K comparable, V any](capacity uint,
 	onEvict cachetypes.CBFunc[K, V]) *List[K, V] {
 	l := List[K, V]{
-		entryPool: &sync.Pool{
-			New: func() any {
-				return &Entry[K, V]{}
-			},
-		},
-		capacity: int(capacity), //nolint:gosec // capacity is validated positive by callers
-		onEvict:  onEvict,
-	}
-	// pre-populate the pool
-	for range capacity {
-		l.entryPool.Put(&Entry[K, V]{})
+		entryPool: newEntryPool[K, V](capacity, 0), // pre-populate the pool
+		capacity:  int(capacity),                   //nolint:gosec // capacity is validated positive by callers
+		onEvict:   onEvict,
 	}
 	l.order.Init()
 	return &l
 }
 
-// Size returns the length of the list
+// Size returns the length of the list. It may be called without holding the
+// lock that guards the list, e.g. to report a cache's size without
+// contending with writers.
 func (l *List[K, V]) Size() int {
 	return l.order.Size()
 }
@@ -57,9 +55,34 @@ func (l *List[K, V]) Capacity() int {
 	return l.capacity
 }
 
-// Destroy release resources of the list
+// SetCapacity changes the capacity of the list. It does not remove entries;
+// the caller evicts any beyond the new capacity.
+func (l *List[K, V]) SetCapacity(capacity uint) {
+	l.capacity = int(capacity) //nolint:gosec // capacity is validated positive by callers
+}
+
+// Compact drops pooled entries beyond those needed to refill the list to its
+// capacity, e.g. after the cache shrank from a peak.
+func (l *List[K, V]) Compact() {
+	l.entryPool.trim(max(l.capacity-l.order.Size(), 0))
+}
+
+// Destroy release resources of the list, returning any remaining entries to
+// the pools
 func (l *List[K, V]) Destroy() {
-	l.order = list.List[*Entry[K, V]]{} // Reset the order list
+	l.ReleaseAll(l.RemoveAll())
+}
+
+// RemoveAll empties the list in one pass and returns the contents of all
+// entries, least recently used first, e.g. for OnEvictAll or ReleaseAll.
+// The list entries go back to their pool and the list can be reused.
+func (l *List[K, V]) RemoveAll() []*Entry[K, V] {
+	ens := make([]*Entry[K, V], 0, l.order.Size())
+	for elem := range l.order.SeqReverse() {
+		ens = append(ens, elem.Value)
+	}
+	l.order.Clear()
+	return ens
 }
 
 // Seq returns the iterator of the list
@@ -67,6 +90,12 @@ func (l *List[K, V]) Seq() iter.Seq[*ListEntry[K, V]] {
 	return l.order.Seq()
 }
 
+// SeqReverse returns the iterator of the list from the least recently used
+// entry to the most recently used one
+func (l *List[K, V]) SeqReverse() iter.Seq[*ListEntry[K, V]] {
+	return l.order.SeqReverse()
+}
+
 // MoveToFront move the given element to the front of the list
 func (l *List[K, V]) MoveToFront(elem *ListEntry[K, V]) {
 	if err := l.order.MoveToFront(elem); err != nil {
@@ -81,17 +110,65 @@ func zeroOf[T any]() (t T) { return }
 func (l *List[K, V]) OnEvict(ctx context.Context, en *Entry[K, V]) {
 	if l.onEvict != nil {
 		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Println("Recovered from panic:", r)
-				}
-			}()
+			defer recoverPanic(l.panicHandler)
 			l.onEvict(ctx, en.Key, en.Value)
 		}()
 	}
-	en.Key = zeroOf[K]()
-	en.Value = zeroOf[V]()
-	l.entryPool.Put(en)
+	l.entryPool.put(en)
+}
+
+// SetBatchEvict sets the callback used by OnEvictAll. When it is nil
+// OnEvictAll falls back to the per-entry eviction callback.
+func (l *List[K, V]) SetBatchEvict(cb cachetypes.BatchCBFunc[K, V]) {
+	l.onBatch = cb
+}
+
+// SetEntryPoolLimit caps the number of idle entries kept for reuse; entries
+// released beyond the limit are left to the garbage collector. A limit of 0
+// keeps the default unbounded pool. It must be called before the list is used.
+func (l *List[K, V]) SetEntryPoolLimit(limit uint) {
+	if limit == 0 {
+		return
+	}
+	l.entryPool = newEntryPool[K, V](uint(l.capacity), limit) //nolint:gosec // capacity is positive
+}
+
+// SetPanicHandler sets the function that receives values recovered from
+// panicking eviction callbacks. When it is nil they are printed.
+func (l *List[K, V]) SetPanicHandler(h func(recovered any)) {
+	l.panicHandler = h
+}
+
+// OnEvictAll invokes the batch eviction callback once for all the given
+// entries, or the per-entry callback for each of them, and returns the
+// entries back to the pool
+func (l *List[K, V]) OnEvictAll(ctx context.Context, ens []*Entry[K, V]) {
+	if l.onBatch == nil {
+		for _, en := range ens {
+			l.OnEvict(ctx, en)
+		}
+		return
+	}
+	if len(ens) == 0 {
+		return
+	}
+	batch := make([]cachetypes.Entry[K, V], len(ens))
+	for i, en := range ens {
+		batch[i] = cachetypes.Entry[K, V]{Key: en.Key, Value: en.Value}
+		l.entryPool.put(en)
+	}
+	func() {
+		defer recoverPanic(l.panicHandler)
+		l.onBatch(ctx, batch)
+	}()
+}
+
+// ReleaseAll returns the given entries to the pool without invoking any
+// eviction callback.
+func (l *List[K, V]) ReleaseAll(ens []*Entry[K, V]) {
+	for _, en := range ens {
+		l.entryPool.put(en)
+	}
 }
 
 // Remove removes the given element from the list and return
@@ -103,6 +180,11 @@ func (l *List[K, V]) Remove(elem *ListEntry[K, V]) *Entry[K, V] {
 	return en
 }
 
+// Front returns the first element of the list
+func (l *List[K, V]) Front() *ListEntry[K, V] {
+	return l.order.Front()
+}
+
 // Back returns the last element of the list
 func (l *List[K, V]) Back() *ListEntry[K, V] {
 	return l.order.Back()
@@ -110,7 +192,7 @@ func (l *List[K, V]) Back() *ListEntry[K, V] {
 
 // PushFront inserts a new entry at the beginning of the list
 func (l *List[K, V]) PushFront(key K, value V) *ListEntry[K, V] {
-	en := l.entryPool.Get().(*Entry[K, V]) //nolint:forcetypeassert // pool only contains *Entry[K, V]
+	en := l.entryPool.get()
 	en.Key = key
 	en.Value = value
 	return l.order.PushFront(en)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"iter"
 	"sync"
+	"sync/atomic"
 )
 
 // Entry represents an element in a linked list.
@@ -25,11 +26,19 @@ func (e *Entry[V]) Next() *Entry[V] {
 	return nil
 }
 
+// Prev returns the previous list element or nil.
+func (e *Entry[V]) Prev() *Entry[V] {
+	if p := e.prev; e.list != nil && p != &e.list.root {
+		return p
+	}
+	return nil
+}
+
 // List represents a doubly linked list.
 type List[V any] struct {
-	pool *sync.Pool // Pool for reusing Entry[V] instances.
-	root Entry[V]   // sentinel list element, only &root, root.prev, and root.next are used
-	len  int        // current list length excluding (this) sentinel element
+	pool *sync.Pool   // Pool for reusing Entry[V] instances.
+	root Entry[V]     // sentinel list element, only &root, root.prev, and root.next are used
+	len  atomic.Int64 // current list length excluding (this) sentinel element
 }
 
 // Init initialize the list
@@ -41,12 +50,14 @@ func (l *List[V]) Init() {
 	}
 	l.root.next = &l.root
 	l.root.prev = &l.root
-	l.len = 0
+	l.len.Store(0)
 }
 
-// Size returns the current number of items in the list.
+// Size returns the current number of items in the list. Unlike the other
+// methods it may be called concurrently with mutations; the result is then a
+// snapshot that may already be stale.
 func (l *List[V]) Size() int {
-	return l.len
+	return int(l.len.Load())
 }
 
 // MoveToFront moves the specified entry to the front of the list.
@@ -96,13 +107,13 @@ func (l *List[V]) insert(e, at *Entry[V]) *Entry[V] {
 	e.prev.next = e
 	e.next.prev = e
 	e.list = l
-	l.len++
+	l.len.Add(1)
 	return e
 }
 
 // Front returns the first element of the list or nil if the list is empty.
 func (l *List[V]) Front() *Entry[V] {
-	if l.len == 0 {
+	if l.len.Load() == 0 {
 		return nil
 	}
 	return l.root.next
@@ -110,7 +121,7 @@ func (l *List[V]) Front() *Entry[V] {
 
 // Back returns the last element of list l or nil if the list is empty.
 func (l *List[V]) Back() *Entry[V] {
-	if l.len == 0 {
+	if l.len.Load() == 0 {
 		return nil
 	}
 	return l.root.prev
@@ -147,11 +158,33 @@ func (l *List[V]) Remove(e *Entry[V]) {
 	l.pool.Put(e)  // Return the entry to the pool
 }
 
+// Clear removes all entries in one pass, zeroing their values and returning
+// them to the pool, and leaves the list empty and ready for reuse. Entries
+// obtained before the call must not be used afterwards.
+func (l *List[V]) Clear() {
+	if l.root.next == nil {
+		return // never initialized
+	}
+	var zero V
+	for e := l.root.next; e != &l.root; {
+		next := e.next
+		e.Value = zero
+		e.prev = nil
+		e.next = nil
+		e.list = nil
+		l.pool.Put(e)
+		e = next
+	}
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.len.Store(0)
+}
+
 // remove removes e from its list, decrements l.len
 func (l *List[V]) remove(e *Entry[V]) {
 	e.prev.next = e.next
 	e.next.prev = e.prev
-	l.len--
+	l.len.Add(-1)
 }
 
 // Seq returns a forward iterator over the list entries using iter.Seq.
@@ -164,3 +197,15 @@ func (l *List[V]) Seq() iter.Seq[*Entry[V]] {
 		}
 	}
 }
+
+// SeqReverse returns a backward iterator over the list entries using iter.Seq,
+// walking from the back of the list toward the front.
+func (l *List[V]) SeqReverse() iter.Seq[*Entry[V]] {
+	return func(yield func(*Entry[V]) bool) {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			if !yield(e) {
+				break
+			}
+		}
+	}
+}
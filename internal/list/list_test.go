@@ -1,6 +1,7 @@
 package list_test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -106,3 +107,102 @@ func TestSeqEarlyStop(t *testing.T) {
 	}
 	require.Equal(t, 1, visited)
 }
+
+func TestSeqReverse(t *testing.T) {
+	var l list.List[int]
+	l.Init()
+	for range l.SeqReverse() {
+		t.Fatal("empty list must not yield")
+	}
+
+	l.PushFront(3)
+	l.PushFront(2)
+	l.PushFront(1)
+
+	got := []int{}
+	for e := range l.SeqReverse() {
+		got = append(got, e.Value)
+	}
+	require.Equal(t, []int{3, 2, 1}, got)
+	require.Nil(t, l.Front().Prev())
+	require.Equal(t, 2, l.Back().Prev().Value)
+}
+
+func TestSeqReverseEarlyStop(t *testing.T) {
+	var l list.List[int]
+	l.Init()
+	l.PushFront(3)
+	l.PushFront(2)
+	l.PushFront(1)
+
+	visited := 0
+	for e := range l.SeqReverse() {
+		visited++
+		require.Equal(t, 3, e.Value)
+		break
+	}
+	require.Equal(t, 1, visited)
+}
+
+// TestSizeConcurrent reads Size without the writer's lock; run with -race.
+func TestSizeConcurrent(t *testing.T) {
+	var (
+		l  list.List[int]
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	l.Init()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 1000 {
+			mu.Lock()
+			l.PushFront(i)
+			if i%2 == 0 {
+				l.PopBack()
+			}
+			mu.Unlock()
+		}
+	}()
+	for range 1000 {
+		n := l.Size()
+		require.GreaterOrEqual(t, n, 0)
+		require.LessOrEqual(t, n, 500)
+	}
+	wg.Wait()
+	require.Equal(t, 500, l.Size())
+}
+
+func TestClear(t *testing.T) {
+	var l list.List[*int]
+	l.Init()
+	l.Clear() // an empty list is a no-op
+
+	old := map[*list.Entry[*int]]bool{}
+	for i := range 100 {
+		e := l.PushFront(&i)
+		old[e] = true
+	}
+	l.Clear()
+	require.Zero(t, l.Size())
+	require.Nil(t, l.Front())
+	require.Nil(t, l.Back())
+	for e := range old {
+		require.Nil(t, e.Value, "values are zeroed so they can be collected")
+		require.Nil(t, e.Next())
+		require.Error(t, l.MoveToFront(e), "cleared entries no longer belong to the list")
+	}
+
+	// the cleared entries were returned to the pool and are handed out again
+	reused := 0
+	for i := range 100 {
+		if old[l.PushFront(&i)] {
+			reused++
+		}
+	}
+	require.Positive(t, reused)
+	require.Equal(t, 100, l.Size())
+
+	var zero list.List[int]
+	require.NotPanics(t, zero.Clear, "an uninitialized list is a no-op")
+}
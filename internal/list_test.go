@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
 )
 
 func TestNewList_SizeAndCapacity(t *testing.T) {
@@ -91,3 +92,78 @@ func TestList_Destroy(t *testing.T) {
 	l.Destroy()
 	require.Equal(t, 0, l.Size())
 }
+
+func TestList_RemoveAll(t *testing.T) {
+	l := internal.NewList[int, string](4, nil)
+	require.Empty(t, l.RemoveAll())
+	for i := range 3 {
+		l.PushFront(i, "v")
+	}
+	ens := l.RemoveAll()
+	require.Zero(t, l.Size())
+	require.Nil(t, l.Back())
+	keys := make([]int, 0, len(ens))
+	for _, en := range ens {
+		keys = append(keys, en.Key)
+	}
+	require.Equal(t, []int{0, 1, 2}, keys, "least recently used first")
+	l.ReleaseAll(ens)
+
+	// the list is reusable after RemoveAll
+	l.PushFront(3, "w")
+	require.Equal(t, 1, l.Size())
+	require.Equal(t, 3, l.Front().Value.Key)
+}
+
+func TestList_SeqReverse(t *testing.T) {
+	l := internal.NewList[int, string](4, nil)
+	l.PushFront(1, "one")
+	l.PushFront(2, "two")
+	l.PushFront(3, "three")
+
+	keys := []int{}
+	for e := range l.SeqReverse() {
+		keys = append(keys, e.Value.Key)
+	}
+	require.Equal(t, []int{1, 2, 3}, keys)
+}
+
+func TestList_OnEvictAll(t *testing.T) {
+	perEntry := 0
+	l := internal.NewList[int, string](4, func(context.Context, int, string) {
+		perEntry++
+	})
+	ens := []*internal.Entry[int, string]{
+		l.Remove(l.PushFront(1, "one")),
+		l.Remove(l.PushFront(2, "two")),
+	}
+	l.OnEvictAll(context.Background(), ens)
+	require.Equal(t, 2, perEntry)
+
+	var batches [][]cachetypes.Entry[int, string]
+	l.SetBatchEvict(func(_ context.Context, es []cachetypes.Entry[int, string]) {
+		batches = append(batches, es)
+	})
+	ens = []*internal.Entry[int, string]{
+		l.Remove(l.PushFront(1, "one")),
+		l.Remove(l.PushFront(2, "two")),
+	}
+	l.OnEvictAll(context.Background(), ens)
+	require.Equal(t, 2, perEntry)
+	require.Equal(t, [][]cachetypes.Entry[int, string]{{{Key: 1, Value: "one"}, {Key: 2, Value: "two"}}}, batches)
+}
+
+func TestList_OnEvict_PanicHandler(t *testing.T) {
+	l := internal.NewList[int, string](4, func(context.Context, int, string) {
+		panic("boom")
+	})
+	var recovered []any
+	l.SetPanicHandler(func(r any) { recovered = append(recovered, r) })
+	l.OnEvict(context.Background(), l.Remove(l.PushFront(1, "one")))
+
+	l.SetBatchEvict(func(context.Context, []cachetypes.Entry[int, string]) {
+		panic("batch")
+	})
+	l.OnEvictAll(context.Background(), []*internal.Entry[int, string]{l.Remove(l.PushFront(2, "two"))})
+	require.Equal(t, []any{"boom", "batch"}, recovered)
+}
@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"context"
+
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Load fills a cache miss for key from loader and stores a found value with
+// put. It must be called without the cache lock held. Loader and put errors
+// are returned and nothing is reported as found.
+func Load[K comparable, V any](ctx context.Context, key K,
+	loader cachetypes.LoaderFunc[K, V],
+	put func(context.Context, K, V) error) (V, bool, error) {
+	var zero V
+	v, found, err := loader(ctx, key)
+	if err != nil || !found {
+		return zero, false, err
+	}
+	if err := put(ctx, key, v); err != nil {
+		return zero, false, err
+	}
+	return v, true, nil
+}
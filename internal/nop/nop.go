@@ -19,6 +19,11 @@ func (Cache[K, V]) Get(_ context.Context, _ K) (V, bool, error) {
 	return zero, false, cachetypes.ErrShutdown
 }
 
+// Has reports no key in the nop cache.
+func (Cache[K, V]) Has(_ context.Context, _ K) (bool, error) {
+	return false, cachetypes.ErrShutdown
+}
+
 // Put does nothing in the nop cache.
 func (Cache[K, V]) Put(_ context.Context, _ K, _ V) error {
 	// No operation
@@ -31,6 +36,18 @@ func (Cache[K, V]) Delete(_ context.Context, _ K) (bool, error) {
 	return false, cachetypes.ErrShutdown
 }
 
+// GetAndDelete retrieves no value from the cache.
+func (Cache[K, V]) GetAndDelete(_ context.Context, _ K) (V, bool, error) {
+	var zero V
+	return zero, false, cachetypes.ErrShutdown
+}
+
+// Replace replaces no value in the nop cache.
+func (Cache[K, V]) Replace(_ context.Context, _ K, _ V) (V, bool, error) {
+	var zero V
+	return zero, false, cachetypes.ErrShutdown
+}
+
 // Reset clears the cache, but does nothing in the nop cache.
 func (Cache[K, V]) Reset(_ context.Context) error {
 	// No operation
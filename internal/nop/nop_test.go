@@ -26,10 +26,19 @@ func TestNoopCacheExists(t *testing.T) {
 	require.False(t, ok)
 	var sErr *cachetypes.ShutdownError
 	require.ErrorAs(t, err, &sErr)
+	ok, err = c.Has(ctx, "key")
+	require.False(t, ok)
+	require.ErrorAs(t, err, &sErr)
 	err = c.Put(ctx, "key", "value")
 	require.ErrorAs(t, err, &sErr)
 	_, err = c.Delete(ctx, "key")
 	require.ErrorAs(t, err, &sErr)
+	_, ok, err = c.GetAndDelete(ctx, "key")
+	require.False(t, ok)
+	require.ErrorAs(t, err, &sErr)
+	_, ok, err = c.Replace(ctx, "key", "value")
+	require.False(t, ok)
+	require.ErrorAs(t, err, &sErr)
 	err = c.Reset(ctx)
 	require.ErrorAs(t, err, &sErr)
 	size, err := c.Size()
@@ -42,3 +51,39 @@ func TestNoopCacheExists(t *testing.T) {
 	require.ErrorAs(t, err, &sErr)
 	c.Shutdown(ctx)
 }
+
+func TestSilentCache(t *testing.T) {
+	ctx := context.Background()
+	c := nop.NewSilent[string, string]()
+
+	require.NoError(t, c.Put(ctx, "key", "value"))
+	v, ok, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Empty(t, v)
+	ok, err = c.Has(ctx, "key")
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = c.Delete(ctx, "key")
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, ok, err = c.GetAndDelete(ctx, "key")
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, ok, err = c.Replace(ctx, "key", "value")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, c.Reset(ctx))
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+	capacity, err := c.Capacity()
+	require.NoError(t, err)
+	require.Zero(t, capacity)
+	require.NoError(t, c.Traverse(ctx, func(context.Context, string, string) bool {
+		t.Fatal("silent cache has no entries")
+		return true
+	}))
+	c.Shutdown(ctx)
+	require.NoError(t, c.Put(ctx, "key", "value"), "silent even after Shutdown")
+}
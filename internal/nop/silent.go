@@ -0,0 +1,76 @@
+package nop
+
+import (
+	"context"
+
+	"github.com/mcphone2004/cache/iface"
+)
+
+// Silent is an inert cache that implements the Cache interface. Unlike
+// Cache, whose methods report ErrShutdown to expose use after shutdown,
+// Silent swallows every call: it stores nothing and returns zero values and
+// nil errors.
+type Silent[K comparable, V any] struct{}
+
+var _ iface.Cache[string, int] = (*Silent[string, int])(nil)
+
+// NewSilent returns a cache that ignores every operation without error.
+func NewSilent[K comparable, V any]() *Silent[K, V] {
+	return &Silent[K, V]{}
+}
+
+// Get always misses.
+func (Silent[K, V]) Get(_ context.Context, _ K) (V, bool, error) {
+	var zero V
+	return zero, false, nil
+}
+
+// Has reports no key.
+func (Silent[K, V]) Has(_ context.Context, _ K) (bool, error) {
+	return false, nil
+}
+
+// Put discards the value.
+func (Silent[K, V]) Put(_ context.Context, _ K, _ V) error {
+	return nil
+}
+
+// Delete finds nothing to delete.
+func (Silent[K, V]) Delete(_ context.Context, _ K) (bool, error) {
+	return false, nil
+}
+
+// GetAndDelete finds nothing to delete.
+func (Silent[K, V]) GetAndDelete(_ context.Context, _ K) (V, bool, error) {
+	var zero V
+	return zero, false, nil
+}
+
+// Replace finds nothing to replace.
+func (Silent[K, V]) Replace(_ context.Context, _ K, _ V) (V, bool, error) {
+	var zero V
+	return zero, false, nil
+}
+
+// Reset does nothing.
+func (Silent[K, V]) Reset(_ context.Context) error {
+	return nil
+}
+
+// Shutdown does nothing.
+func (Silent[K, V]) Shutdown(_ context.Context) {}
+
+// Traverse visits no entries.
+func (Silent[K, V]) Traverse(_ context.Context, _ func(context.Context, K, V) bool) error {
+	return nil
+}
+
+// Size always returns 0.
+func (Silent[K, V]) Size() (int, error) {
+	return 0, nil
+}
+
+// Capacity always returns 0.
+func (Silent[K, V]) Capacity() (int, error) {
+	return 0, nil
+}
@@ -3,25 +3,138 @@
 package internal
 
 import (
+	"time"
+
 	cachetypes "github.com/mcphone2004/cache/types"
 )
 
 // Options is the internal representation of the cache options.
 type Options[K comparable, V any] struct {
-	Capacity uint
-	OnEvict  cachetypes.CBFunc[K, V]
+	Capacity        uint
+	OnEvict         cachetypes.CBFunc[K, V]
+	EvictionChannel chan<- cachetypes.Entry[K, V]
+	// AsyncEvictionQueue is the worker queue size; only used when AsyncEviction is set.
+	AsyncEviction      bool
+	AsyncEvictionQueue uint
+	OnBatchEvict       cachetypes.BatchCBFunc[K, V]
+	BatchEvictionSize  uint
+	PanicHandler       func(recovered any)
+	EntryPoolLimit     uint
+	SizeLimit          SizeLimit[K, V]
+	ExpiryBucket       time.Duration
+	// ValueCodec is nil when values are encoded with gob.
+	ValueCodec *cachetypes.ValueCodec[V]
+	// AdmissionPolicy enables TinyLFU admission; see NewAdmission.
+	AdmissionPolicy bool
+	// Loader is nil unless WithLoader is set.
+	Loader cachetypes.LoaderFunc[K, V]
+	// ResetHighWaterMark makes Reset clear the high-water mark.
+	ResetHighWaterMark bool
+	// OnResize is nil unless WithResizeCB is set.
+	OnResize func(oldCapacity, newCapacity uint)
+	// EntryMetadata makes caches set Entry.Meta.
+	EntryMetadata bool
 }
 
 // ToOptions converts Options to options, validating the capacity and callback types.
-// It returns an error if the capacity is not positive or if the callback is of an incorrect
+// It returns an error if the capacity is not positive, if the callback is of an incorrect
+// type, or if an option only the lru package or caches with a TTL implement is set.
 func ToOptions[K comparable, V any](o cachetypes.Options) (
 	Options[K, V], error) {
-	var opt Options[K, V]
+	if err := rejectLRUOnly(o); err != nil {
+		return Options[K, V]{}, err
+	}
+	if err := rejectTTLOnly(o); err != nil {
+		return Options[K, V]{}, err
+	}
+	return toBoundedOptions[K, V](o)
+}
+
+// ToLRUOptions is ToOptions for the lru package, which also accepts the
+// options that only it implements.
+func ToLRUOptions[K comparable, V any](o cachetypes.Options) (
+	Options[K, V], error) {
+	if err := rejectTTLOnly(o); err != nil {
+		return Options[K, V]{}, err
+	}
+	return toBoundedOptions[K, V](o)
+}
+
+// ToTTLOptions is ToOptions for caches with a TTL, which also accept the
+// options that only apply to expiry.
+func ToTTLOptions[K comparable, V any](o cachetypes.Options) (
+	Options[K, V], error) {
+	if err := rejectLRUOnly(o); err != nil {
+		return Options[K, V]{}, err
+	}
+	return toBoundedOptions[K, V](o)
+}
+
+// ToUnboundedOptions converts Options for a cache without a capacity limit.
+// It returns an error if a capacity is set or if a callback is of an incorrect type.
+func ToUnboundedOptions[K comparable, V any](o cachetypes.Options) (
+	Options[K, V], error) {
+	if o.Capacity != 0 {
+		return Options[K, V]{}, &cachetypes.InvalidOptionsError{
+			Message: "capacity is not supported by an unbounded cache",
+		}
+	}
+	if err := rejectLRUOnly(o); err != nil {
+		return Options[K, V]{}, err
+	}
+	if err := rejectTTLOnly(o); err != nil {
+		return Options[K, V]{}, err
+	}
+	return toOptions[K, V](o)
+}
+
+// toBoundedOptions checks that the capacity is positive before toOptions
+func toBoundedOptions[K comparable, V any](o cachetypes.Options) (
+	Options[K, V], error) {
 	if o.Capacity == 0 {
-		return opt, &cachetypes.InvalidOptionsError{
+		return Options[K, V]{}, &cachetypes.InvalidOptionsError{
 			Message: "capacity must be positive",
 		}
 	}
+	return toOptions[K, V](o)
+}
+
+// rejectLRUOnly returns an error naming the first option set in o that only
+// the lru package implements, so that other caches do not silently ignore it
+func rejectLRUOnly(o cachetypes.Options) error {
+	var name string
+	switch {
+	case o.AdmissionPolicy:
+		name = "WithAdmissionPolicy"
+	case o.ResetHighWaterMark:
+		name = "WithResetHighWaterMark"
+	case o.OnResize != nil:
+		name = "WithResizeCB"
+	case o.EntryMetadata:
+		name = "WithEntryMetadata"
+	default:
+		return nil
+	}
+	return &cachetypes.InvalidOptionsError{
+		Message: name + " is only supported by lru",
+	}
+}
+
+// rejectTTLOnly returns an error if o sets an option that only caches with a
+// TTL implement, so that other caches do not silently ignore it
+func rejectTTLOnly(o cachetypes.Options) error {
+	if o.ExpiryBucket == 0 {
+		return nil
+	}
+	return &cachetypes.InvalidOptionsError{
+		Message: "WithExpiryBucket is only supported by caches with a TTL",
+	}
+}
+
+// toOptions validates and casts the callback types shared by all caches
+func toOptions[K comparable, V any](o cachetypes.Options) (
+	Options[K, V], error) {
+	var opt Options[K, V]
 	opt.Capacity = o.Capacity
 	if o.OnEvict != nil {
 		if cb, ok := o.OnEvict.(cachetypes.CBFunc[K, V]); ok {
@@ -32,5 +145,65 @@ func ToOptions[K comparable, V any](o cachetypes.Options) (
 			}
 		}
 	}
+	if o.EvictionChannel != nil {
+		if ch, ok := o.EvictionChannel.(chan<- cachetypes.Entry[K, V]); ok {
+			opt.EvictionChannel = ch
+		} else {
+			return opt, &cachetypes.InvalidOptionsError{
+				Message: "incorrect type for EvictionChannel",
+			}
+		}
+	}
+	if o.OnBatchEvict != nil {
+		if cb, ok := o.OnBatchEvict.(cachetypes.BatchCBFunc[K, V]); ok {
+			opt.OnBatchEvict = cb
+		} else {
+			return opt, &cachetypes.InvalidOptionsError{
+				Message: "incorrect type for OnBatchEvict",
+			}
+		}
+	}
+	limit, err := toSizeLimit[K, V](o)
+	if err != nil {
+		return opt, err
+	}
+	opt.SizeLimit = limit
+	if o.ExpiryBucket < 0 {
+		return opt, &cachetypes.InvalidOptionsError{
+			Message: "expiry bucket must not be negative",
+		}
+	}
+	opt.ExpiryBucket = o.ExpiryBucket
+	if o.ValueCodec != nil {
+		codec, ok := o.ValueCodec.(cachetypes.ValueCodec[V])
+		if !ok || codec.Marshal == nil || codec.Unmarshal == nil {
+			return opt, &cachetypes.InvalidOptionsError{
+				Message: "incorrect type for ValueCodec",
+			}
+		}
+		opt.ValueCodec = &codec
+	}
+	if o.Loader != nil {
+		loader, ok := o.Loader.(cachetypes.LoaderFunc[K, V])
+		if !ok || loader == nil {
+			return opt, &cachetypes.InvalidOptionsError{
+				Message: "incorrect type for Loader",
+			}
+		}
+		opt.Loader = loader
+	}
+	opt.BatchEvictionSize = o.BatchEvictionSize
+	handler, err := panicHandler(o.PanicHandler, o.EvictionPanicPolicy)
+	if err != nil {
+		return opt, err
+	}
+	opt.PanicHandler = handler
+	opt.EntryPoolLimit = o.EntryPoolLimit
+	opt.AsyncEviction = o.AsyncEviction
+	opt.AsyncEvictionQueue = o.AsyncEvictionQueue
+	opt.AdmissionPolicy = o.AdmissionPolicy
+	opt.ResetHighWaterMark = o.ResetHighWaterMark
+	opt.OnResize = o.OnResize
+	opt.EntryMetadata = o.EntryMetadata
 	return opt, nil
 }
@@ -1,9 +1,13 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -51,3 +55,194 @@ func TestWithCapacity(t *testing.T) {
 	o1.OnEvict(context.Background(), "a", 1)
 	require.Equal(t, 1, cnt)
 }
+
+func TestWithEvictionChannel(t *testing.T) {
+	o := cachetypes.Options{Capacity: 1}
+	cachetypes.WithEvictionChannel[int, int](make(chan cachetypes.Entry[int, int]))(&o)
+	_, err := ToOptions[string, int](o)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "incorrect type for EvictionChannel", aerr.Error())
+
+	ch := make(chan cachetypes.Entry[string, int], 1)
+	cachetypes.WithEvictionChannel[string, int](ch)(&o)
+	o1, err := ToOptions[string, int](o)
+	require.NoError(t, err)
+	require.NotNil(t, o1.EvictionChannel)
+}
+
+func TestWithBatchEvictionCB(t *testing.T) {
+	o := cachetypes.Options{Capacity: 1}
+	cachetypes.WithBatchEvictionCB[int, int](func(context.Context, []cachetypes.Entry[int, int]) {}, 8)(&o)
+	_, err := ToOptions[string, int](o)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "incorrect type for OnBatchEvict", aerr.Error())
+
+	cachetypes.WithBatchEvictionCB[string, int](func(context.Context, []cachetypes.Entry[string, int]) {}, 8)(&o)
+	o1, err := ToOptions[string, int](o)
+	require.NoError(t, err)
+	require.NotNil(t, o1.OnBatchEvict)
+	require.Equal(t, uint(8), o1.BatchEvictionSize)
+}
+
+func TestWithMaxEntryBytes(t *testing.T) {
+	var aerr *cachetypes.InvalidOptionsError
+
+	o := cachetypes.Options{Capacity: 1}
+	cachetypes.WithMaxKeyBytes(4, func(int) int { return 0 })(&o)
+	_, err := ToOptions[string, int](o)
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "incorrect type for KeySizer", aerr.Error())
+
+	o = cachetypes.Options{Capacity: 1}
+	cachetypes.WithMaxValueBytes[int](4, nil)(&o)
+	_, err = ToOptions[string, int](o)
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "incorrect type for ValueSizer", aerr.Error())
+
+	o = cachetypes.Options{Capacity: 1}
+	cachetypes.WithMaxKeyBytes(4, func(k string) int { return len(k) })(&o)
+	cachetypes.WithMaxValueBytes(10, func(v int) int { return v })(&o)
+	o1, err := ToOptions[string, int](o)
+	require.NoError(t, err)
+	require.NoError(t, o1.SizeLimit.Check("abcd", 10))
+	require.ErrorIs(t, o1.SizeLimit.Check("abcde", 10), cachetypes.ErrEntryTooLarge)
+	require.ErrorIs(t, o1.SizeLimit.Check("abcd", 11), cachetypes.ErrEntryTooLarge)
+	var cErr *cachetypes.CapacityExceededError
+	require.ErrorAs(t, o1.SizeLimit.Check("abcde", 10), &cErr)
+	require.Equal(t, cachetypes.CapacityExceededError{Field: "key", Size: 5, Limit: 4}, *cErr)
+	require.ErrorAs(t, o1.SizeLimit.Check("abcd", 11), &cErr)
+	require.Equal(t, "value size 11 exceeds the limit of 10", cErr.Error())
+
+	// without limits everything is admitted
+	o1, err = ToOptions[string, int](cachetypes.Options{Capacity: 1})
+	require.NoError(t, err)
+	require.NoError(t, o1.SizeLimit.Check("a very long key", 1<<30))
+}
+
+func TestWithExpiryBucket(t *testing.T) {
+	o := cachetypes.Options{Capacity: 1}
+	cachetypes.WithExpiryBucket(-time.Second)(&o)
+	_, err := ToTTLOptions[string, int](o)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "expiry bucket must not be negative", aerr.Error())
+
+	cachetypes.WithExpiryBucket(time.Second)(&o)
+	o1, err := ToTTLOptions[string, int](o)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, o1.ExpiryBucket)
+
+	// caches without a TTL reject it rather than ignore it
+	_, err = ToOptions[string, int](o)
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "WithExpiryBucket is only supported by caches with a TTL", aerr.Error())
+	_, err = ToLRUOptions[string, int](o)
+	require.ErrorAs(t, err, &aerr)
+	o.Capacity = 0
+	_, err = ToUnboundedOptions[string, int](o)
+	require.ErrorAs(t, err, &aerr)
+}
+
+func TestWithValueCodec(t *testing.T) {
+	o := cachetypes.Options{Capacity: 1}
+	cachetypes.WithValueCodec(
+		func(string) ([]byte, error) { return nil, nil },
+		func([]byte) (string, error) { return "", nil },
+	)(&o)
+	_, err := ToOptions[string, int](o)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "incorrect type for ValueCodec", aerr.Error())
+
+	cachetypes.WithValueCodec(
+		func(int) ([]byte, error) { return nil, nil },
+		func([]byte) (int, error) { return 0, nil },
+	)(&o)
+	o1, err := ToOptions[string, int](o)
+	require.NoError(t, err)
+	require.NotNil(t, o1.ValueCodec)
+}
+
+func TestWithLoader(t *testing.T) {
+	o := cachetypes.Options{Capacity: 1}
+	cachetypes.WithLoader(func(context.Context, string) (string, bool, error) {
+		return "", false, nil
+	})(&o)
+	_, err := ToOptions[string, int](o)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "incorrect type for Loader", aerr.Error())
+
+	cachetypes.WithLoader(func(context.Context, string) (int, bool, error) {
+		return 0, false, nil
+	})(&o)
+	o1, err := ToOptions[string, int](o)
+	require.NoError(t, err)
+	require.NotNil(t, o1.Loader)
+}
+
+func TestWithEvictionPanicPolicy(t *testing.T) {
+	o := cachetypes.Options{Capacity: 1}
+	cachetypes.WithEvictionPanicPolicy(cachetypes.EvictionPanicPropagate + 1)(&o)
+	_, err := ToOptions[string, int](o)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "unknown eviction panic policy", aerr.Error())
+
+	var recovered []any
+	cachetypes.WithPanicHandler(func(r any) { recovered = append(recovered, r) })(&o)
+
+	cachetypes.WithEvictionPanicPolicy(cachetypes.EvictionPanicLog)(&o)
+	o1, err := ToOptions[string, int](o)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	func() {
+		defer recoverPanic(o1.PanicHandler)
+		panic("logged")
+	}()
+	require.Contains(t, buf.String(), "eviction callback panicked: logged")
+	require.Contains(t, buf.String(), "TestWithEvictionPanicPolicy")
+
+	cachetypes.WithEvictionPanicPolicy(cachetypes.EvictionPanicPropagate)(&o)
+	o1, err = ToOptions[string, int](o)
+	require.NoError(t, err)
+	require.PanicsWithValue(t, "propagated", func() {
+		defer recoverPanic(o1.PanicHandler)
+		panic("propagated")
+	})
+	require.Equal(t, []any{"logged", "propagated"}, recovered)
+}
+
+func TestLRUOnlyOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		option func(*cachetypes.Options)
+	}{
+		{"WithAdmissionPolicy", cachetypes.WithAdmissionPolicy()},
+		{"WithResetHighWaterMark", cachetypes.WithResetHighWaterMark()},
+		{"WithResizeCB", cachetypes.WithResizeCB(func(uint, uint) {})},
+		{"WithEntryMetadata", cachetypes.WithEntryMetadata()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := cachetypes.Options{Capacity: 1}
+			tc.option(&o)
+			_, err := ToLRUOptions[string, int](o)
+			require.NoError(t, err)
+
+			var aerr *cachetypes.InvalidOptionsError
+			_, err = ToOptions[string, int](o)
+			require.ErrorAs(t, err, &aerr)
+			require.Equal(t, tc.name+" is only supported by lru", aerr.Error())
+			_, err = ToTTLOptions[string, int](o)
+			require.ErrorAs(t, err, &aerr)
+
+			o.Capacity = 0
+			_, err = ToUnboundedOptions[string, int](o)
+			require.ErrorAs(t, err, &aerr)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// recoverPanic recovers from a panic raised by a user callback and passes the
+// recovered value to handler, or prints it when no handler is set.
+// It must be deferred directly.
+func recoverPanic(handler func(recovered any)) {
+	if r := recover(); r != nil {
+		if handler != nil {
+			handler(r)
+			return
+		}
+		fmt.Println("Recovered from panic:", r)
+	}
+}
+
+// panicHandler returns the handler implementing policy on top of the
+// user-supplied handler h, which may be nil. The result is called from the
+// deferred recoverPanic, so re-panicking in it propagates the panic and the
+// stack it records still includes the panicking callback.
+func panicHandler(h func(recovered any), policy cachetypes.EvictionPanicPolicy) (
+	func(recovered any), error) {
+	switch policy {
+	case cachetypes.EvictionPanicRecover:
+		return h, nil
+	case cachetypes.EvictionPanicLog:
+		return func(r any) {
+			log.Printf("cache: eviction callback panicked: %v\n%s", r, debug.Stack())
+			if h != nil {
+				h(r)
+			}
+		}, nil
+	case cachetypes.EvictionPanicPropagate:
+		return func(r any) {
+			if h != nil {
+				h(r)
+			}
+			panic(r)
+		}, nil
+	}
+	return nil, &cachetypes.InvalidOptionsError{
+		Message: "unknown eviction panic policy",
+	}
+}
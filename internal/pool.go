@@ -0,0 +1,79 @@
+package internal
+
+import "sync"
+
+// entryPool recycles list entries. By default it is backed by a sync.Pool;
+// with a limit it keeps at most that many idle entries in a buffered channel
+// and lets the rest be garbage collected.
+type entryPool[K comparable, V any] struct {
+	pool *sync.Pool
+	free chan *Entry[K, V]
+}
+
+// newEntryPool creates a pool pre-populated with n entries. A limit of 0
+// means unbounded; otherwise n is capped at limit.
+func newEntryPool[K comparable, V any](n, limit uint) entryPool[K, V] {
+	var p entryPool[K, V]
+	if limit == 0 {
+		p.pool = &sync.Pool{
+			New: func() any {
+				return &Entry[K, V]{}
+			},
+		}
+	} else {
+		p.free = make(chan *Entry[K, V], limit)
+		n = min(n, limit)
+	}
+	for range n {
+		p.put(&Entry[K, V]{})
+	}
+	return p
+}
+
+// get returns an idle entry or allocates a new one.
+func (p *entryPool[K, V]) get() *Entry[K, V] {
+	if p.pool != nil {
+		return p.pool.Get().(*Entry[K, V]) //nolint:forcetypeassert // pool only contains *Entry[K, V]
+	}
+	select {
+	case en := <-p.free:
+		return en
+	default:
+		return &Entry[K, V]{}
+	}
+}
+
+// put clears en and keeps it for reuse, dropping it if the pool is full.
+func (p *entryPool[K, V]) put(en *Entry[K, V]) {
+	en.Key = zeroOf[K]()
+	en.Value = zeroOf[V]()
+	en.Meta = nil
+	if p.pool != nil {
+		p.pool.Put(en)
+		return
+	}
+	select {
+	case p.free <- en:
+	default:
+	}
+}
+
+// trim drops idle entries of a bounded pool beyond keep so they can be
+// garbage collected. It is safe to call concurrently with get and put. An
+// unbounded pool is left alone: the garbage collector already releases what
+// a sync.Pool holds.
+func (p *entryPool[K, V]) trim(keep int) {
+	for len(p.free) > keep {
+		select {
+		case <-p.free:
+		default:
+			return
+		}
+	}
+}
+
+// idle returns the number of retained entries of a bounded pool. An
+// unbounded pool reports 0 because sync.Pool cannot be measured.
+func (p *entryPool[K, V]) idle() int {
+	return len(p.free)
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryPool_Bounded(t *testing.T) {
+	p := newEntryPool[int, string](10, 4)
+	require.Equal(t, 4, p.idle()) // pre-population is capped
+
+	var ens []*Entry[int, string]
+	for range 6 {
+		ens = append(ens, p.get())
+	}
+	require.Zero(t, p.idle())
+	for _, en := range ens {
+		en.Key, en.Value = 1, "one"
+		p.put(en)
+	}
+	require.Equal(t, 4, p.idle()) // surplus entries are dropped
+
+	en := p.get()
+	require.Zero(t, en.Key)
+	require.Empty(t, en.Value)
+}
+
+func TestEntryPool_Trim(t *testing.T) {
+	p := newEntryPool[int, string](10, 8)
+	p.trim(3)
+	require.Equal(t, 3, p.idle())
+	p.trim(5)
+	require.Equal(t, 3, p.idle()) // trimming never adds entries
+}
+
+func TestList_Compact(t *testing.T) {
+	l := NewList[int, string](10, nil)
+	l.SetEntryPoolLimit(8)
+	for i := range 4 {
+		l.PushFront(i, "v")
+	}
+	require.Equal(t, 4, l.entryPool.idle())
+	l.SetCapacity(6)
+	l.Compact()
+	require.Equal(t, 2, l.entryPool.idle()) // room for the 2 free slots
+	require.Equal(t, 4, l.Size())
+}
+
+func TestList_EntryPoolLimit(t *testing.T) {
+	l := NewList[int, string](100, nil)
+	l.SetEntryPoolLimit(2)
+	require.Equal(t, 2, l.entryPool.idle())
+
+	var ens []*Entry[int, string]
+	for i := range 5 {
+		ens = append(ens, l.Remove(l.PushFront(i, "v")))
+	}
+	l.OnEvictAll(context.Background(), ens)
+	require.Equal(t, 2, l.entryPool.idle())
+
+	// a zero limit keeps the unbounded sync.Pool
+	l = NewList[int, string](4, nil)
+	l.SetEntryPoolLimit(0)
+	require.NotNil(t, l.entryPool.pool)
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// SizeLimit rejects entries whose key or value exceeds a configured size.
+// The zero value admits everything.
+type SizeLimit[K comparable, V any] struct {
+	maxKey     int
+	keySizer   func(K) int
+	maxValue   int
+	valueSizer func(V) int
+}
+
+// toSizeLimit validates and casts the size limit options
+func toSizeLimit[K comparable, V any](o cachetypes.Options) (SizeLimit[K, V], error) {
+	var l SizeLimit[K, V]
+	if o.MaxKeyBytes > 0 {
+		sizer, ok := o.KeySizer.(func(K) int)
+		if !ok || sizer == nil {
+			return l, &cachetypes.InvalidOptionsError{
+				Message: "incorrect type for KeySizer",
+			}
+		}
+		l.maxKey = int(o.MaxKeyBytes) //nolint:gosec // sizes beyond MaxInt are not meaningful
+		l.keySizer = sizer
+	}
+	if o.MaxValueBytes > 0 {
+		sizer, ok := o.ValueSizer.(func(V) int)
+		if !ok || sizer == nil {
+			return l, &cachetypes.InvalidOptionsError{
+				Message: "incorrect type for ValueSizer",
+			}
+		}
+		l.maxValue = int(o.MaxValueBytes) //nolint:gosec // sizes beyond MaxInt are not meaningful
+		l.valueSizer = sizer
+	}
+	return l, nil
+}
+
+// Check returns a *cachetypes.CapacityExceededError, which matches
+// cachetypes.ErrEntryTooLarge, if key or value exceeds its limit.
+func (l SizeLimit[K, V]) Check(key K, value V) error {
+	if l.keySizer != nil {
+		if n := l.keySizer(key); n > l.maxKey {
+			return &cachetypes.CapacityExceededError{Field: "key", Size: n, Limit: l.maxKey}
+		}
+	}
+	if l.valueSizer != nil {
+		if n := l.valueSizer(value); n > l.maxValue {
+			return &cachetypes.CapacityExceededError{Field: "value", Size: n, Limit: l.maxValue}
+		}
+	}
+	return nil
+}
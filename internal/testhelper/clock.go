@@ -0,0 +1,106 @@
+package testhelper
+
+import (
+	"sync"
+	"time"
+
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// FakeClock is a cachetypes.Clock whose time only moves on Advance.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// Ensure FakeClock implements the Clock interface.
+var _ cachetypes.Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the fake current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer that fires once the clock reaches now+d.
+func (c *FakeClock) NewTimer(d time.Duration) cachetypes.Timer {
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	c.mu.Lock()
+	c.timers = append(c.timers, t)
+	c.mu.Unlock()
+	return t
+}
+
+// Advance moves the clock forward by d and fires every timer that is due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	timers := append([]*fakeTimer(nil), c.timers...)
+	c.mu.Unlock()
+	for _, t := range timers {
+		t.fireIfDue(now)
+	}
+}
+
+// fakeTimer is a one-shot timer driven by a FakeClock.
+type fakeTimer struct {
+	clock *FakeClock
+
+	mu       sync.Mutex
+	deadline time.Time
+	active   bool
+	ch       chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop deactivates the timer and discards a pending tick.
+func (t *fakeTimer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	wasActive := t.active
+	t.active = false
+	t.drain()
+	return wasActive
+}
+
+// Reset rearms the timer to fire d after the current fake time.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	now := t.clock.Now()
+	t.mu.Lock()
+	wasActive := t.active
+	t.drain()
+	t.deadline = now.Add(d)
+	t.active = true
+	t.mu.Unlock()
+	t.fireIfDue(now)
+	return wasActive
+}
+
+func (t *fakeTimer) fireIfDue(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active && !now.Before(t.deadline) {
+		t.active = false
+		t.ch <- now
+	}
+}
+
+// drain discards a pending tick; the caller holds t.mu.
+func (t *fakeTimer) drain() {
+	select {
+	case <-t.ch:
+	default:
+	}
+}
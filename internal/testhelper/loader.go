@@ -0,0 +1,77 @@
+package testhelper
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcphone2004/cache/iface"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+type newLoaderCacheFn func(loader cachetypes.LoaderFunc[int, string]) (
+	iface.Cache[int, string], error)
+
+// CommonLoaderTest verifies that Get fills misses from the loader set with
+// WithLoader, stores found values, and neither caches misses nor errors.
+func CommonLoaderTest(t *testing.T, newCache newLoaderCacheFn) {
+	ctx := context.Background()
+	errLoad := errors.New("load failed")
+	calls := map[int]int{}
+	c, err := newCache(func(_ context.Context, k int) (string, bool, error) {
+		calls[k]++
+		switch {
+		case k < 0:
+			return "", false, errLoad
+		case k >= 100:
+			return "", false, nil
+		}
+		return strconv.Itoa(k), true, nil
+	})
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	// a found value is loaded once and then served from the cache
+	for range 2 {
+		v, ok, err := c.Get(ctx, 1)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "1", v)
+	}
+	require.Equal(t, 1, calls[1])
+	ok, err := c.Has(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// a present key never reaches the loader
+	require.NoError(t, c.Put(ctx, 2, "two"))
+	v, ok, err := c.Get(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "two", v)
+	require.Zero(t, calls[2])
+
+	// not found is reported as a miss and not remembered
+	for range 2 {
+		_, ok, err = c.Get(ctx, 100)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	require.Equal(t, 2, calls[100])
+
+	// errors propagate and nothing is cached
+	_, ok, err = c.Get(ctx, -1)
+	require.ErrorIs(t, err, errLoad)
+	require.False(t, ok)
+	ok, err = c.Has(ctx, -1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	c.Shutdown(ctx)
+	_, _, err = c.Get(ctx, 3)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	require.Zero(t, calls[3])
+}
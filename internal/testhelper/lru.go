@@ -4,11 +4,14 @@ package testhelper
 import (
 	"context"
 	"iter"
+	"math/rand/v2"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
 
 	"github.com/mcphone2004/cache/iface"
 	cachetypes "github.com/mcphone2004/cache/types"
@@ -289,6 +292,12 @@ func CommonShutdownTest(t *testing.T, newCache newCacheFn[int, string]) {
 	_, err = cache.Delete(ctx, 1)
 	require.ErrorIs(t, err, cachetypes.ErrShutdown)
 
+	_, _, err = cache.GetAndDelete(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+
+	_, err = cache.Has(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+
 	_, err = cache.Size()
 	require.ErrorIs(t, err, cachetypes.ErrShutdown)
 
@@ -305,6 +314,50 @@ func CommonShutdownTest(t *testing.T, newCache newCacheFn[int, string]) {
 	cache.Shutdown(ctx)
 }
 
+// CommonEvictionCallbackTest verifies that the eviction callback fires
+// exactly once for every entry removed by capacity eviction, Delete,
+// GetAndDelete, Reset, and Shutdown.
+func CommonEvictionCallbackTest(t *testing.T, newCache newCacheFn[int, string]) {
+	t.Helper()
+	var mu sync.Mutex
+	var evicted []int
+	take := func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		got := evicted
+		evicted = nil
+		return got
+	}
+	cache, err := newCache(2, func(_ context.Context, key int, value string) {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, strconv.Itoa(key), value)
+		evicted = append(evicted, key)
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for k := 1; k <= 3; k++ {
+		require.NoError(t, cache.Put(ctx, k, strconv.Itoa(k)))
+	}
+	require.Equal(t, []int{1}, take(), "capacity eviction")
+
+	_, err = cache.Delete(ctx, 2)
+	require.NoError(t, err)
+	_, _, err = cache.GetAndDelete(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3}, take(), "Delete and GetAndDelete")
+
+	require.NoError(t, cache.Put(ctx, 4, "4"))
+	require.NoError(t, cache.Put(ctx, 5, "5"))
+	require.NoError(t, cache.Reset(ctx))
+	require.ElementsMatch(t, []int{4, 5}, take(), "Reset")
+
+	require.NoError(t, cache.Put(ctx, 6, "6"))
+	cache.Shutdown(ctx)
+	require.Equal(t, []int{6}, take(), "Shutdown")
+}
+
 // CommonDeleteNonExistentTest verifies that deleting a key that was never inserted
 // returns (false, nil) without error.
 func CommonDeleteNonExistentTest(t *testing.T, newCache newCacheFn[int, string]) {
@@ -368,6 +421,41 @@ func CommonEvictionCallbackPanicTest(t *testing.T, newCache newCacheFn[int, stri
 	require.Equal(t, "two", val)
 }
 
+// CommonEvictionPanicPropagateTest verifies that Reset and Shutdown stay
+// consistent when an eviction callback panics under EvictionPanicPropagate:
+// the panic reaches the caller without corrupting the lock, the cache stays
+// usable after Reset, and Shutdown still stops every goroutine the cache
+// started. newCache must configure cachetypes.EvictionPanicPropagate.
+func CommonEvictionPanicPropagateTest(t *testing.T, newCache newCacheFn[int, string]) {
+	t.Helper()
+	ignore := goleak.IgnoreCurrent()
+	ctx := context.Background()
+	cache, err := newCache(4, func(_ context.Context, k int, _ string) {
+		if k == 1 {
+			panic("callback bug")
+		}
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.NoError(t, cache.Put(ctx, 2, "two"))
+	require.PanicsWithValue(t, "callback bug", func() { _ = cache.Reset(ctx) })
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	v, ok, err := cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "one", v)
+
+	require.PanicsWithValue(t, "callback bug", func() { cache.Shutdown(ctx) })
+	_, _, err = cache.Get(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	require.NotPanics(t, func() { cache.Shutdown(ctx) })
+	goleak.VerifyNone(t, ignore)
+}
+
 // CommonConcurrentTest verifies that concurrent Put/Get/Delete operations do not
 // cause data races or panics. Run with -race to get full benefit.
 func CommonConcurrentTest(t *testing.T, newCache newCacheFn[int, string]) {
@@ -535,3 +623,281 @@ func CommonStressShutdownTest(t *testing.T, newCache newCacheFn[int, string]) {
 	_, _, err = cache.Get(ctx, 0)
 	require.ErrorIs(t, err, cachetypes.ErrShutdown)
 }
+
+// CommonGetAndDeleteTest verifies that GetAndDelete returns and removes an
+// entry, fires the eviction callback exactly once, and that concurrent callers
+// never both claim the same key.
+func CommonGetAndDeleteTest(t *testing.T, newCache newCacheFn[int, string]) {
+	t.Helper()
+	var mu sync.Mutex
+	evicted := make(map[int]int)
+	cache, err := newCache(64, func(_ context.Context, key int, _ string) {
+		mu.Lock()
+		evicted[key]++
+		mu.Unlock()
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+
+	val, found, err := cache.GetAndDelete(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "one", val)
+	require.Equal(t, 1, evicted[1])
+
+	_, ok, err := cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	val, found, err = cache.GetAndDelete(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, found)
+	require.Empty(t, val)
+	require.Equal(t, 1, evicted[1])
+
+	// Concurrent consumers race to claim each key; every key must be claimed once.
+	const keys = 32
+	const consumers = 8
+	for k := range keys {
+		require.NoError(t, cache.Put(ctx, k, strconv.Itoa(k)))
+	}
+	claims := make([]int, keys)
+	var wg sync.WaitGroup
+	wg.Add(consumers)
+	for range consumers {
+		go func() {
+			defer wg.Done()
+			for k := range keys {
+				if _, claimed, _ := cache.GetAndDelete(ctx, k); claimed {
+					mu.Lock()
+					claims[k]++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for k := range keys {
+		require.Equal(t, 1, claims[k], "key %d", k)
+	}
+}
+
+// CommonReplaceTest verifies that Replace updates only existing keys,
+// returns the previous value without firing the eviction callback, and is
+// atomic: concurrent replacements of one key each observe a distinct
+// previous value.
+func CommonReplaceTest(t *testing.T, newCache newCacheFn[int, string]) {
+	t.Helper()
+	var evicted atomic.Int32
+	cache, err := newCache(64, func(context.Context, int, string) {
+		evicted.Add(1)
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	defer cache.Shutdown(ctx)
+
+	old, found, err := cache.Replace(ctx, 1, "one")
+	require.NoError(t, err)
+	require.False(t, found)
+	require.Empty(t, old)
+	found, err = cache.Has(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, found, "Replace must not insert an absent key")
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	old, found, err = cache.Replace(ctx, 1, "uno")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "one", old)
+	v, ok, err := cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "uno", v)
+	require.Zero(t, evicted.Load())
+
+	const writers = 8
+	const perWriter = 50
+	var mu sync.Mutex
+	seen := map[string]int{}
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := range writers {
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				old, found, err := cache.Replace(ctx, 1, strconv.Itoa(w*perWriter+i))
+				if err != nil || !found {
+					continue
+				}
+				mu.Lock()
+				seen[old]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	last, _, err := cache.Get(ctx, 1)
+	require.NoError(t, err)
+	seen[last]++
+	require.Len(t, seen, writers*perWriter+1)
+	for val, n := range seen {
+		require.Equal(t, 1, n, "value %q observed %d times", val, n)
+	}
+
+	cache.Shutdown(ctx)
+	_, _, err = cache.Replace(ctx, 1, "one")
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+// CommonHasTest verifies that Has reports presence for inserted keys and
+// absence for missing or deleted keys.
+func CommonHasTest(t *testing.T, newCache newCacheFn[int, string]) {
+	t.Helper()
+	cache, err := newCache(4, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	defer cache.Shutdown(ctx)
+
+	found, err := cache.Has(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	found, err = cache.Has(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	_, err = cache.Delete(ctx, 1)
+	require.NoError(t, err)
+	found, err = cache.Has(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+// CommonHasNoPromoteTest verifies that Has does not mark a key as recently
+// used, so it is still the next eviction victim.
+func CommonHasNoPromoteTest(t *testing.T, newCache newCacheFn[int, string]) {
+	t.Helper()
+	cache, err := newCache(2, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.NoError(t, cache.Put(ctx, 2, "two"))
+
+	found, err := cache.Has(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.NoError(t, cache.Put(ctx, 3, "three")) // must still evict key 1
+	found, err = cache.Has(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, found)
+	found, err = cache.Has(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, found)
+}
+
+// CommonConcurrentStressTest runs randomized Put/Get/Delete operations from
+// many goroutines and checks the cache invariants: Size never exceeds
+// Capacity, and once the workers stop Traverse visits exactly Size entries.
+// Run with -race to get full benefit.
+func CommonConcurrentStressTest(t *testing.T, newCache newCacheFn[int, string]) {
+	t.Helper()
+	ctx := context.Background()
+	cache, err := newCache(64, nil)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	capacity, err := cache.Capacity()
+	require.NoError(t, err)
+
+	const goroutines = 16
+	const ops = 2000
+	const keySpace = 256
+	var wg sync.WaitGroup
+	var oversize atomic.Int64
+	wg.Add(goroutines)
+	for g := range goroutines {
+		go func(seed uint64) {
+			defer wg.Done()
+			r := rand.New(rand.NewPCG(seed, seed)) //nolint:gosec // test workload, not crypto
+			for range ops {
+				key := r.IntN(keySpace)
+				switch r.IntN(10) {
+				case 0, 1, 2, 3:
+					_ = cache.Put(ctx, key, strconv.Itoa(key))
+				case 4, 5, 6, 7:
+					if v, ok, err := cache.Get(ctx, key); err == nil && ok && v != strconv.Itoa(key) {
+						panic("value does not match key " + strconv.Itoa(key))
+					}
+				case 8:
+					_, _ = cache.Delete(ctx, key)
+				default:
+					if size, err := cache.Size(); err == nil && size > capacity {
+						oversize.Store(int64(size))
+					}
+				}
+			}
+		}(uint64(g)) //nolint:gosec // g is a small loop index
+	}
+	wg.Wait()
+	require.Zero(t, oversize.Load(), "Size exceeded Capacity %d", capacity)
+
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.LessOrEqual(t, size, capacity)
+	visited := 0
+	require.NoError(t, cache.Traverse(ctx, func(_ context.Context, _ int, _ string) bool {
+		visited++
+		return true
+	}))
+	require.Equal(t, size, visited)
+}
+
+// CommonCapacityTest verifies the capacity contract: Capacity reports
+// expected(requested) for a range of requested capacities, and Size never
+// exceeds Capacity after inserting many more keys than fit. expected maps
+// the requested capacity to the reported one, e.g. to account for a sharded
+// cache rounding capacity up to a whole number of entries per shard.
+func CommonCapacityTest(t *testing.T, newCache newCacheFn[int, string],
+	expected func(requested uint) int) {
+	t.Helper()
+	ctx := context.Background()
+	for _, requested := range []uint{1, 2, 7, 64, 1000} {
+		cache, err := newCache(requested, nil)
+		require.NoError(t, err)
+
+		capacity, err := cache.Capacity()
+		require.NoError(t, err)
+		require.Equal(t, expected(requested), capacity, "requested %d", requested)
+		require.GreaterOrEqual(t, capacity, int(requested)) //nolint:gosec // requested is small
+
+		for i := range 4 * capacity {
+			require.NoError(t, cache.Put(ctx, i, strconv.Itoa(i)))
+			if i%16 == 0 {
+				size, err := cache.Size()
+				require.NoError(t, err)
+				require.LessOrEqual(t, size, capacity, "requested %d", requested)
+			}
+		}
+		size, err := cache.Size()
+		require.NoError(t, err)
+		require.LessOrEqual(t, size, capacity, "requested %d", requested)
+		cache.Shutdown(ctx)
+	}
+}
+
+// ExactCapacity is the expected function for CommonCapacityTest when a cache
+// reports exactly the requested capacity.
+func ExactCapacity(requested uint) int {
+	return int(requested) //nolint:gosec // test capacities are small
+}
@@ -0,0 +1,113 @@
+package testhelper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// TTLCache is a cache that supports per-entry expiry.
+type TTLCache[K comparable, V any] interface {
+	iface.Cache[K, V]
+	PutWithTTL(ctx context.Context, key K, value V, ttl time.Duration) error
+}
+
+type newTTLCacheFn[K comparable, V any] func(clock cachetypes.Clock, capacity uint,
+	evictionCB func(context.Context, K, V)) (TTLCache[K, V], error)
+
+// CommonTTLExpiryTest verifies TTL expiry against a fake clock: expired keys
+// become misses, the eviction callback fires exactly once per expired key,
+// keys without a TTL never expire, and re-putting a key resets its expiry.
+func CommonTTLExpiryTest(t *testing.T, newCache newTTLCacheFn[int, string]) {
+	t.Helper()
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var mu sync.Mutex
+	evicted := make(map[int]int)
+	evictedCount := func(k int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return evicted[k]
+	}
+	cache, err := newCache(clock, 10, func(_ context.Context, k int, _ string) {
+		mu.Lock()
+		evicted[k]++
+		mu.Unlock()
+	})
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.PutWithTTL(ctx, 1, "one", 10*time.Second))
+	require.NoError(t, cache.PutWithTTL(ctx, 2, "two", 10*time.Second))
+	require.NoError(t, cache.Put(ctx, 3, "three")) // no TTL
+
+	clock.Advance(5 * time.Second)
+	// re-putting key 2 resets its expiry to 15s
+	require.NoError(t, cache.PutWithTTL(ctx, 2, "TWO", 10*time.Second))
+
+	clock.Advance(6 * time.Second) // t=11s: key 1 expires
+	require.Eventually(t, func() bool { return evictedCount(1) == 1 },
+		time.Second, time.Millisecond)
+	_, ok, err := cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+	v, ok, err := cache.Get(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "TWO", v)
+
+	clock.Advance(5 * time.Second) // t=16s: key 2 expires
+	require.Eventually(t, func() bool { return evictedCount(2) == 1 },
+		time.Second, time.Millisecond)
+	_, ok, err = cache.Get(ctx, 2)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	clock.Advance(time.Hour)
+	v, ok, err = cache.Get(ctx, 3)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "three", v)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, map[int]int{1: 1, 2: 1}, evicted)
+}
+
+// CommonNoLeakAfterShutdownTest verifies that Shutdown stops the goroutines a
+// TTL cache starts, such as the one driving its ExpiryMap, both after entries
+// have expired and while others are still pending. goleak.VerifyTestMain only
+// reports a leak once the whole package has run; this pins it to the cache
+// under test. Goroutines running before the call are ignored.
+func CommonNoLeakAfterShutdownTest(t *testing.T, newCache newTTLCacheFn[int, string]) {
+	t.Helper()
+	ignore := goleak.IgnoreCurrent()
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cache, err := newCache(clock, 10, func(context.Context, int, string) {})
+	require.NoError(t, err)
+	for k := range 5 {
+		require.NoError(t, cache.PutWithTTL(ctx, k, "v", time.Second))
+	}
+	clock.Advance(time.Minute)
+	require.Eventually(t, func() bool {
+		size, err := cache.Size()
+		return err == nil && size == 0
+	}, time.Second, time.Millisecond)
+
+	// leave entries waiting to expire so their timers are pending
+	for k := range 5 {
+		require.NoError(t, cache.PutWithTTL(ctx, k, "v", time.Hour))
+	}
+	cache.Shutdown(ctx)
+	goleak.VerifyNone(t, ignore)
+}
@@ -0,0 +1,179 @@
+package lru
+
+import (
+	"context"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// keyed is the value stored by KeyFuncCache: the caller's key is kept next
+// to the value so that callbacks and Traverse see it rather than its string.
+type keyed[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// KeyFuncCache is an LRU cache whose keys are compared through a key
+// function instead of ==, created by NewWithKeyFunc.
+type KeyFuncCache[K comparable, V any] struct {
+	keyFn     func(K) string
+	inner     *Cache[string, keyed[K, V]]
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+	loader    cachetypes.LoaderFunc[K, V]
+}
+
+// Ensure KeyFuncCache implements the Cache interface.
+var _ iface.Cache[string, int] = (*KeyFuncCache[string, int])(nil)
+
+// Ensure KeyFuncCache implements the Toucher interface.
+var _ iface.Toucher[string] = (*KeyFuncCache[string, int])(nil)
+
+// NewWithKeyFunc creates an LRU cache that stores entries under keyFn(key),
+// so two keys are the same entry when keyFn maps them to the same string,
+// e.g. structs that should be equal on a subset of their fields. When such
+// keys collide, the key last passed to Put or Replace is the one reported to
+// eviction callbacks, the loader and Traverse.
+//
+// Every operation calls keyFn and hashes the resulting string, which usually
+// allocates and is noticeably slower than hashing a small comparable key, so
+// prefer New unless == does not express the equality you need. The options
+// are those of New; WithValueCodec has no effect since the cache offers no
+// Snapshot.
+func NewWithKeyFunc[K comparable, V any](keyFn func(K) string,
+	options ...func(o *cachetypes.Options)) (*KeyFuncCache[K, V], error) {
+	if keyFn == nil {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "keyFn cannot be nil",
+		}
+	}
+	var o cachetypes.Options
+	for _, cb := range options {
+		cb(&o)
+	}
+
+	o1, err := internal.ToLRUOptions[K, V](o)
+	if err != nil {
+		return nil, err
+	}
+	c := &KeyFuncCache[K, V]{
+		keyFn:     keyFn,
+		evictor:   internal.NewEvictor(o1),
+		sizeLimit: o1.SizeLimit,
+		loader:    o1.Loader,
+	}
+	// the inner cache only stores entries; eviction, size limits and loading
+	// are handled here, where the caller's key and value are known
+	o2 := internal.Options[string, keyed[K, V]]{
+		Capacity:           o1.Capacity,
+		PanicHandler:       o1.PanicHandler,
+		EntryPoolLimit:     o1.EntryPoolLimit,
+		AdmissionPolicy:    o1.AdmissionPolicy,
+		ResetHighWaterMark: o1.ResetHighWaterMark,
+		OnResize:           o1.OnResize,
+		EntryMetadata:      o1.EntryMetadata,
+	}
+	// the inner cache recovers panics raised by cb with the policy handler
+	if cb := c.evictor.Callback(); cb != nil {
+		o2.OnEvict = func(ctx context.Context, _ string, e keyed[K, V]) {
+			cb(ctx, e.key, e.value)
+		}
+	}
+	if o1.OnBatchEvict != nil {
+		o2.OnBatchEvict = func(ctx context.Context, entries []cachetypes.Entry[string, keyed[K, V]]) {
+			out := make([]cachetypes.Entry[K, V], len(entries))
+			for i, en := range entries {
+				out[i] = cachetypes.Entry[K, V]{Key: en.Value.key, Value: en.Value.value}
+			}
+			c.evictor.EvictAll(ctx, out)
+		}
+	}
+	c.inner = newCache(o2)
+	return c, nil
+}
+
+// Get retrieves a value from the cache and marks it as recently used. On a
+// miss it calls the loader, if one is set.
+func (c *KeyFuncCache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	e, ok, err := c.inner.Get(ctx, c.keyFn(key))
+	if ok || err != nil || c.loader == nil {
+		return e.value, ok, err
+	}
+	return internal.Load(ctx, key, c.loader, c.Put)
+}
+
+// Has reports whether the key is present without refreshing its recency.
+func (c *KeyFuncCache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	return c.inner.Has(ctx, c.keyFn(key))
+}
+
+// Touch marks the key as recently used without reading its value.
+func (c *KeyFuncCache[K, V]) Touch(ctx context.Context, key K) (bool, error) {
+	return c.inner.Touch(ctx, c.keyFn(key))
+}
+
+// Put inserts or updates a value in the cache. It returns
+// cachetypes.ErrEntryTooLarge if the entry exceeds a configured size limit.
+func (c *KeyFuncCache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
+	return c.inner.Put(ctx, c.keyFn(key), keyed[K, V]{key: key, value: value})
+}
+
+// Replace updates the value of an existing key and returns the previous
+// value. It does nothing if the key is absent.
+func (c *KeyFuncCache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	old, ok, err := c.inner.Replace(ctx, c.keyFn(key), keyed[K, V]{key: key, value: value})
+	return old.value, ok, err
+}
+
+// Delete removes the entry with the specified key from the cache.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *KeyFuncCache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	return c.inner.Delete(ctx, c.keyFn(key))
+}
+
+// GetAndDelete atomically removes the entry and returns its value.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *KeyFuncCache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	e, ok, err := c.inner.GetAndDelete(ctx, c.keyFn(key))
+	return e.value, ok, err
+}
+
+// Size returns the current number of items in the cache.
+func (c *KeyFuncCache[K, V]) Size() (int, error) {
+	return c.inner.Size()
+}
+
+// Capacity returns the maximum number of items the cache can hold.
+func (c *KeyFuncCache[K, V]) Capacity() (int, error) {
+	return c.inner.Capacity()
+}
+
+// Reset clears the cache and calls the eviction callback for each removed item.
+func (c *KeyFuncCache[K, V]) Reset(ctx context.Context) error {
+	return c.inner.Reset(ctx)
+}
+
+// Traverse calls fn for each entry, most recently used first, with the key
+// last stored for it, until fn returns false.
+func (c *KeyFuncCache[K, V]) Traverse(ctx context.Context,
+	fn func(context.Context, K, V) bool) error {
+	return c.inner.Traverse(ctx, func(ctx context.Context, _ string, e keyed[K, V]) bool {
+		return fn(ctx, e.key, e.value)
+	})
+}
+
+// Shutdown clears the cache, calling the eviction callback for each item.
+// Every later operation returns ErrShutdown.
+func (c *KeyFuncCache[K, V]) Shutdown(ctx context.Context) {
+	c.inner.Shutdown(ctx)
+	c.evictor.Close(ctx)
+}
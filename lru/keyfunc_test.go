@@ -0,0 +1,102 @@
+package lru_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal/testhelper"
+	"github.com/mcphone2004/cache/lru"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func newKeyFuncCache[K comparable, T any](capacity uint, evictionCB func(context.Context, K, T)) (iface.Cache[K, T], error) {
+	return lru.NewWithKeyFunc[K, T](func(k K) string { return fmt.Sprint(k) },
+		cachetypes.WithCapacity(capacity),
+		cachetypes.WithEvictionCB(evictionCB),
+	)
+}
+
+func TestKeyFuncCommon(t *testing.T) {
+	testhelper.CommonLRUCacheBasicTest(t, newKeyFuncCache)
+	testhelper.CommonLRUCacheUpdateTest(t, newKeyFuncCache)
+	testhelper.CommonEvictionCallbackTest(t, newKeyFuncCache)
+	testhelper.CommonGetAndDeleteTest(t, newKeyFuncCache)
+	testhelper.CommonReplaceTest(t, newKeyFuncCache)
+	testhelper.CommonShutdownTest(t, newKeyFuncCache)
+}
+
+// request is a key whose equality ignores the trace ID.
+type request struct {
+	user    int
+	traceID string
+}
+
+func TestKeyFunc(t *testing.T) {
+	ctx := context.Background()
+	var evicted []request
+	cache, err := lru.NewWithKeyFunc[request, string](
+		func(r request) string { return strconv.Itoa(r.user) },
+		cachetypes.WithCapacity(2),
+		cachetypes.WithEvictionCB(func(_ context.Context, k request, _ string) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, request{1, "a"}, "one"))
+	// a key differing only in the trace ID finds the same entry
+	v, ok, err := cache.Get(ctx, request{1, "b"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "one", v)
+
+	// an update keeps one entry but records the newer key
+	require.NoError(t, cache.Put(ctx, request{1, "c"}, "uno"))
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Equal(t, 1, size)
+	var keys []request
+	require.NoError(t, cache.Traverse(ctx, func(_ context.Context, k request, _ string) bool {
+		keys = append(keys, k)
+		return true
+	}))
+	require.Equal(t, []request{{1, "c"}}, keys)
+
+	require.NoError(t, cache.Put(ctx, request{2, "d"}, "two"))
+	require.NoError(t, cache.Put(ctx, request{3, "e"}, "three"))
+	require.Equal(t, []request{{1, "c"}}, evicted)
+
+	_, err = lru.NewWithKeyFunc[request, string](nil, cachetypes.WithCapacity(2))
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "keyFn cannot be nil", aerr.Error())
+}
+
+func TestKeyFuncLoader(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.NewWithKeyFunc[request, string](
+		func(r request) string { return strconv.Itoa(r.user) },
+		cachetypes.WithCapacity(2),
+		cachetypes.WithLoader(func(_ context.Context, k request) (string, bool, error) {
+			return k.traceID, true, nil
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	v, ok, err := cache.Get(ctx, request{1, "a"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "a", v)
+	// the loaded value is cached under the normalized key
+	v, ok, err = cache.Get(ctx, request{1, "b"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "a", v)
+}
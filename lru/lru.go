@@ -3,7 +3,11 @@ package lru
 
 import (
 	"context"
+	"iter"
+	"maps"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mcphone2004/cache/iface"
 	"github.com/mcphone2004/cache/internal"
@@ -11,16 +15,43 @@ import (
 )
 
 // Cache is a thread-safe LRU cache.
+//
+// All operations, including Get, are serialized on a single mutex: a Get
+// moves the entry to the front of the recency list, so it mutates shared
+// state and cannot run under a read lock. Keeping one lock makes the cache
+// simple and cheapest when uncontended. lru2 guards the map with an RWMutex
+// and the queue with a separate mutex, which lets lookups overlap but costs
+// two lock acquisitions per Get; it only pays off when many cores contend.
+// Compare the two with BenchmarkLRUReadHeavy in benchmark/lru, or spread the
+// load across shards with the shard package.
 type Cache[K comparable, V any] struct {
 	mu         sync.Mutex
-	isShutdown bool
+	isShutdown atomic.Bool // set under mu; Size reads it without
 	items      map[K]*internal.ListEntry[K, V]
 	queue      *internal.List[K, V]
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+	codec     *cachetypes.ValueCodec[V]
+	admission *internal.Admission[K] // nil unless WithAdmissionPolicy is set
+	loader    cachetypes.LoaderFunc[K, V]
+	// highWater is the largest size reached; it is written under mu but read
+	// without it by HighWaterMark.
+	highWater atomic.Int64
+	// opts is kept so that Clone can build a cache with the same options.
+	opts internal.Options[K, V]
+	// quit is closed by Shutdown to stop Drain goroutines, which drainWG
+	// tracks.
+	quit    chan struct{}
+	drainWG sync.WaitGroup
 }
 
 // Ensure Cache implements the Cache interface.
 var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
 
+// Ensure Cache implements the Toucher interface.
+var _ iface.Toucher[string] = (*Cache[string, int])(nil)
+
 // New creates a new LRU cache with the given capacity.
 func New[K comparable, V any](options ...func(o *cachetypes.Options)) (
 	*Cache[K, V], error) {
@@ -29,40 +60,173 @@ func New[K comparable, V any](options ...func(o *cachetypes.Options)) (
 		cb(&o)
 	}
 
-	o1, err := internal.ToOptions[K, V](o)
+	o1, err := internal.ToLRUOptions[K, V](o)
 	if err != nil {
 		return nil, err
 	}
+	return newCache(o1), nil
+}
 
+// newCache creates a cache from validated options.
+func newCache[K comparable, V any](o1 internal.Options[K, V]) *Cache[K, V] {
+	evictor := internal.NewEvictor(o1)
 	c := &Cache[K, V]{
-		items: make(map[K]*internal.ListEntry[K, V], o1.Capacity),
-		queue: internal.NewList(o1.Capacity, o1.OnEvict),
+		items:     make(map[K]*internal.ListEntry[K, V], o1.Capacity),
+		queue:     internal.NewList(o1.Capacity, evictor.Callback()),
+		evictor:   evictor,
+		sizeLimit: o1.SizeLimit,
+		codec:     o1.ValueCodec,
+		loader:    o1.Loader,
+		opts:      o1,
+		quit:      make(chan struct{}),
+	}
+	if o1.AdmissionPolicy {
+		c.admission = internal.NewAdmission[K](o1.Capacity)
 	}
-	return c, nil
+	c.queue.SetBatchEvict(evictor.BatchCallback())
+	c.queue.SetPanicHandler(o1.PanicHandler)
+	c.queue.SetEntryPoolLimit(o1.EntryPoolLimit)
+	return c
+}
+
+// DroppedEvictions returns how many evicted entries were not delivered
+// because the eviction channel was full.
+func (c *Cache[K, V]) DroppedEvictions() uint64 {
+	return c.evictor.Dropped()
 }
 
 // Get retrieves a value from the cache and marks it as recently used.
-func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+// With WithLoader, a miss is filled from the loader.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	var zero V
-	if c.isShutdown {
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
 		return zero, false, cachetypes.ErrShutdown
 	}
+	c.admission.Record(key)
 	if elem, ok := c.items[key]; ok {
 		c.queue.MoveToFront(elem)
-		return elem.Value.Value, true, nil
+		if m := elem.Value.Meta; m != nil {
+			m.Hits++
+		}
+		v := elem.Value.Value
+		c.mu.Unlock()
+		return v, true, nil
+	}
+	c.mu.Unlock()
+	if c.loader == nil {
+		return zero, false, nil
+	}
+	return internal.Load(ctx, key, c.loader, c.Put)
+}
+
+// GetWithRank is like Get but also returns the entry's 0-based position from
+// the most recently used end before the lookup moved it there, so 0 means the
+// key was already the most recent. A miss returns rank -1 and does not call
+// the loader. Finding the rank walks the list from the front under the lock,
+// which is O(n): use it for diagnostics such as ranking prefetch candidates,
+// not on hot paths.
+func (c *Cache[K, V]) GetWithRank(_ context.Context, key K) (V, int, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	if c.isShutdown.Load() {
+		return zero, -1, false, cachetypes.ErrShutdown
+	}
+	c.admission.Record(key)
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, -1, false, nil
+	}
+	rank := 0
+	for e := range c.queue.Seq() {
+		if e == elem {
+			break
+		}
+		rank++
+	}
+	c.queue.MoveToFront(elem)
+	if m := elem.Value.Meta; m != nil {
+		m.Hits++
+	}
+	return elem.Value.Value, rank, true, nil
+}
+
+// Has reports whether the key is present without changing its recency.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown.Load() {
+		return false, cachetypes.ErrShutdown
 	}
-	return zero, false, nil
+	_, ok := c.items[key]
+	return ok, nil
 }
 
-// Put inserts or updates a value in the cache.
+// Touch marks the key as recently used without returning its value.
+// It returns true if the key was present.
+func (c *Cache[K, V]) Touch(_ context.Context, key K) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown.Load() {
+		return false, cachetypes.ErrShutdown
+	}
+	c.admission.Record(key)
+	elem, ok := c.items[key]
+	if !ok {
+		return false, nil
+	}
+	c.queue.MoveToFront(elem)
+	return true, nil
+}
+
+// Oldest returns the least recently used entry without changing its position.
+// It returns false if the cache is empty.
+func (c *Cache[K, V]) Oldest(_ context.Context) (K, V, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peekEntry(c.queue.Back())
+}
+
+// Newest returns the most recently used entry without changing its position.
+// It returns false if the cache is empty.
+func (c *Cache[K, V]) Newest(_ context.Context) (K, V, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peekEntry(c.queue.Front())
+}
+
+// peekEntry returns the key and value held by elem.
+// It must be called with the mutex held.
+func (c *Cache[K, V]) peekEntry(elem *internal.ListEntry[K, V]) (K, V, bool, error) {
+	var (
+		zeroK K
+		zeroV V
+	)
+	if c.isShutdown.Load() {
+		return zeroK, zeroV, false, cachetypes.ErrShutdown
+	}
+	if elem == nil {
+		return zeroK, zeroV, false, nil
+	}
+	return elem.Value.Key, elem.Value.Value, true, nil
+}
+
+// Put inserts or updates a value in the cache. It returns
+// cachetypes.ErrEntryTooLarge, leaving the cache unchanged, if the entry
+// exceeds a configured size limit. With an admission policy, a new key that
+// loses to the least recently used entry is dropped and Put returns nil.
 func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
 	c.mu.Lock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mu.Unlock()
 		return cachetypes.ErrShutdown
 	}
+	c.admission.Record(key)
 	if elem, ok := c.items[key]; ok {
 		c.queue.MoveToFront(elem)
 		elem.Value.Value = value
@@ -71,9 +235,132 @@ func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
 	}
 	var evicted *internal.Entry[K, V]
 	if c.queue.Size() == c.queue.Capacity() {
+		if !c.admit(key) {
+			c.mu.Unlock()
+			return nil
+		}
 		evicted = c.evict()
 	}
-	c.items[key] = c.queue.PushFront(key, value)
+	c.items[key] = c.push(key, value)
+	c.mu.Unlock()
+	if evicted != nil {
+		c.queue.OnEvict(ctx, evicted)
+	}
+	return nil
+}
+
+// PutMulti inserts or updates all entries under a single lock acquisition
+// and evicts once at the end instead of per entry. Entries are applied in
+// order, as if by Put, and then the least recently used entries beyond the
+// capacity are removed; when more than capacity entries are given, the cache
+// therefore ends up holding the last capacity of them, in order. The
+// eviction callback runs for every removed entry, including ones inserted by
+// this call, after the lock is released. The sequence is consumed before the
+// lock is taken, so it may read from the cache. If any entry exceeds a
+// configured size limit, PutMulti returns cachetypes.ErrEntryTooLarge and
+// leaves the cache unchanged. The admission policy is not applied.
+func (c *Cache[K, V]) PutMulti(ctx context.Context, entries iter.Seq2[K, V]) error {
+	var batch []cachetypes.Entry[K, V]
+	for k, v := range entries {
+		if err := c.sizeLimit.Check(k, v); err != nil {
+			return err
+		}
+		batch = append(batch, cachetypes.Entry[K, V]{Key: k, Value: v})
+	}
+	c.mu.Lock()
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	for _, en := range batch {
+		c.admission.Record(en.Key)
+		if elem, ok := c.items[en.Key]; ok {
+			c.queue.MoveToFront(elem)
+			elem.Value.Value = en.Value
+			continue
+		}
+		c.items[en.Key] = c.pushEntry(en.Key, en.Value)
+	}
+	var toEvict []*internal.Entry[K, V]
+	for c.queue.Size() > c.queue.Capacity() {
+		toEvict = append(toEvict, c.evict())
+	}
+	c.recordSize()
+	c.mu.Unlock()
+	for _, en := range toEvict {
+		c.queue.OnEvict(ctx, en)
+	}
+	return nil
+}
+
+// Replace updates the value of an existing key, marks it as recently used
+// and returns the previous value. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown.Load() {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	c.queue.MoveToFront(elem)
+	old := elem.Value.Value
+	elem.Value.Value = value
+	return old, true, nil
+}
+
+// Update performs a read-modify-write of key under the cache lock. fn
+// receives the current value and whether the key exists, and returns the
+// new value and whether to keep it. A kept value is stored and marked as
+// recently used, possibly evicting the least recently used entry or, with an
+// admission policy, being dropped in its favour; if fn returns false an
+// existing key is deleted. A kept value exceeding a size limit leaves the
+// cache unchanged and returns cachetypes.ErrEntryTooLarge. The eviction
+// callback runs after the lock is released. fn must not call back into the
+// cache.
+func (c *Cache[K, V]) Update(ctx context.Context, key K,
+	fn func(old V, exists bool) (V, bool)) error {
+	c.mu.Lock()
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	var evicted *internal.Entry[K, V]
+	elem, exists := c.items[key]
+	var old V
+	if exists {
+		old = elem.Value.Value
+	}
+	value, keep := fn(old, exists)
+	c.admission.Record(key)
+	if keep {
+		if err := c.sizeLimit.Check(key, value); err != nil {
+			c.mu.Unlock()
+			return err
+		}
+	}
+	switch {
+	case exists && keep:
+		c.queue.MoveToFront(elem)
+		elem.Value.Value = value
+	case exists:
+		delete(c.items, key)
+		evicted = c.queue.Remove(elem)
+	case keep:
+		if c.queue.Size() == c.queue.Capacity() {
+			if !c.admit(key) {
+				break // dropped by the admission policy
+			}
+			evicted = c.evict()
+		}
+		c.items[key] = c.push(key, value)
+	}
 	c.mu.Unlock()
 	if evicted != nil {
 		c.queue.OnEvict(ctx, evicted)
@@ -81,6 +368,49 @@ func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
 	return nil
 }
 
+// push inserts a new entry at the front of the list and records the size in
+// the high-water mark. It must be called with the mutex held.
+func (c *Cache[K, V]) push(key K, value V) *internal.ListEntry[K, V] {
+	elem := c.pushEntry(key, value)
+	c.recordSize()
+	return elem
+}
+
+// pushEntry inserts a new entry at the front of the queue, with fresh
+// metadata if WithEntryMetadata is set. It must be called with the mutex held.
+func (c *Cache[K, V]) pushEntry(key K, value V) *internal.ListEntry[K, V] {
+	elem := c.queue.PushFront(key, value)
+	if c.opts.EntryMetadata {
+		elem.Value.Meta = &cachetypes.EntryMeta{CreatedAt: time.Now()}
+	}
+	return elem
+}
+
+// recordSize raises the high-water mark to the current size. It must be
+// called with the mutex held.
+func (c *Cache[K, V]) recordSize() {
+	if size := int64(c.queue.Size()); size > c.highWater.Load() {
+		c.highWater.Store(size)
+	}
+}
+
+// HighWaterMark returns the largest number of entries the cache has held
+// since it was created, or since the last Reset with WithResetHighWaterMark.
+// Compared with Capacity it shows whether a cache is oversized or thrashing.
+// It does not take the lock and keeps working after Shutdown.
+func (c *Cache[K, V]) HighWaterMark() int {
+	return int(c.highWater.Load())
+}
+
+// admit reports whether a new key may evict the least recently used entry.
+// It must be called with the mutex held and the cache full.
+func (c *Cache[K, V]) admit(key K) bool {
+	if c.admission == nil {
+		return true
+	}
+	return c.admission.Admit(key, c.queue.Back().Value.Key)
+}
+
 // evict removes the least recently used item from the cache and returns it.
 // It returns nil if there are no items to evict.
 func (c *Cache[K, V]) evict() *internal.Entry[K, V] {
@@ -92,37 +422,171 @@ func (c *Cache[K, V]) evict() *internal.Entry[K, V] {
 	return nil
 }
 
-// Reset clears the cache and calls the eviction callback for each evicted item.
-func (c *Cache[K, V]) Reset(ctx context.Context) error {
+// EvictOldest removes up to n least recently used entries, calling the
+// eviction callback for each, and returns how many were removed. It lets
+// callers shed entries ahead of capacity-driven eviction, e.g. under memory
+// pressure. It returns 0 if the cache is empty or n is not positive. Like
+// capacity evictions, these use the per-entry callback even when a batch
+// callback is set.
+func (c *Cache[K, V]) EvictOldest(ctx context.Context, n int) (int, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.isShutdown {
-		return cachetypes.ErrShutdown
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		return 0, cachetypes.ErrShutdown
 	}
-	c.reset(ctx)
-	return nil
-}
-
-// reset clears the cache and calls the eviction callback for each evicted item.
-// It is called with the mutex held, so it should not be called directly
-// outside of the Cache methods.
-func (c *Cache[K, V]) reset(ctx context.Context) {
-	for {
+	var toEvict []*internal.Entry[K, V]
+	for len(toEvict) < n {
 		en := c.evict()
 		if en == nil {
 			break
 		}
+		toEvict = append(toEvict, en)
+	}
+	c.mu.Unlock()
+	for _, en := range toEvict {
+		c.queue.OnEvict(ctx, en)
+	}
+	return len(toEvict), nil
+}
+
+// DeleteIf removes every entry for which pred returns true, calling the
+// eviction callback for each, and returns how many were removed. It lets
+// callers bulk-invalidate entries, e.g. all keys of one tenant, without
+// knowing every key. pred is called with the lock held, so it must not call
+// back into the cache; the eviction callbacks run after the lock is released.
+// Like Delete, it uses the per-entry callback even when a batch callback is
+// set.
+func (c *Cache[K, V]) DeleteIf(ctx context.Context,
+	pred func(K, V) bool) (int, error) {
+	c.mu.Lock()
+	if c.isShutdown.Load() {
 		c.mu.Unlock()
+		return 0, cachetypes.ErrShutdown
+	}
+	// Collect matches first: removing an element while ranging over the list
+	// would break the iterator's link to the next element.
+	var matched []*internal.ListEntry[K, V]
+	for e := range c.queue.Seq() {
+		if pred(e.Value.Key, e.Value.Value) {
+			matched = append(matched, e)
+		}
+	}
+	toEvict := make([]*internal.Entry[K, V], 0, len(matched))
+	for _, e := range matched {
+		delete(c.items, e.Value.Key)
+		toEvict = append(toEvict, c.queue.Remove(e))
+	}
+	c.mu.Unlock()
+	for _, en := range toEvict {
 		c.queue.OnEvict(ctx, en)
+	}
+	return len(toEvict), nil
+}
+
+// Drain removes every entry, least recently used first, and streams it on the
+// returned channel, e.g. to hand warm entries to a successor process during a
+// rolling restart. Each entry is removed and its eviction callback called
+// before it is sent, so entries put meanwhile are drained as well. The
+// channel is closed once the cache is empty, or early when ctx is done or
+// Shutdown is called; the entry being sent at that point has already been
+// removed and is not delivered. A consumer that stops reading must cancel
+// ctx or shut the cache down to release the draining goroutine.
+func (c *Cache[K, V]) Drain(ctx context.Context) <-chan cachetypes.Entry[K, V] {
+	ch := make(chan cachetypes.Entry[K, V])
+	c.mu.Lock()
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		close(ch)
+		return ch
+	}
+	// Add under the lock so that Shutdown, which sets isShutdown under the
+	// same lock before waiting, cannot miss this goroutine.
+	c.drainWG.Add(1)
+	c.mu.Unlock()
+	go c.drainTo(ctx, ch)
+	return ch
+}
+
+// drainTo implements Drain.
+func (c *Cache[K, V]) drainTo(ctx context.Context, ch chan<- cachetypes.Entry[K, V]) {
+	defer c.drainWG.Done()
+	defer close(ch)
+	for ctx.Err() == nil {
 		c.mu.Lock()
+		if c.isShutdown.Load() {
+			c.mu.Unlock()
+			return
+		}
+		en := c.evict()
+		c.mu.Unlock()
+		if en == nil {
+			return
+		}
+		// copy before OnEvict returns the entry to the pool
+		entry := cachetypes.Entry[K, V]{Key: en.Key, Value: en.Value}
+		c.queue.OnEvict(ctx, en)
+		select {
+		case ch <- entry:
+		case <-ctx.Done():
+			return
+		case <-c.quit:
+			return
+		}
 	}
 }
 
-// Size returns the current number of items in the cache.
-func (c *Cache[K, V]) Size() (int, error) {
+// Reset clears the cache and calls the eviction callback for each evicted item.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	// cleared before the lock is released to run the callbacks, so that
+	// Puts made meanwhile are counted
+	c.resetHighWaterMark()
+	toEvict := c.removeAll()
+	c.mu.Unlock()
+	c.queue.OnEvictAll(ctx, toEvict)
+	return nil
+}
+
+// ResetQuiet clears the cache like Reset but without calling the eviction
+// callback, e.g. when deliberately flushing a cache whose callback persists
+// evicted entries.
+func (c *Cache[K, V]) ResetQuiet(_ context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
+		return cachetypes.ErrShutdown
+	}
+	clear(c.items)
+	c.queue.ReleaseAll(c.queue.RemoveAll())
+	c.resetHighWaterMark()
+	return nil
+}
+
+// resetHighWaterMark clears the high-water mark if WithResetHighWaterMark is
+// set. It must be called with the mutex held.
+func (c *Cache[K, V]) resetHighWaterMark() {
+	if c.opts.ResetHighWaterMark {
+		c.highWater.Store(0)
+	}
+}
+
+// removeAll empties the cache and returns its entries, least recently used
+// first. The caller holds mu and must release it before passing the entries
+// to OnEvictAll, so that a callback panicking with EvictionPanicPropagate
+// does not unwind with the lock in an unknown state.
+func (c *Cache[K, V]) removeAll() []*internal.Entry[K, V] {
+	clear(c.items)
+	return c.queue.RemoveAll()
+}
+
+// Size returns the current number of items in the cache. It does not take the
+// cache lock, so it never waits for writers; the result may already be stale.
+func (c *Cache[K, V]) Size() (int, error) {
+	if c.isShutdown.Load() {
 		return 0, cachetypes.ErrShutdown
 	}
 	return c.queue.Size(), nil
@@ -132,19 +596,72 @@ func (c *Cache[K, V]) Size() (int, error) {
 func (c *Cache[K, V]) Capacity() (int, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		return 0, cachetypes.ErrShutdown
 	}
 	return c.queue.Capacity(), nil
 }
 
+// Resize changes the capacity of the cache. When shrinking, the least
+// recently used entries beyond the new capacity are evicted, calling the
+// eviction callback for each after the lock is released; the callback set
+// with WithResizeCB then runs if the capacity changed. Clone uses the new
+// capacity. It returns an InvalidOptionsError if capacity is 0.
+func (c *Cache[K, V]) Resize(ctx context.Context, capacity uint) error {
+	if capacity == 0 {
+		return &cachetypes.InvalidOptionsError{
+			Message: "capacity must be positive",
+		}
+	}
+	c.mu.Lock()
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	oldCapacity := c.opts.Capacity
+	c.opts.Capacity = capacity
+	c.queue.SetCapacity(capacity)
+	var toEvict []*internal.Entry[K, V]
+	for c.queue.Size() > c.queue.Capacity() {
+		toEvict = append(toEvict, c.evict())
+	}
+	c.mu.Unlock()
+	for _, en := range toEvict {
+		c.queue.OnEvict(ctx, en)
+	}
+	if c.opts.OnResize != nil && oldCapacity != capacity {
+		c.opts.OnResize(oldCapacity, capacity)
+	}
+	return nil
+}
+
+// Compact releases memory retained from a peak in size, e.g. after Resize or
+// EvictOldest shrank the cache: Go maps never shrink, so the key index is
+// rebuilt at the current size, and with WithEntryPoolLimit idle pooled
+// entries beyond those needed to refill the cache are dropped. The default
+// pool needs no trimming as the garbage collector empties it. Compact copies
+// every key under the lock, so it is meant to be called occasionally, e.g.
+// from a memory-pressure handler, not on every shrink.
+func (c *Cache[K, V]) Compact() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown.Load() {
+		return cachetypes.ErrShutdown
+	}
+	items := make(map[K]*internal.ListEntry[K, V], len(c.items))
+	maps.Copy(items, c.items)
+	c.items = items
+	c.queue.Compact()
+	return nil
+}
+
 // Traverse iterates over all items in the cache, calling the provided function
 // for each key-value pair. If the function returns false, the iteration stops.
 // The snapshot is taken under the lock; fn is called without holding the lock.
 func (c *Cache[K, V]) Traverse(ctx context.Context,
 	fn func(context.Context, K, V) bool) error {
 	c.mu.Lock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mu.Unlock()
 		return cachetypes.ErrShutdown
 	}
@@ -170,35 +687,108 @@ func (c *Cache[K, V]) Traverse(ctx context.Context,
 	return nil
 }
 
+// TraverseMeta is like Traverse but also passes each entry's metadata. The
+// metadata is the zero EntryMeta unless the cache was created with
+// WithEntryMetadata. Entries copied by Clone or Restore start with fresh
+// metadata.
+func (c *Cache[K, V]) TraverseMeta(ctx context.Context,
+	fn func(context.Context, K, V, cachetypes.EntryMeta) bool) error {
+	type item struct {
+		k K
+		v V
+		m cachetypes.EntryMeta
+	}
+	c.mu.Lock()
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	items := make([]item, 0, c.queue.Size())
+	for e := range c.queue.Seq() {
+		it := item{k: e.Value.Key, v: e.Value.Value}
+		if e.Value.Meta != nil {
+			it.m = *e.Value.Meta
+		}
+		items = append(items, it)
+	}
+	c.mu.Unlock()
+	for _, it := range items {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !fn(ctx, it.k, it.v, it.m) {
+			break
+		}
+	}
+	return nil
+}
+
+// All returns an iterator over the cache's entries from most to least
+// recently used, for use with range:
+//
+//	for k, v := range cache.All(ctx) { ... }
+//
+// Like Traverse, the entries are copied from queue.Seq under the lock and the
+// loop body runs without it, so the body may call back into the cache. The
+// sequence is empty once the cache is shut down, and it stops early when ctx
+// is canceled; use Traverse to observe those errors.
+func (c *Cache[K, V]) All(ctx context.Context) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		_ = c.Traverse(ctx, func(_ context.Context, k K, v V) bool {
+			return yield(k, v)
+		})
+	}
+}
+
 // Delete removes the entry with the specified key from the cache.
 // If the entry exists and is removed, it triggers the onEvict callback.
 func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete removes the entry with the specified key from the cache and
+// returns its value. Lookup and removal happen under a single lock, so two
+// concurrent callers can never both receive the same entry.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	var zero V
 	c.mu.Lock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mu.Unlock()
-		return false, cachetypes.ErrShutdown
+		return zero, false, cachetypes.ErrShutdown
 	}
 	elem, ok := c.items[key]
 	if !ok {
 		c.mu.Unlock()
-		return false, nil
+		return zero, false, nil
 	}
 	delete(c.items, key)
 	evicted := c.queue.Remove(elem)
-	c.mu.Unlock() // Unlock before callback to avoid deadlock
+	value := evicted.Value // OnEvict clears the entry before returning it to the pool
+	c.mu.Unlock()          // Unlock before callback to avoid deadlock
 	c.queue.OnEvict(ctx, evicted)
-	return true, nil
+	return value, true, nil
 }
 
 // Shutdown cleans up the cache, releasing any resources it holds.
 func (c *Cache[K, V]) Shutdown(ctx context.Context) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
 		return
 	}
-	c.isShutdown = true
-	c.reset(ctx) // Clear the cache and call eviction callbacks
+	c.isShutdown.Store(true)
+	close(c.quit)
+	toEvict := c.removeAll()
 	c.items = nil
 	c.queue.Destroy()
+	c.mu.Unlock()
+	// stop the drain goroutines and the async worker even if a callback
+	// panics with EvictionPanicPropagate
+	defer func() {
+		c.drainWG.Wait()
+		c.evictor.Close(ctx)
+	}()
+	c.queue.OnEvictAll(ctx, toEvict)
 }
@@ -3,8 +3,14 @@ package lru_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 
@@ -41,6 +47,16 @@ func newCache[K comparable, T any](capacity uint, evictionCB func(context.Contex
 	)
 }
 
+func TestLoader(t *testing.T) {
+	testhelper.CommonLoaderTest(t, func(loader cachetypes.LoaderFunc[int, string]) (
+		iface.Cache[int, string], error) {
+		return lru.New[int, string](
+			cachetypes.WithCapacity(10),
+			cachetypes.WithLoader(loader),
+		)
+	})
+}
+
 func TestReset(t *testing.T) {
 	testhelper.CommonLRUResetTest(t, newCache)
 }
@@ -73,6 +89,19 @@ func TestGetMultiIter(t *testing.T) {
 	testhelper.CommonGetMultiIterTest(t, newCache)
 }
 
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache)
+	testhelper.CommonHasNoPromoteTest(t, newCache)
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache)
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache)
+}
+
 func TestShutdown(t *testing.T) {
 	testhelper.CommonShutdownTest(t, newCache)
 }
@@ -89,10 +118,22 @@ func TestEvictionCallbackPanic(t *testing.T) {
 	testhelper.CommonEvictionCallbackPanicTest(t, newCache)
 }
 
+func TestEvictionCallback(t *testing.T) {
+	testhelper.CommonEvictionCallbackTest(t, newCache)
+}
+
 func TestConcurrent(t *testing.T) {
 	testhelper.CommonConcurrentTest(t, newCache)
 }
 
+func TestConcurrentStress(t *testing.T) {
+	testhelper.CommonConcurrentStressTest(t, newCache)
+}
+
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache, testhelper.ExactCapacity)
+}
+
 func TestTraverseCancel(t *testing.T) {
 	testhelper.CommonTraverseCancelTest(t, newCache)
 }
@@ -100,3 +141,1028 @@ func TestTraverseCancel(t *testing.T) {
 func TestStressShutdown(t *testing.T) {
 	testhelper.CommonStressShutdownTest(t, newCache[int, string])
 }
+
+func TestOldestNewest(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](cachetypes.WithCapacity(3))
+	require.NoError(t, err)
+
+	_, _, ok, err := cache.Oldest(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, _, ok, err = cache.Newest(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.NoError(t, cache.Put(ctx, 2, "two"))
+	require.NoError(t, cache.Put(ctx, 3, "three"))
+
+	k, v, ok, err := cache.Oldest(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, k)
+	require.Equal(t, "one", v)
+
+	k, v, ok, err = cache.Newest(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 3, k)
+	require.Equal(t, "three", v)
+
+	// Oldest must not refresh recency: 1 is still evicted next
+	require.NoError(t, cache.Put(ctx, 4, "four"))
+	_, ok, err = cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	cache.Shutdown(ctx)
+	_, _, _, err = cache.Oldest(ctx)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	_, _, _, err = cache.Newest(ctx)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+func TestGetWithRank(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](cachetypes.WithCapacity(3))
+	require.NoError(t, err)
+
+	_, rank, ok, err := cache.GetWithRank(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, -1, rank)
+
+	for k := 1; k <= 3; k++ {
+		require.NoError(t, cache.Put(ctx, k, strconv.Itoa(k)))
+	}
+	// 1 is the LRU entry, two behind the newest
+	v, rank, ok, err := cache.GetWithRank(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "1", v)
+	require.Equal(t, 2, rank)
+
+	// the lookup moved 1 to the front
+	_, rank, _, err = cache.GetWithRank(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 0, rank)
+	_, rank, _, err = cache.GetWithRank(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, rank)
+
+	cache.Shutdown(ctx)
+	_, _, _, err = cache.GetWithRank(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+func TestTouch(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+
+	found, err := cache.Touch(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.NoError(t, cache.Put(ctx, 2, "two"))
+
+	// Touch 1 so that 2 becomes the LRU entry
+	found, err = cache.Touch(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.NoError(t, cache.Put(ctx, 3, "three"))
+	_, ok, err := cache.Get(ctx, 2)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, ok, err = cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	cache.Shutdown(ctx)
+	_, err = cache.Touch(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+// TestPutAfterShutdown guards against Put silently dropping writes on a
+// shut-down cache instead of reporting the condition.
+func TestPutAfterShutdown(t *testing.T) {
+	ctx := context.Background()
+	evicted := 0
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(2),
+		cachetypes.WithEvictionCB(func(_ context.Context, _ int, _ string) {
+			evicted++
+		}),
+	)
+	require.NoError(t, err)
+	cache.Shutdown(ctx)
+
+	err = cache.Put(ctx, 1, "one")
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	var sErr *cachetypes.ShutdownError
+	require.ErrorAs(t, err, &sErr)
+	require.Zero(t, evicted)
+}
+
+func TestEvictionChannel(t *testing.T) {
+	ctx := context.Background()
+	ch := make(chan cachetypes.Entry[int, string], 1)
+	evicted := 0
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(1),
+		cachetypes.WithEvictionCB(func(_ context.Context, _ int, _ string) {
+			evicted++
+		}),
+		cachetypes.WithEvictionChannel[int, string](ch),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.NoError(t, cache.Put(ctx, 2, "two"))   // evicts 1
+	require.NoError(t, cache.Put(ctx, 3, "three")) // evicts 2, channel is full
+
+	require.Equal(t, 2, evicted)
+	require.Equal(t, uint64(1), cache.DroppedEvictions())
+	require.Equal(t, cachetypes.Entry[int, string]{Key: 1, Value: "one"}, <-ch)
+}
+
+func TestAsyncEvictionCB(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	var evicted []int
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(1),
+		cachetypes.WithAsyncEvictionCB(func(_ context.Context, k int, _ string) {
+			<-release
+			evicted = append(evicted, k)
+		}, 4),
+	)
+	require.NoError(t, err)
+
+	// Put does not wait for the blocked callback
+	for i := range 4 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	close(release)
+
+	// Shutdown drains pending callbacks, including the final entry
+	cache.Shutdown(ctx)
+	require.Equal(t, []int{0, 1, 2, 3}, evicted)
+}
+
+// TestAsyncEvictionCBReentrant checks that an async callback may evict from
+// and shut down its own cache without deadlocking on the worker
+func TestAsyncEvictionCBReentrant(t *testing.T) {
+	ctx := context.Background()
+	done := make(chan struct{})
+	var evicted []int
+	var cache *lru.Cache[int, string]
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(1),
+		cachetypes.WithAsyncEvictionCB(func(ctx context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+			switch k {
+			case 0:
+				// evicts 1, whose callback runs inline on the worker
+				assert.NoError(t, cache.Put(ctx, 2, "v"))
+			case 1:
+				cache.Shutdown(ctx)
+				close(done)
+			}
+		}, 0),
+	)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 0, "v"))
+	require.NoError(t, cache.Put(ctx, 1, "v"))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("async callback deadlocked")
+	}
+	cache.Shutdown(ctx) // waits for the worker to exit
+	require.Equal(t, []int{0, 1, 2}, evicted)
+}
+
+func TestBatchEvictionCB(t *testing.T) {
+	ctx := context.Background()
+	evicted := 0
+	var batches [][]cachetypes.Entry[int, string]
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(_ context.Context, _ int, _ string) {
+			evicted++
+		}),
+		cachetypes.WithBatchEvictionCB(func(_ context.Context, es []cachetypes.Entry[int, string]) {
+			batches = append(batches, es)
+		}, 2),
+	)
+	require.NoError(t, err)
+	for i := range 5 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	// a capacity eviction still uses the per-entry callback
+	require.Equal(t, 1, evicted)
+
+	require.NoError(t, cache.Reset(ctx))
+	require.Equal(t, 1, evicted)
+	require.Equal(t, [][]cachetypes.Entry[int, string]{
+		{{Key: 1, Value: "v"}, {Key: 2, Value: "v"}},
+		{{Key: 3, Value: "v"}, {Key: 4, Value: "v"}},
+	}, batches)
+
+	require.NoError(t, cache.Put(ctx, 5, "v"))
+	cache.Shutdown(ctx)
+	require.Len(t, batches, 3)
+	require.Equal(t, []cachetypes.Entry[int, string]{{Key: 5, Value: "v"}}, batches[2])
+}
+
+func TestPanicHandler(t *testing.T) {
+	ctx := context.Background()
+	var recovered []any
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(1),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			panic(k)
+		}),
+		cachetypes.WithPanicHandler(func(r any) {
+			recovered = append(recovered, r)
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.NoError(t, cache.Put(ctx, 2, "two"))
+	cache.Shutdown(ctx)
+	require.Equal(t, []any{1, 2}, recovered)
+}
+
+func TestEvictionPanicPolicyPropagate(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(1),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			if k == 1 {
+				panic("callback bug")
+			}
+		}),
+		cachetypes.WithEvictionPanicPolicy(cachetypes.EvictionPanicPropagate),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.PanicsWithValue(t, "callback bug", func() {
+		_ = cache.Put(ctx, 2, "two")
+	})
+	// the lock was released before the callback ran
+	v, found, err := cache.Get(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "two", v)
+}
+
+func TestEvictionPanicPropagateResetShutdown(t *testing.T) {
+	testhelper.CommonEvictionPanicPropagateTest(t, func(capacity uint,
+		evictionCB func(context.Context, int, string)) (iface.Cache[int, string], error) {
+		return lru.New[int, string](
+			cachetypes.WithCapacity(capacity),
+			cachetypes.WithEvictionCB(evictionCB),
+			cachetypes.WithEvictionPanicPolicy(cachetypes.EvictionPanicPropagate),
+		)
+	})
+}
+
+// TestEvictionPanicPropagateStopsDrain checks that a Shutdown interrupted by a
+// panicking callback still stops a Drain blocked on its unread channel.
+func TestEvictionPanicPropagateStopsDrain(t *testing.T) {
+	ignore := goleak.IgnoreCurrent()
+	ctx := context.Background()
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			if k == 1 {
+				panic("callback bug")
+			}
+		}),
+		cachetypes.WithEvictionPanicPolicy(cachetypes.EvictionPanicPropagate),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Put(ctx, 2, "two"))
+	require.NoError(t, cache.Put(ctx, 3, "three"))
+	_ = cache.Drain(ctx) // never read, so the drain goroutine blocks
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.PanicsWithValue(t, "callback bug", func() { cache.Shutdown(ctx) })
+	goleak.VerifyNone(t, ignore)
+}
+
+func TestCompact(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(100),
+		cachetypes.WithEntryPoolLimit(100),
+	)
+	require.NoError(t, err)
+
+	for k := range 100 {
+		require.NoError(t, cache.Put(ctx, k, strconv.Itoa(k)))
+	}
+	n, err := cache.EvictOldest(ctx, 90)
+	require.NoError(t, err)
+	require.Equal(t, 90, n)
+	require.NoError(t, cache.Resize(ctx, 20))
+	require.NoError(t, cache.Compact())
+
+	// the surviving entries and their order are untouched
+	var keys []int
+	require.NoError(t, cache.Traverse(ctx, func(_ context.Context, k int, _ string) bool {
+		keys = append(keys, k)
+		return true
+	}))
+	require.Equal(t, []int{99, 98, 97, 96, 95, 94, 93, 92, 91, 90}, keys)
+	for k := 100; k < 120; k++ {
+		require.NoError(t, cache.Put(ctx, k, strconv.Itoa(k)))
+	}
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Equal(t, 20, size)
+	_, ok, err := cache.Get(ctx, 119)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	cache.Shutdown(ctx)
+	require.ErrorIs(t, cache.Compact(), cachetypes.ErrShutdown)
+}
+
+func TestResize(t *testing.T) {
+	ctx := context.Background()
+	var events []string
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			events = append(events, "evict "+strconv.Itoa(k))
+		}),
+		cachetypes.WithResizeCB(func(oldCapacity, newCapacity uint) {
+			events = append(events, fmt.Sprintf("resize %d->%d", oldCapacity, newCapacity))
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for k := 1; k <= 4; k++ {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	_, _, err = cache.Get(ctx, 1)
+	require.NoError(t, err)
+
+	// the callback fires after the least recently used entries are evicted
+	require.NoError(t, cache.Resize(ctx, 2))
+	require.Equal(t, []string{"evict 2", "evict 3", "resize 4->2"}, events)
+	capacity, err := cache.Capacity()
+	require.NoError(t, err)
+	require.Equal(t, 2, capacity)
+	clone, err := cache.Clone(ctx)
+	require.NoError(t, err)
+	defer clone.Shutdown(ctx)
+	capacity, err = clone.Capacity()
+	require.NoError(t, err)
+	require.Equal(t, 2, capacity)
+
+	// growing evicts nothing; an unchanged capacity is not reported
+	events = nil
+	require.NoError(t, cache.Resize(ctx, 3))
+	require.NoError(t, cache.Resize(ctx, 3))
+	require.NoError(t, cache.Put(ctx, 5, "v"))
+	require.Equal(t, []string{"resize 2->3"}, events)
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Equal(t, 3, size)
+
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, cache.Resize(ctx, 0), &aerr)
+	cache.Shutdown(ctx)
+	require.ErrorIs(t, cache.Resize(ctx, 1), cachetypes.ErrShutdown)
+}
+
+func TestDrain(t *testing.T) {
+	ctx := context.Background()
+	var evicted []int
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for k := 1; k <= 4; k++ {
+		require.NoError(t, cache.Put(ctx, k, strconv.Itoa(k)))
+	}
+	_, _, err = cache.Get(ctx, 1)
+	require.NoError(t, err)
+
+	var drained []cachetypes.Entry[int, string]
+	for en := range cache.Drain(ctx) {
+		drained = append(drained, en)
+	}
+	require.Equal(t, []cachetypes.Entry[int, string]{
+		{Key: 2, Value: "2"}, {Key: 3, Value: "3"}, {Key: 4, Value: "4"}, {Key: 1, Value: "1"},
+	}, drained)
+	require.Equal(t, []int{2, 3, 4, 1}, evicted)
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+}
+
+func TestDrainInterrupted(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	for k := 1; k <= 4; k++ {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+
+	// cancelling ctx closes the channel and leaves the rest in the cache
+	cctx, cancel := context.WithCancel(ctx)
+	ch := cache.Drain(cctx)
+	require.Equal(t, 1, (<-ch).Key)
+	cancel()
+	for range ch {
+	}
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, size, 2)
+
+	// Shutdown interrupts a drain whose consumer stopped reading
+	ch = cache.Drain(ctx)
+	<-ch
+	cache.Shutdown(ctx)
+	for range ch {
+	}
+
+	_, ok := <-cache.Drain(ctx)
+	require.False(t, ok)
+}
+
+func TestTraverseMeta(t *testing.T) {
+	ctx := context.Background()
+	before := time.Now()
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEntryMetadata(),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.NoError(t, cache.Put(ctx, 2, "two"))
+	for _, k := range []int{1, 1, 2, 3} {
+		_, _, err := cache.Get(ctx, k)
+		require.NoError(t, err)
+	}
+	var created time.Time
+	require.NoError(t, cache.TraverseMeta(ctx, func(_ context.Context, k int, _ string, m cachetypes.EntryMeta) bool {
+		if k == 1 {
+			created = m.CreatedAt
+		}
+		return true
+	}))
+	// updating a value keeps the insertion time and hit count
+	require.NoError(t, cache.Put(ctx, 1, "uno"))
+	after := time.Now()
+
+	metas := map[int]cachetypes.EntryMeta{}
+	require.NoError(t, cache.TraverseMeta(ctx, func(_ context.Context, k int, _ string, m cachetypes.EntryMeta) bool {
+		metas[k] = m
+		return true
+	}))
+	require.Len(t, metas, 2)
+	require.Equal(t, uint64(2), metas[1].Hits)
+	require.Equal(t, uint64(1), metas[2].Hits)
+	require.Equal(t, created, metas[1].CreatedAt)
+	for _, m := range metas {
+		require.False(t, m.CreatedAt.Before(before))
+		require.False(t, m.CreatedAt.After(after))
+	}
+}
+
+func TestTraverseMetaDisabled(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	_, _, err = cache.Get(ctx, 1)
+	require.NoError(t, err)
+	var seen int
+	require.NoError(t, cache.TraverseMeta(ctx, func(_ context.Context, _ int, v string, m cachetypes.EntryMeta) bool {
+		seen++
+		require.Equal(t, "one", v)
+		require.Zero(t, m)
+		return true
+	}))
+	require.Equal(t, 1, seen)
+}
+
+func TestTraverseCancelledContext(t *testing.T) {
+	cache, err := lru.New[int, string](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	defer cache.Shutdown(context.Background())
+	require.NoError(t, cache.Put(context.Background(), 1, "one"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = cache.Traverse(ctx, func(context.Context, int, string) bool {
+		t.Fatal("callback must not run with a cancelled context")
+		return true
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestEntryPoolLimit(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(1000),
+		cachetypes.WithEntryPoolLimit(8),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for i := range 1000 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	require.NoError(t, cache.Reset(ctx))
+	for i := range 10 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Equal(t, 10, size)
+}
+
+func TestHighWaterMark(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](cachetypes.WithCapacity(5))
+	require.NoError(t, err)
+	require.Zero(t, cache.HighWaterMark())
+
+	for i := range 3 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	require.Equal(t, 3, cache.HighWaterMark())
+
+	// deleting does not lower the mark, and overwriting does not raise it
+	_, err = cache.Delete(ctx, 0)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 1, "w"))
+	require.Equal(t, 3, cache.HighWaterMark())
+
+	// it never exceeds the capacity
+	for i := range 10 {
+		require.NoError(t, cache.Put(ctx, 10+i, "v"))
+	}
+	require.Equal(t, 5, cache.HighWaterMark())
+
+	// by default Reset keeps the mark
+	require.NoError(t, cache.Reset(ctx))
+	require.Equal(t, 5, cache.HighWaterMark())
+	cache.Shutdown(ctx)
+	require.Equal(t, 5, cache.HighWaterMark())
+}
+
+func TestResetHighWaterMark(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(5),
+		cachetypes.WithResetHighWaterMark(),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for i := range 4 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	require.NoError(t, cache.Reset(ctx))
+	require.Zero(t, cache.HighWaterMark())
+
+	require.NoError(t, cache.Update(ctx, 1, func(string, bool) (string, bool) {
+		return "v", true
+	}))
+	require.NoError(t, cache.Put(ctx, 2, "v"))
+	require.Equal(t, 2, cache.HighWaterMark())
+	require.NoError(t, cache.ResetQuiet(ctx))
+	require.Zero(t, cache.HighWaterMark())
+}
+
+func TestAll(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, string](cachetypes.WithCapacity(5))
+	require.NoError(t, err)
+	for i := range 3 {
+		require.NoError(t, cache.Put(ctx, i, strconv.Itoa(i)))
+	}
+
+	var keys []int
+	for k, v := range cache.All(ctx) {
+		require.Equal(t, strconv.Itoa(k), v)
+		keys = append(keys, k)
+		// the lock is not held while the loop body runs
+		_, _, err := cache.Get(ctx, k)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []int{2, 1, 0}, keys)
+
+	keys = keys[:0]
+	for k := range cache.All(ctx) {
+		keys = append(keys, k)
+		break
+	}
+	require.Len(t, keys, 1)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	for range cache.All(cctx) {
+		t.Fatal("canceled context must yield nothing")
+	}
+
+	cache.Shutdown(ctx)
+	for range cache.All(ctx) {
+		t.Fatal("shut down cache must yield nothing")
+	}
+}
+
+func TestPutMulti(t *testing.T) {
+	ctx := context.Background()
+	var evicted []int
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(3),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+		cachetypes.WithMaxValueBytes(5, func(v string) int { return len(v) }),
+	)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 1, "v"))
+	require.NoError(t, cache.Put(ctx, 2, "v"))
+
+	entries := func(keys ...int) iter.Seq2[int, string] {
+		return func(yield func(int, string) bool) {
+			for _, k := range keys {
+				if !yield(k, strconv.Itoa(k)) {
+					return
+				}
+			}
+		}
+	}
+	keys := func() []int {
+		var ks []int
+		for k := range cache.All(ctx) {
+			ks = append(ks, k)
+		}
+		return ks
+	}
+
+	// an update moves 2 to the front; then 1 and the first new keys go
+	require.NoError(t, cache.PutMulti(ctx, entries(2, 10, 11, 12, 13)))
+	require.Equal(t, []int{1, 2, 10}, evicted, "evicted once at the end, oldest first")
+	require.Equal(t, []int{13, 12, 11}, keys(), "the last capacity entries, in order")
+	require.Equal(t, 3, cache.HighWaterMark(), "the batch never counts above capacity")
+
+	// within capacity nothing is evicted
+	evicted = nil
+	require.NoError(t, cache.PutMulti(ctx, entries(11)))
+	require.Empty(t, evicted)
+	require.Equal(t, []int{11, 13, 12}, keys())
+
+	// a too large entry rejects the whole batch
+	err = cache.PutMulti(ctx, func(yield func(int, string) bool) {
+		_ = yield(20, "v") && yield(21, "too large")
+	})
+	require.ErrorIs(t, err, cachetypes.ErrEntryTooLarge)
+	require.Equal(t, []int{11, 13, 12}, keys())
+
+	cache.Shutdown(ctx)
+	require.ErrorIs(t, cache.PutMulti(ctx, entries(1)), cachetypes.ErrShutdown)
+}
+
+func TestDeleteIf(t *testing.T) {
+	ctx := context.Background()
+	evicted := map[int]string{}
+	batches := 0
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(10),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, v string) {
+			evicted[k] = v
+		}),
+		// only Reset and Shutdown use the batch callback
+		cachetypes.WithBatchEvictionCB(func(context.Context, []cachetypes.Entry[int, string]) {
+			batches++
+		}, 0),
+	)
+	require.NoError(t, err)
+
+	for i := range 6 {
+		tenant := "a"
+		if i%2 == 1 {
+			tenant = "b"
+		}
+		require.NoError(t, cache.Put(ctx, i, tenant))
+	}
+
+	// adjacent matches exercise removal while walking the list
+	n, err := cache.DeleteIf(ctx, func(_ int, v string) bool { return v == "b" })
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, map[int]string{1: "b", 3: "b", 5: "b"}, evicted)
+	require.Zero(t, batches)
+	for i := range 6 {
+		ok, err := cache.Has(ctx, i)
+		require.NoError(t, err)
+		require.Equal(t, i%2 == 0, ok, "key %d", i)
+	}
+
+	n, err = cache.DeleteIf(ctx, func(int, string) bool { return false })
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = cache.DeleteIf(ctx, func(int, string) bool { return true })
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+
+	// the cache stays usable after a full DeleteIf
+	require.NoError(t, cache.Put(ctx, 7, "a"))
+	v, ok, err := cache.Get(ctx, 7)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "a", v)
+
+	cache.Shutdown(ctx)
+	_, err = cache.DeleteIf(ctx, func(int, string) bool { return true })
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+func TestEvictOldest(t *testing.T) {
+	ctx := context.Background()
+	var evicted []int
+	batches := 0
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(5),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+		// only Reset and Shutdown use the batch callback
+		cachetypes.WithBatchEvictionCB(func(context.Context, []cachetypes.Entry[int, string]) {
+			batches++
+		}, 0),
+	)
+	require.NoError(t, err)
+
+	n, err := cache.EvictOldest(ctx, 3)
+	require.NoError(t, err)
+	require.Zero(t, n, "empty cache is a no-op")
+
+	for i := range 5 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	_, _, err = cache.Get(ctx, 0) // 0 becomes the most recently used
+	require.NoError(t, err)
+
+	n, err = cache.EvictOldest(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []int{1, 2}, evicted)
+
+	n, err = cache.EvictOldest(ctx, 0)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// asking for more than the cache holds removes what is there
+	n, err = cache.EvictOldest(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []int{1, 2, 3, 4, 0}, evicted)
+	require.Zero(t, batches)
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+
+	cache.Shutdown(ctx)
+	_, err = cache.EvictOldest(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	var evicted []int
+	cache, err := lru.New[int, int](
+		cachetypes.WithCapacity(2),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ int) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+
+	incr := func(old int, _ bool) (int, bool) { return old + 1, true }
+
+	// insert, then update in place
+	require.NoError(t, cache.Update(ctx, 1, incr))
+	require.NoError(t, cache.Update(ctx, 1, incr))
+	v, ok, err := cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 2, v)
+
+	// returning false deletes an existing key and skips an absent one
+	drop := func(int, bool) (int, bool) { return 0, false }
+	require.NoError(t, cache.Update(ctx, 1, drop))
+	require.NoError(t, cache.Update(ctx, 9, drop))
+	require.Equal(t, []int{1}, evicted)
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+
+	// inserting into a full cache evicts the least recently used key
+	require.NoError(t, cache.Put(ctx, 2, 0))
+	require.NoError(t, cache.Put(ctx, 3, 0))
+	require.NoError(t, cache.Update(ctx, 4, incr))
+	require.Equal(t, []int{1, 2}, evicted)
+
+	cache.Shutdown(ctx)
+	require.ErrorIs(t, cache.Update(ctx, 1, incr), cachetypes.ErrShutdown)
+}
+
+func TestUpdateConcurrentCounter(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[string, int](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	const workers, perWorker = 8, 100
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for range perWorker {
+				_ = cache.Update(ctx, "hits", func(old int, _ bool) (int, bool) {
+					return old + 1, true
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	v, _, err := cache.Get(ctx, "hits")
+	require.NoError(t, err)
+	require.Equal(t, workers*perWorker, v)
+}
+
+func TestMaxEntryBytes(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[string, []byte](
+		cachetypes.WithCapacity(2),
+		cachetypes.WithMaxKeyBytes(8, func(k string) int { return len(k) }),
+		cachetypes.WithMaxValueBytes(4, func(v []byte) int { return len(v) }),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, "a", []byte("1234")))
+	require.NoError(t, cache.Put(ctx, "b", []byte("1")))
+
+	// oversized entries are rejected without evicting anything
+	err = cache.Put(ctx, "c", []byte("12345"))
+	require.ErrorIs(t, err, cachetypes.ErrEntryTooLarge)
+	var tErr *cachetypes.EntryTooLargeError
+	require.ErrorAs(t, err, &tErr)
+	var cErr *cachetypes.CapacityExceededError
+	require.ErrorAs(t, err, &cErr)
+	require.Equal(t, 5, cErr.Size)
+	require.Equal(t, 4, cErr.Limit)
+	require.ErrorIs(t, cache.Put(ctx, "a very long key", nil), cachetypes.ErrEntryTooLarge)
+	_, _, err = cache.Replace(ctx, "a", []byte("12345"))
+	require.ErrorIs(t, err, cachetypes.ErrEntryTooLarge)
+	err = cache.Update(ctx, "b", func([]byte, bool) ([]byte, bool) { return []byte("12345"), true })
+	require.ErrorIs(t, err, cachetypes.ErrEntryTooLarge)
+
+	v, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1234"), v)
+	v, ok, err = cache.Get(ctx, "b")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), v)
+}
+
+func TestResetQuiet(t *testing.T) {
+	ctx := context.Background()
+	var evicted int
+	cache, err := lru.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(_ context.Context, _ int, _ string) {
+			evicted++
+		}),
+	)
+	require.NoError(t, err)
+	for i := range 3 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	require.NoError(t, cache.ResetQuiet(ctx))
+	require.Zero(t, evicted)
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+	_, ok, err := cache.Get(ctx, 0)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the cache stays usable and later evictions still call the callback
+	require.NoError(t, cache.Put(ctx, 1, "v"))
+	require.NoError(t, cache.Reset(ctx))
+	require.Equal(t, 1, evicted)
+
+	cache.Shutdown(ctx)
+	require.ErrorIs(t, cache.ResetQuiet(ctx), cachetypes.ErrShutdown)
+}
+
+func TestAdmissionPolicy(t *testing.T) {
+	ctx := context.Background()
+	// hitRate warms up hot keys, then interleaves them with a scan of
+	// one-off keys and reports how many hot reads hit.
+	hitRate := func(opts ...func(*cachetypes.Options)) int {
+		cache, err := lru.New[int, int](append(opts, cachetypes.WithCapacity(10))...)
+		require.NoError(t, err)
+		defer cache.Shutdown(ctx)
+		for range 3 {
+			for k := range 10 {
+				require.NoError(t, cache.Put(ctx, k, k))
+			}
+		}
+		hits := 0
+		for scan := 1000; scan < 1200; scan++ {
+			require.NoError(t, cache.Put(ctx, scan, scan))
+			_, found, err := cache.Get(ctx, scan%10)
+			require.NoError(t, err)
+			if found {
+				hits++
+			}
+		}
+		return hits
+	}
+	require.Less(t, hitRate(), 50)
+	// the sketch may rarely overestimate a scan key and admit it
+	require.Greater(t, hitRate(cachetypes.WithAdmissionPolicy()), 150)
+}
+
+func TestAdmissionPolicyAdmitsFrequentKey(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, int](cachetypes.WithCapacity(2), cachetypes.WithAdmissionPolicy())
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+	require.NoError(t, cache.Put(ctx, 1, 1))
+	require.NoError(t, cache.Put(ctx, 2, 2))
+
+	// a first Put of a new key loses to the victim, but misses raise its
+	// frequency until it wins
+	require.NoError(t, cache.Put(ctx, 3, 3))
+	found, err := cache.Has(ctx, 3)
+	require.NoError(t, err)
+	require.False(t, found)
+	for range 3 {
+		_, _, err = cache.Get(ctx, 3)
+		require.NoError(t, err)
+	}
+	require.NoError(t, cache.Update(ctx, 3, func(int, bool) (int, bool) { return 3, true }))
+	found, err = cache.Has(ctx, 3)
+	require.NoError(t, err)
+	require.True(t, found)
+}
+
+func TestSizeWithoutLock(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru.New[int, int](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 1, 1))
+
+	// Update holds the lock while fn runs; Size must not wait for it
+	var size int
+	require.NoError(t, cache.Update(ctx, 2, func(int, bool) (int, bool) {
+		size, err = cache.Size()
+		return 2, true
+	}))
+	require.NoError(t, err)
+	require.Equal(t, 1, size)
+
+	cache.Shutdown(ctx)
+	_, err = cache.Size()
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
@@ -0,0 +1,160 @@
+package lru
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// encodedEntry is a snapshot entry whose value was encoded by a ValueCodec.
+type encodedEntry[K comparable] struct {
+	Key   K
+	Value []byte
+}
+
+// Snapshot writes all entries to w using encoding/gob, from the least to the
+// most recently used, so that Restore reproduces the recency order. K and V
+// must be gob-encodable (exported struct fields, registered interface types);
+// otherwise a *cachetypes.SnapshotError is returned. With WithValueCodec,
+// values are encoded by the codec instead and only K must be gob-encodable.
+// Snapshot does not change the recency of any entry.
+func (c *Cache[K, V]) Snapshot(ctx context.Context, w io.Writer) error {
+	entries, err := c.entriesOldestFirst(ctx)
+	if err != nil {
+		return err
+	}
+	var payload any = entries
+	if c.codec != nil {
+		encoded := make([]encodedEntry[K], len(entries))
+		for i, en := range entries {
+			b, err := c.codec.Marshal(en.Value)
+			if err != nil {
+				return &cachetypes.SnapshotError{Message: "lru: cannot encode snapshot", Err: err}
+			}
+			encoded[i] = encodedEntry[K]{Key: en.Key, Value: b}
+		}
+		payload = encoded
+	}
+	if err := gob.NewEncoder(w).Encode(payload); err != nil {
+		return &cachetypes.SnapshotError{Message: "lru: cannot encode snapshot", Err: err}
+	}
+	return nil
+}
+
+// Restore reads a snapshot written by Snapshot and puts its entries into the
+// cache in their original recency order. Existing entries are kept unless the
+// snapshot overwrites their keys. When the snapshot holds more entries than
+// the capacity, the least recently used ones are evicted as with Put.
+func (c *Cache[K, V]) Restore(ctx context.Context, r io.Reader) error {
+	entries, err := c.decodeSnapshot(r)
+	if err != nil {
+		return err
+	}
+	for _, en := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := c.Put(ctx, en.Key, en.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clone returns a new cache with the same capacity and options, including the
+// eviction callback, holding the current entries in the same LRU order, e.g.
+// to fork a warm cache for a read replica. Keys are copied but values are
+// shared by reference, so a mutable value changed through one cache is changed
+// in both. The source is not modified and no eviction callbacks are called.
+func (c *Cache[K, V]) Clone(ctx context.Context) (*Cache[K, V], error) {
+	c.mu.Lock()
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		return nil, cachetypes.ErrShutdown
+	}
+	// opts is read under the lock since Resize changes the capacity
+	opts := c.opts
+	entries := c.collectOldestFirst()
+	c.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	clone := newCache(opts)
+	for _, en := range entries {
+		clone.items[en.Key] = clone.push(en.Key, en.Value)
+	}
+	return clone, nil
+}
+
+// decodeSnapshot reads the entries written by Snapshot, decoding values with
+// the ValueCodec when one is configured.
+func (c *Cache[K, V]) decodeSnapshot(r io.Reader) ([]cachetypes.Entry[K, V], error) {
+	if c.codec == nil {
+		var entries []cachetypes.Entry[K, V]
+		if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+			return nil, &cachetypes.SnapshotError{Message: "lru: cannot decode snapshot", Err: err}
+		}
+		return entries, nil
+	}
+	var encoded []encodedEntry[K]
+	if err := gob.NewDecoder(r).Decode(&encoded); err != nil {
+		return nil, &cachetypes.SnapshotError{Message: "lru: cannot decode snapshot", Err: err}
+	}
+	entries := make([]cachetypes.Entry[K, V], len(encoded))
+	for i, en := range encoded {
+		v, err := c.codec.Unmarshal(en.Value)
+		if err != nil {
+			return nil, &cachetypes.SnapshotError{Message: "lru: cannot decode snapshot", Err: err}
+		}
+		entries[i] = cachetypes.Entry[K, V]{Key: en.Key, Value: v}
+	}
+	return entries, nil
+}
+
+// Dump writes one "key: value" line per entry to w, from the least to the
+// most recently used, formatting keys and values with %v so that types
+// implementing fmt.Stringer render through their String method. It is meant
+// for inspecting the cache, e.g. from an admin endpoint. The lock is held
+// only while the entries are copied, and recency is not changed.
+func (c *Cache[K, V]) Dump(ctx context.Context, w io.Writer) error {
+	entries, err := c.entriesOldestFirst(ctx)
+	if err != nil {
+		return err
+	}
+	for _, en := range entries {
+		if _, err := fmt.Fprintf(w, "%v: %v\n", en.Key, en.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entriesOldestFirst copies all entries under the lock, from the least to
+// the most recently used.
+func (c *Cache[K, V]) entriesOldestFirst(ctx context.Context) ([]cachetypes.Entry[K, V], error) {
+	c.mu.Lock()
+	if c.isShutdown.Load() {
+		c.mu.Unlock()
+		return nil, cachetypes.ErrShutdown
+	}
+	entries := c.collectOldestFirst()
+	c.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// collectOldestFirst returns the entries from least to most recently used.
+// The caller holds mu.
+func (c *Cache[K, V]) collectOldestFirst() []cachetypes.Entry[K, V] {
+	entries := make([]cachetypes.Entry[K, V], 0, c.queue.Size())
+	for e := range c.queue.SeqReverse() {
+		entries = append(entries, cachetypes.Entry[K, V]{Key: e.Value.Key, Value: e.Value.Value})
+	}
+	return entries
+}
@@ -0,0 +1,200 @@
+package lru_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcphone2004/cache/lru"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func keysInOrder(t *testing.T, c *lru.Cache[int, string]) []int {
+	t.Helper()
+	var keys []int
+	require.NoError(t, c.Traverse(context.Background(), func(_ context.Context, k int, _ string) bool {
+		keys = append(keys, k)
+		return true
+	}))
+	return keys
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	src, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	defer src.Shutdown(ctx)
+	for i := range 4 {
+		require.NoError(t, src.Put(ctx, i, strings.Repeat("x", i)))
+	}
+	_, _, err = src.Get(ctx, 0) // 0 becomes the most recently used
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, src.Snapshot(ctx, &buf))
+
+	dst, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	defer dst.Shutdown(ctx)
+	require.NoError(t, dst.Restore(ctx, bytes.NewReader(buf.Bytes())))
+	require.Equal(t, keysInOrder(t, src), keysInOrder(t, dst))
+	v, ok, err := dst.Get(ctx, 3)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "xxx", v)
+
+	// a smaller cache keeps the most recently used entries
+	small, err := lru.New[int, string](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	defer small.Shutdown(ctx)
+	require.NoError(t, small.Restore(ctx, bytes.NewReader(buf.Bytes())))
+	require.Equal(t, []int{0, 3}, keysInOrder(t, small))
+}
+
+type unregistered struct{ N int }
+
+func TestSnapshotNotEncodable(t *testing.T) {
+	ctx := context.Background()
+	c, err := lru.New[int, any](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+	// interface values need gob.Register for their concrete type
+	require.NoError(t, c.Put(ctx, 1, unregistered{N: 1}))
+
+	var sErr *cachetypes.SnapshotError
+	require.ErrorAs(t, c.Snapshot(ctx, &bytes.Buffer{}), &sErr)
+}
+
+func TestRestoreInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	c, err := lru.New[int, string](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	var sErr *cachetypes.SnapshotError
+	require.ErrorAs(t, c.Restore(ctx, strings.NewReader("not gob")), &sErr)
+}
+
+func TestSnapshotAfterShutdown(t *testing.T) {
+	ctx := context.Background()
+	c, err := lru.New[int, string](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	c.Shutdown(ctx)
+	require.ErrorIs(t, c.Snapshot(ctx, &bytes.Buffer{}), cachetypes.ErrShutdown)
+}
+
+type point struct{ x, y int }
+
+func (p point) String() string { return fmt.Sprintf("(%d,%d)", p.x, p.y) }
+
+func TestDump(t *testing.T) {
+	ctx := context.Background()
+	c, err := lru.New[string, point](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Put(ctx, "a", point{1, 2}))
+	require.NoError(t, c.Put(ctx, "b", point{3, 4}))
+	require.NoError(t, c.Put(ctx, "c", point{5, 6}))
+	_, _, err = c.Get(ctx, "a")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Dump(ctx, &buf))
+	require.Equal(t, "b: (3,4)\nc: (5,6)\na: (1,2)\n", buf.String())
+
+	// Dump does not change recency: b is still evicted first
+	require.NoError(t, c.Put(ctx, "d", point{}))
+	require.NoError(t, c.Put(ctx, "e", point{}))
+	found, err := c.Has(ctx, "b")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	c.Shutdown(ctx)
+	require.ErrorIs(t, c.Dump(ctx, &buf), cachetypes.ErrShutdown)
+}
+
+func TestSnapshotValueCodec(t *testing.T) {
+	ctx := context.Background()
+	codec := cachetypes.WithValueCodec(
+		func(p point) ([]byte, error) {
+			if p.x < 0 {
+				return nil, errors.New("negative point")
+			}
+			return fmt.Appendf(nil, "%d %d", p.x, p.y), nil
+		},
+		func(b []byte) (point, error) {
+			var p point
+			_, err := fmt.Sscanf(string(b), "%d %d", &p.x, &p.y)
+			return p, err
+		},
+	)
+
+	// point has no exported fields, so gob alone cannot encode it
+	plain, err := lru.New[string, point](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	defer plain.Shutdown(ctx)
+	require.NoError(t, plain.Put(ctx, "a", point{1, 2}))
+	var sErr *cachetypes.SnapshotError
+	require.ErrorAs(t, plain.Snapshot(ctx, &bytes.Buffer{}), &sErr)
+
+	src, err := lru.New[string, point](cachetypes.WithCapacity(2), codec)
+	require.NoError(t, err)
+	defer src.Shutdown(ctx)
+	require.NoError(t, src.Put(ctx, "a", point{1, 2}))
+	require.NoError(t, src.Put(ctx, "b", point{3, 4}))
+	var buf bytes.Buffer
+	require.NoError(t, src.Snapshot(ctx, &buf))
+
+	dst, err := lru.New[string, point](cachetypes.WithCapacity(2), codec)
+	require.NoError(t, err)
+	defer dst.Shutdown(ctx)
+	require.NoError(t, dst.Restore(ctx, &buf))
+	var dump bytes.Buffer
+	require.NoError(t, dst.Dump(ctx, &dump))
+	require.Equal(t, "a: (1,2)\nb: (3,4)\n", dump.String())
+
+	// codec errors are reported as snapshot errors
+	require.NoError(t, src.Put(ctx, "c", point{-1, 0}))
+	require.ErrorAs(t, src.Snapshot(ctx, &bytes.Buffer{}), &sErr)
+	require.ErrorAs(t, dst.Restore(ctx, strings.NewReader("not gob")), &sErr)
+}
+
+func TestClone(t *testing.T) {
+	ctx := context.Background()
+	var evicted []int
+	src, err := lru.New[int, string](
+		cachetypes.WithCapacity(3),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+	for i := range 3 {
+		require.NoError(t, src.Put(ctx, i, fmt.Sprint(i)))
+	}
+	_, _, err = src.Get(ctx, 0)
+	require.NoError(t, err)
+
+	clone, err := src.Clone(ctx)
+	require.NoError(t, err)
+	require.Equal(t, keysInOrder(t, src), keysInOrder(t, clone))
+	capacity, err := clone.Capacity()
+	require.NoError(t, err)
+	require.Equal(t, 3, capacity)
+
+	// the clone is independent and evicts its own least recently used entry
+	require.NoError(t, clone.Put(ctx, 3, "3"))
+	require.Equal(t, []int{3, 0, 2}, keysInOrder(t, clone))
+	require.Equal(t, []int{0, 2, 1}, keysInOrder(t, src))
+	require.Equal(t, []int{1}, evicted, "the clone shares the eviction callback")
+
+	clone.Shutdown(ctx)
+	src.Shutdown(ctx)
+	_, err = src.Clone(ctx)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
@@ -4,6 +4,7 @@ package lru2
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/mcphone2004/cache/iface"
 	"github.com/mcphone2004/cache/internal"
@@ -13,11 +14,15 @@ import (
 // Cache is a thread-safe LRU cache.
 type Cache[K comparable, V any] struct {
 	mapMutex   sync.RWMutex // mutex for map
-	isShutdown bool
+	isShutdown atomic.Bool  // set under mapMutex; Size reads it without
 	items      map[K]*internal.ListEntry[K, V]
 
 	qMutex sync.Mutex // mutex for queue
 	queue  *internal.List[K, V]
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+	loader    cachetypes.LoaderFunc[K, V]
 }
 
 // Ensure Cache implements the Cache interface.
@@ -36,25 +41,42 @@ func New[K comparable, V any](options ...func(o *cachetypes.Options)) (
 		return nil, err
 	}
 
+	evictor := internal.NewEvictor(o1)
 	c := &Cache[K, V]{
-		items: make(map[K]*internal.ListEntry[K, V], o1.Capacity),
-		queue: internal.NewList(o1.Capacity, o1.OnEvict),
+		items:     make(map[K]*internal.ListEntry[K, V], o1.Capacity),
+		queue:     internal.NewList(o1.Capacity, evictor.Callback()),
+		evictor:   evictor,
+		sizeLimit: o1.SizeLimit,
+		loader:    o1.Loader,
 	}
+	c.queue.SetBatchEvict(evictor.BatchCallback())
+	c.queue.SetPanicHandler(o1.PanicHandler)
+	c.queue.SetEntryPoolLimit(o1.EntryPoolLimit)
 	return c, nil
 }
 
+// DroppedEvictions returns how many evicted entries were not delivered
+// because the eviction channel was full.
+func (c *Cache[K, V]) DroppedEvictions() uint64 {
+	return c.evictor.Dropped()
+}
+
 // Get retrieves a value from the cache and marks it as recently used.
-func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+// With WithLoader, a miss is filled from the loader.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
 	var zero V
 	c.mapMutex.RLock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mapMutex.RUnlock()
 		return zero, false, cachetypes.ErrShutdown
 	}
 	elem, ok := c.items[key]
 	if !ok {
 		c.mapMutex.RUnlock()
-		return zero, false, nil
+		if c.loader == nil {
+			return zero, false, nil
+		}
+		return internal.Load(ctx, key, c.loader, c.Put)
 	}
 
 	val := elem.Value.Value
@@ -65,10 +87,27 @@ func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
 	return val, true, nil
 }
 
-// Put inserts or updates a value in the cache.
+// Has reports whether the key is present without changing its recency.
+// It only takes the map read lock, so it never contends with Get.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mapMutex.RLock()
+	defer c.mapMutex.RUnlock()
+	if c.isShutdown.Load() {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.items[key]
+	return ok, nil
+}
+
+// Put inserts or updates a value in the cache. It returns
+// cachetypes.ErrEntryTooLarge, leaving the cache unchanged, if the entry
+// exceeds a configured size limit.
 func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
 	c.mapMutex.Lock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mapMutex.Unlock()
 		return cachetypes.ErrShutdown
 	}
@@ -101,15 +140,39 @@ func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
 	return nil
 }
 
-// Size returns the current number of items in the cache.
+// Replace updates the value of an existing key, marks it as recently used
+// and returns the previous value. It does nothing if the key is absent. The
+// value is swapped under the map write lock.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	c.mapMutex.Lock()
+	if c.isShutdown.Load() {
+		c.mapMutex.Unlock()
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.items[key]
+	if !ok {
+		c.mapMutex.Unlock()
+		return zero, false, nil
+	}
+	old := elem.Value.Value
+	elem.Value.Value = value
+	c.qMutex.Lock()
+	c.mapMutex.Unlock()
+	c.queue.MoveToFront(elem)
+	c.qMutex.Unlock()
+	return old, true, nil
+}
+
+// Size returns the current number of items in the cache. It does not take
+// either lock, so it never waits for writers; the result may already be stale.
 func (c *Cache[K, V]) Size() (int, error) {
-	c.mapMutex.RLock()
-	defer c.mapMutex.RUnlock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		return 0, cachetypes.ErrShutdown
 	}
-	c.qMutex.Lock()
-	defer c.qMutex.Unlock()
 	return c.queue.Size(), nil
 }
 
@@ -117,7 +180,7 @@ func (c *Cache[K, V]) Size() (int, error) {
 func (c *Cache[K, V]) Capacity() (int, error) {
 	c.mapMutex.RLock()
 	defer c.mapMutex.RUnlock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		return 0, cachetypes.ErrShutdown
 	}
 	c.qMutex.Lock()
@@ -131,7 +194,7 @@ func (c *Cache[K, V]) Capacity() (int, error) {
 func (c *Cache[K, V]) Traverse(ctx context.Context,
 	fn func(context.Context, K, V) bool) error {
 	c.mapMutex.RLock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mapMutex.RUnlock()
 		return cachetypes.ErrShutdown
 	}
@@ -162,23 +225,34 @@ func (c *Cache[K, V]) Traverse(ctx context.Context,
 // Delete removes the entry with the specified key from the cache.
 // If the entry exists and is removed, it triggers the onEvict callback.
 func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete removes the entry with the specified key from the cache and
+// returns its value. The map lookup and removal happen under the map write
+// lock, so two concurrent callers can never both receive the same entry.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	var zero V
 	c.mapMutex.Lock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mapMutex.Unlock()
-		return false, cachetypes.ErrShutdown
+		return zero, false, cachetypes.ErrShutdown
 	}
 	elem, ok := c.items[key]
 	if !ok {
 		c.mapMutex.Unlock()
-		return false, nil
+		return zero, false, nil
 	}
 	delete(c.items, key)
 	c.qMutex.Lock()
 	c.mapMutex.Unlock()
 	ent := c.queue.Remove(elem)
 	c.qMutex.Unlock()
+	value := ent.Value // OnEvict clears the entry before returning it to the pool
 	c.queue.OnEvict(ctx, ent)
-	return true, nil
+	return value, true, nil
 }
 
 // drain removes all items from the queue and returns them for eviction callbacks.
@@ -189,40 +263,48 @@ func (c *Cache[K, V]) drain() []*internal.Entry[K, V] {
 	}
 	c.qMutex.Lock()
 	c.mapMutex.Unlock()
-	var toEvict []*internal.Entry[K, V]
-	for {
-		elem := c.queue.Back()
-		if elem == nil {
-			break
-		}
-		toEvict = append(toEvict, c.queue.Remove(elem))
-	}
+	toEvict := c.queue.RemoveAll()
 	c.qMutex.Unlock()
 	return toEvict
 }
 
 // Shutdown cleans up the cache, releasing any resources it holds.
+// It is idempotent: the shutdown flag is checked and set under the map write
+// lock, so only the first of several concurrent calls drains the queue. All
+// other methods return ErrShutdown afterwards.
 func (c *Cache[K, V]) Shutdown(ctx context.Context) {
 	c.mapMutex.Lock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mapMutex.Unlock()
 		return
 	}
-	c.isShutdown = true
-	for _, ent := range c.drain() {
-		c.queue.OnEvict(ctx, ent)
-	}
+	c.isShutdown.Store(true)
+	// stop the async worker even if a callback panics with
+	// EvictionPanicPropagate
+	defer c.evictor.Close(ctx)
+	c.queue.OnEvictAll(ctx, c.drain())
 }
 
 // Reset clears the cache and calls the eviction callback for each evicted item.
 func (c *Cache[K, V]) Reset(ctx context.Context) error {
 	c.mapMutex.Lock()
-	if c.isShutdown {
+	if c.isShutdown.Load() {
 		c.mapMutex.Unlock()
 		return cachetypes.ErrShutdown
 	}
-	for _, ent := range c.drain() {
-		c.queue.OnEvict(ctx, ent)
+	c.queue.OnEvictAll(ctx, c.drain())
+	return nil
+}
+
+// ResetQuiet clears the cache like Reset but without calling the eviction
+// callback, e.g. when deliberately flushing a cache whose callback persists
+// evicted entries.
+func (c *Cache[K, V]) ResetQuiet(_ context.Context) error {
+	c.mapMutex.Lock()
+	if c.isShutdown.Load() {
+		c.mapMutex.Unlock()
+		return cachetypes.ErrShutdown
 	}
+	c.queue.ReleaseAll(c.drain())
 	return nil
 }
@@ -3,7 +3,10 @@ package lru2_test
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
@@ -41,6 +44,16 @@ func newCache[K comparable, T any](capacity uint, evictionCB func(context.Contex
 	)
 }
 
+func TestLoader(t *testing.T) {
+	testhelper.CommonLoaderTest(t, func(loader cachetypes.LoaderFunc[int, string]) (
+		iface.Cache[int, string], error) {
+		return lru2.New[int, string](
+			cachetypes.WithCapacity(10),
+			cachetypes.WithLoader(loader),
+		)
+	})
+}
+
 func TestReset(t *testing.T) {
 	testhelper.CommonLRUResetTest(t, newCache)
 }
@@ -73,6 +86,19 @@ func TestGetMultiIter(t *testing.T) {
 	testhelper.CommonGetMultiIterTest(t, newCache)
 }
 
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache)
+	testhelper.CommonHasNoPromoteTest(t, newCache)
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache)
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache)
+}
+
 func TestShutdown(t *testing.T) {
 	testhelper.CommonShutdownTest(t, newCache)
 }
@@ -89,10 +115,32 @@ func TestEvictionCallbackPanic(t *testing.T) {
 	testhelper.CommonEvictionCallbackPanicTest(t, newCache)
 }
 
+func TestEvictionCallback(t *testing.T) {
+	testhelper.CommonEvictionCallbackTest(t, newCache)
+}
+
+func TestEvictionPanicPropagateResetShutdown(t *testing.T) {
+	testhelper.CommonEvictionPanicPropagateTest(t, func(capacity uint, evictionCB func(context.Context, int, string)) (iface.Cache[int, string], error) {
+		return lru2.New[int, string](
+			cachetypes.WithCapacity(capacity),
+			cachetypes.WithEvictionCB(evictionCB),
+			cachetypes.WithEvictionPanicPolicy(cachetypes.EvictionPanicPropagate),
+		)
+	})
+}
+
 func TestConcurrent(t *testing.T) {
 	testhelper.CommonConcurrentTest(t, newCache)
 }
 
+func TestConcurrentStress(t *testing.T) {
+	testhelper.CommonConcurrentStressTest(t, newCache)
+}
+
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache, testhelper.ExactCapacity)
+}
+
 func TestTraverseCancel(t *testing.T) {
 	testhelper.CommonTraverseCancelTest(t, newCache)
 }
@@ -100,3 +148,130 @@ func TestTraverseCancel(t *testing.T) {
 func TestStressShutdown(t *testing.T) {
 	testhelper.CommonStressShutdownTest(t, newCache[int, string])
 }
+
+// TestPutAfterShutdown guards the shutdown branch of Put, which holds the
+// map write lock and must release it with Unlock rather than RUnlock.
+func TestPutAfterShutdown(t *testing.T) {
+	ctx := context.Background()
+	cache, err := lru2.New[int, string](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	cache.Shutdown(ctx)
+
+	// require must not be called off the test goroutine, so the results
+	// are sent back
+	errs := make(chan error, 2)
+	go func() {
+		errs <- cache.Put(ctx, 1, "one")
+		// a second Put would deadlock if the first left the lock held
+		errs <- cache.Put(ctx, 2, "two")
+	}()
+	for range 2 {
+		select {
+		case err := <-errs:
+			require.ErrorIs(t, err, cachetypes.ErrShutdown)
+		case <-time.After(time.Second):
+			t.Fatal("Put on a shut-down cache deadlocked")
+		}
+	}
+
+	_, err = cache.Size()
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+// TestShutdownIdempotent shuts the cache down from several goroutines at
+// once: only the first call drains the queue, so every entry is evicted
+// exactly once, and every method then reports ErrShutdown.
+func TestShutdownIdempotent(t *testing.T) {
+	ctx := context.Background()
+	var evicted atomic.Int32
+	cache, err := lru2.New[int, string](
+		cachetypes.WithCapacity(10),
+		cachetypes.WithEvictionCB(func(context.Context, int, string) {
+			evicted.Add(1)
+		}),
+	)
+	require.NoError(t, err)
+	for i := range 5 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() { cache.Shutdown(ctx) })
+	}
+	wg.Wait()
+	cache.Shutdown(ctx)
+	require.EqualValues(t, 5, evicted.Load())
+
+	_, _, err = cache.Get(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	_, err = cache.Has(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	require.ErrorIs(t, cache.Put(ctx, 1, "v"), cachetypes.ErrShutdown)
+	_, _, err = cache.Replace(ctx, 1, "v")
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	_, err = cache.Delete(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	_, _, err = cache.GetAndDelete(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	_, err = cache.Size()
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	_, err = cache.Capacity()
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	require.ErrorIs(t, cache.Reset(ctx), cachetypes.ErrShutdown)
+	require.ErrorIs(t, cache.ResetQuiet(ctx), cachetypes.ErrShutdown)
+	err = cache.Traverse(ctx, func(context.Context, int, string) bool { return true })
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	require.EqualValues(t, 5, evicted.Load())
+}
+
+func TestAsyncEvictionCB(t *testing.T) {
+	ctx := context.Background()
+	var evicted atomic.Int32
+	cache, err := lru2.New[int, string](
+		cachetypes.WithCapacity(2),
+		cachetypes.WithAsyncEvictionCB(func(_ context.Context, _ int, _ string) {
+			time.Sleep(time.Millisecond)
+			evicted.Add(1)
+		}, 1),
+	)
+	require.NoError(t, err)
+	for i := range 5 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+
+	// Shutdown waits for the three capacity evictions and the two remaining entries
+	cache.Shutdown(ctx)
+	require.Equal(t, int32(5), evicted.Load())
+}
+
+func TestResetQuiet(t *testing.T) {
+	ctx := context.Background()
+	var evicted int
+	cache, err := lru2.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(_ context.Context, _ int, _ string) {
+			evicted++
+		}),
+	)
+	require.NoError(t, err)
+	for i := range 3 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	require.NoError(t, cache.ResetQuiet(ctx))
+	require.Zero(t, evicted)
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+	_, ok, err := cache.Get(ctx, 0)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the cache stays usable and later evictions still call the callback
+	require.NoError(t, cache.Put(ctx, 1, "v"))
+	require.NoError(t, cache.Reset(ctx))
+	require.Equal(t, 1, evicted)
+
+	cache.Shutdown(ctx)
+	require.ErrorIs(t, cache.ResetQuiet(ctx), cachetypes.ErrShutdown)
+}
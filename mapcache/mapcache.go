@@ -0,0 +1,217 @@
+// Package mapcache provides an unbounded cache backed by a map. It never
+// evicts on Put, which suits small reference data whose size is managed by
+// the caller.
+package mapcache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Cache is a thread-safe map-backed cache without eviction.
+type Cache[K comparable, V any] struct {
+	mu         sync.RWMutex
+	isShutdown bool
+	items      map[K]V
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+}
+
+// Ensure Cache implements the Cache interface.
+var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
+
+// New creates a new map-backed cache. Capacity must not be set; the eviction
+// options apply to entries removed by Delete, GetAndDelete, Reset and Shutdown.
+func New[K comparable, V any](options ...func(o *cachetypes.Options)) (
+	*Cache[K, V], error) {
+	var o cachetypes.Options
+	for _, cb := range options {
+		cb(&o)
+	}
+
+	o1, err := internal.ToUnboundedOptions[K, V](o)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache[K, V]{
+		items:     make(map[K]V),
+		evictor:   internal.NewEvictor(o1),
+		sizeLimit: o1.SizeLimit,
+	}
+	return c, nil
+}
+
+// Get retrieves a value from the cache.
+func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var zero V
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	v, ok := c.items[key]
+	return v, ok, nil
+}
+
+// Has reports whether the key is present.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.items[key]
+	return ok, nil
+}
+
+// Put inserts or updates a value in the cache. It never evicts. It returns
+// cachetypes.ErrEntryTooLarge if the entry exceeds a configured size limit.
+func (c *Cache[K, V]) Put(_ context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return cachetypes.ErrShutdown
+	}
+	c.items[key] = value
+	return nil
+}
+
+// Replace updates the value of an existing key and returns the previous
+// value. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	old, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	c.items[key] = value
+	return old, true, nil
+}
+
+// Delete removes the entry with the specified key from the cache.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete atomically removes the entry and returns its value.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	var zero V
+	if c.isShutdown {
+		c.mu.Unlock()
+		return zero, false, cachetypes.ErrShutdown
+	}
+	value, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return zero, false, nil
+	}
+	delete(c.items, key)
+	c.mu.Unlock() // Unlock before callback to avoid deadlock
+	c.evictor.Evict(ctx, key, value)
+	return value, true, nil
+}
+
+// Size returns the current number of items in the cache.
+func (c *Cache[K, V]) Size() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return len(c.items), nil
+}
+
+// Capacity returns 0, meaning the cache is unbounded.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return 0, nil
+}
+
+// Reset clears the cache and calls the eviction callback for each removed item.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := c.drain()
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	return nil
+}
+
+// drain empties the map and returns its entries; the caller holds mu.
+func (c *Cache[K, V]) drain() []cachetypes.Entry[K, V] {
+	entries := make([]cachetypes.Entry[K, V], 0, len(c.items))
+	for k, v := range c.items {
+		entries = append(entries, cachetypes.Entry[K, V]{Key: k, Value: v})
+	}
+	clear(c.items)
+	return entries
+}
+
+// Traverse calls fn for each entry in unspecified order until fn returns false.
+// fn runs on a snapshot taken under the lock, so it may call back into the cache.
+func (c *Cache[K, V]) Traverse(ctx context.Context,
+	fn func(context.Context, K, V) bool) error {
+	c.mu.RLock()
+	if c.isShutdown {
+		c.mu.RUnlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := make([]cachetypes.Entry[K, V], 0, len(c.items))
+	for k, v := range c.items {
+		entries = append(entries, cachetypes.Entry[K, V]{Key: k, Value: v})
+	}
+	c.mu.RUnlock()
+	for _, en := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(ctx, en.Key, en.Value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Shutdown clears the cache, calling the eviction callback for each item.
+// Every later operation returns ErrShutdown.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return
+	}
+	c.isShutdown = true
+	entries := c.drain()
+	c.items = nil
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	c.evictor.Close(ctx)
+}
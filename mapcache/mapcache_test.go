@@ -0,0 +1,135 @@
+package mapcache_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal/testhelper"
+	"github.com/mcphone2004/cache/mapcache"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// newCache ignores capacity: the shared helpers used here never exceed it.
+func newCache[K comparable, T any](_ uint, evictionCB func(context.Context, K, T)) (iface.Cache[K, T], error) {
+	if evictionCB == nil {
+		return mapcache.New[K, T]()
+	}
+	return mapcache.New[K, T](cachetypes.WithEvictionCB(evictionCB))
+}
+
+func TestNewCache(t *testing.T) {
+	cache, err := mapcache.New[int, string](cachetypes.WithCapacity(2))
+	require.Nil(t, cache)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+}
+
+func TestTraverse(t *testing.T) {
+	testhelper.CommonTraverseTest(t, newCache)
+}
+
+func TestTraverseReentrant(t *testing.T) {
+	testhelper.CommonTraverseReentrantTest(t, newCache)
+}
+
+func TestTraverseCancel(t *testing.T) {
+	testhelper.CommonTraverseCancelTest(t, newCache)
+}
+
+func TestDelete(t *testing.T) {
+	testhelper.CommonDeleteTest(t, newCache)
+}
+
+func TestDeleteNonExistent(t *testing.T) {
+	testhelper.CommonDeleteNonExistentTest(t, newCache)
+}
+
+func TestUpdateNoEviction(t *testing.T) {
+	testhelper.CommonUpdateNoEvictionTest(t, newCache)
+}
+
+func TestGetMultiIter(t *testing.T) {
+	testhelper.CommonGetMultiIterTest(t, newCache)
+}
+
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache)
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache)
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache)
+}
+
+func TestShutdown(t *testing.T) {
+	testhelper.CommonShutdownTest(t, newCache)
+}
+
+func TestConcurrent(t *testing.T) {
+	testhelper.CommonConcurrentTest(t, newCache)
+}
+
+func TestStressShutdown(t *testing.T) {
+	testhelper.CommonStressShutdownTest(t, newCache)
+}
+
+func TestNeverEvicts(t *testing.T) {
+	ctx := context.Background()
+	evicted := map[int]string{}
+	cache, err := mapcache.New[int, string](
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, v string) {
+			evicted[k] = v
+		}),
+	)
+	require.NoError(t, err)
+
+	for i := range 1000 {
+		require.NoError(t, cache.Put(ctx, i, "v"))
+	}
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Equal(t, 1000, size)
+	capacity, err := cache.Capacity()
+	require.NoError(t, err)
+	require.Zero(t, capacity)
+	require.Empty(t, evicted)
+
+	require.NoError(t, cache.Reset(ctx))
+	require.Len(t, evicted, 1000)
+	size, err = cache.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	cache.Shutdown(ctx)
+	require.Equal(t, "one", evicted[1])
+}
+
+func TestEvictionCallbackPanic(t *testing.T) {
+	ctx := context.Background()
+	cache, err := mapcache.New[int, string](
+		cachetypes.WithEvictionCB(func(context.Context, int, string) {
+			panic("eviction panic")
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	require.NoError(t, cache.Put(ctx, 1, "one"))
+	require.NotPanics(t, func() {
+		found, err := cache.Delete(ctx, 1)
+		require.NoError(t, err)
+		require.True(t, found)
+	})
+}
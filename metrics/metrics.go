@@ -0,0 +1,241 @@
+// Package metrics provides a cache decorator that counts operations and
+// measures their latency, so any iface.Cache can be instrumented uniformly.
+// The counters are those of package stats; this package adds the latencies.
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/stats"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Ensure Cache satisfies iface.Cache at compile time.
+var _ iface.Cache[struct{}, struct{}] = (*Cache[struct{}, struct{}])(nil)
+
+// Latency summarises the timing of one kind of operation.
+type Latency struct {
+	// Count is the number of timed calls.
+	Count uint64
+	// Total is the sum of the durations of all timed calls.
+	Total time.Duration
+	// Max is the longest single call observed.
+	Max time.Duration
+}
+
+// Mean returns the average duration of a call, or 0 if no calls were timed.
+func (l Latency) Mean() time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+	return l.Total / time.Duration(l.Count) //nolint:gosec // Count never exceeds int64 in practice
+}
+
+// Latencies holds a point-in-time copy of all latencies.
+type Latencies struct {
+	// Get times Get calls.
+	Get Latency
+	// Put times Put and Replace calls. Because eviction callbacks run synchronously on
+	// the caller's goroutine, this includes the cost of any eviction.
+	Put Latency
+	// Delete times Delete and GetAndDelete calls, including the eviction
+	// callback fired for the removed entry.
+	Delete Latency
+	// Reset times Reset calls, including the eviction callbacks for every entry.
+	Reset Latency
+	// Evict times the eviction callbacks installed with EvictionHook or
+	// EvictionHookWith on their own, so that a slow callback can be told
+	// apart from a slow cache.
+	Evict Latency
+}
+
+// latency accumulates timings for one kind of operation with atomics.
+type latency struct {
+	count atomic.Uint64
+	total atomic.Int64
+	max   atomic.Int64
+}
+
+func (l *latency) observe(d time.Duration) {
+	l.count.Add(1)
+	l.total.Add(int64(d))
+	for {
+		cur := l.max.Load()
+		if int64(d) <= cur || l.max.CompareAndSwap(cur, int64(d)) {
+			return
+		}
+	}
+}
+
+func (l *latency) load() Latency {
+	return Latency{
+		Count: l.count.Load(),
+		Total: time.Duration(l.total.Load()),
+		Max:   time.Duration(l.max.Load()),
+	}
+}
+
+// Cache wraps an [iface.Cache], counting operations with a [stats.Cache] and
+// recording their latencies. All counters are updated with atomics and are
+// safe for concurrent use.
+//
+// The zero value is valid. To also count and time evictions, obtain an
+// [Cache.EvictionHook] before constructing the inner cache, then call
+// [Cache.Wrap]:
+//
+//	var mc metrics.Cache[string, int]
+//	c, _ := lru.New[string, int](
+//	    cachetypes.WithCapacity(1024),
+//	    cachetypes.WithEvictionCB(mc.EvictionHook()),
+//	)
+//	mc.Wrap(c)
+//
+// When eviction tracking is not needed, use [Wrap] instead.
+type Cache[K comparable, V any] struct {
+	stats stats.Cache[K, V]
+	// now is nil in the zero value, which uses time.Now.
+	now func() time.Time
+
+	get    latency
+	put    latency
+	delete latency
+	reset  latency
+	evict  latency
+}
+
+// Wrap returns a Cache that instruments c.
+func Wrap[K comparable, V any](c iface.Cache[K, V]) *Cache[K, V] {
+	m := &Cache[K, V]{}
+	m.Wrap(c)
+	return m
+}
+
+// Wrap sets the inner cache. Call it once before any concurrent use of c.
+func (c *Cache[K, V]) Wrap(inner iface.Cache[K, V]) {
+	c.stats.Wrap(inner)
+}
+
+// EvictionHook returns an eviction callback that counts and times
+// evictions. Pass the result to [cachetypes.WithEvictionCB] when constructing
+// the inner cache.
+func (c *Cache[K, V]) EvictionHook() cachetypes.CBFunc[K, V] {
+	return c.EvictionHookWith(nil)
+}
+
+// EvictionHookWith returns an eviction callback that calls cb (if non-nil),
+// counting the eviction and recording the time cb took as Evict.
+func (c *Cache[K, V]) EvictionHookWith(cb cachetypes.CBFunc[K, V]) cachetypes.CBFunc[K, V] {
+	hook := c.stats.EvictionHookWith(cb)
+	return func(ctx context.Context, k K, v V) {
+		start := c.clock()
+		hook(ctx, k, v)
+		c.evict.observe(c.since(start))
+	}
+}
+
+// Snapshot returns a point-in-time copy of the counters, in the same form
+// as [stats.Cache.Snapshot], so a Cache can be exported like one.
+func (c *Cache[K, V]) Snapshot() stats.Snapshot {
+	return c.stats.Snapshot()
+}
+
+// Latencies returns a point-in-time copy of all latencies.
+func (c *Cache[K, V]) Latencies() Latencies {
+	return Latencies{
+		Get:    c.get.load(),
+		Put:    c.put.load(),
+		Delete: c.delete.load(),
+		Reset:  c.reset.load(),
+		Evict:  c.evict.load(),
+	}
+}
+
+// clock returns the current time from the configured clock.
+func (c *Cache[K, V]) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
+
+// since returns the time elapsed since start using the configured clock.
+func (c *Cache[K, V]) since(start time.Time) time.Duration {
+	return c.clock().Sub(start)
+}
+
+// Get implements [iface.Cache]. It is counted as [stats.Cache.Get] counts it
+// and timed either way.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	start := c.clock()
+	v, found, err := c.stats.Get(ctx, key)
+	c.get.observe(c.since(start))
+	return v, found, err
+}
+
+// Has implements [iface.Cache]. It is not timed.
+func (c *Cache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	return c.stats.Has(ctx, key)
+}
+
+// Put implements [iface.Cache].
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	start := c.clock()
+	err := c.stats.Put(ctx, key, value)
+	c.put.observe(c.since(start))
+	return err
+}
+
+// Delete implements [iface.Cache].
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	start := c.clock()
+	found, err := c.stats.Delete(ctx, key)
+	c.delete.observe(c.since(start))
+	return found, err
+}
+
+// GetAndDelete implements [iface.Cache]. It is timed as a Delete.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	start := c.clock()
+	v, found, err := c.stats.GetAndDelete(ctx, key)
+	c.delete.observe(c.since(start))
+	return v, found, err
+}
+
+// Replace implements [iface.Cache]. It is timed as a Put.
+func (c *Cache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	start := c.clock()
+	old, found, err := c.stats.Replace(ctx, key, value)
+	c.put.observe(c.since(start))
+	return old, found, err
+}
+
+// Size implements [iface.Cache].
+func (c *Cache[K, V]) Size() (int, error) {
+	return c.stats.Size()
+}
+
+// Capacity implements [iface.Cache].
+func (c *Cache[K, V]) Capacity() (int, error) {
+	return c.stats.Capacity()
+}
+
+// Reset implements [iface.Cache].
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	start := c.clock()
+	err := c.stats.Reset(ctx)
+	c.reset.observe(c.since(start))
+	return err
+}
+
+// Traverse implements [iface.Cache].
+func (c *Cache[K, V]) Traverse(ctx context.Context, fn func(context.Context, K, V) bool) error {
+	return c.stats.Traverse(ctx, fn)
+}
+
+// Shutdown implements [iface.Cache].
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.stats.Shutdown(ctx)
+}
@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/lru"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// fakeClock advances by step on every call so each timed call lasts exactly step.
+type fakeClock struct {
+	mu   sync.Mutex
+	t    time.Time
+	step time.Duration
+}
+
+func (f *fakeClock) now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.t = f.t.Add(f.step)
+	return f.t
+}
+
+func newWrapped(t *testing.T, step time.Duration) *Cache[string, int] {
+	t.Helper()
+	inner, err := lru.New[string, int](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	c := Wrap[string, int](inner)
+	clk := &fakeClock{step: step}
+	c.now = clk.now
+	t.Cleanup(func() { c.Shutdown(context.Background()) })
+	return c
+}
+
+func TestCountsAndLatency(t *testing.T) {
+	ctx := context.Background()
+	c := newWrapped(t, time.Millisecond)
+
+	require.NoError(t, c.Put(ctx, "a", 1))
+	require.NoError(t, c.Put(ctx, "b", 2))
+	_, found, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	_, found, err = c.Get(ctx, "z")
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, err = c.Delete(ctx, "b")
+	require.NoError(t, err)
+	v, found, err := c.GetAndDelete(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+	require.NoError(t, c.Reset(ctx))
+
+	snap := c.Snapshot()
+	assert.Equal(t, uint64(2), snap.Hits)
+	assert.Equal(t, uint64(1), snap.Misses)
+	assert.Equal(t, uint64(2), snap.Puts)
+	assert.Equal(t, uint64(2), snap.Deletes)
+	lat := c.Latencies()
+	assert.Equal(t, Latency{Count: 2, Total: 2 * time.Millisecond, Max: time.Millisecond}, lat.Get)
+	assert.Equal(t, Latency{Count: 2, Total: 2 * time.Millisecond, Max: time.Millisecond}, lat.Put)
+	assert.Equal(t, Latency{Count: 2, Total: 2 * time.Millisecond, Max: time.Millisecond}, lat.Delete)
+	assert.Equal(t, uint64(1), lat.Reset.Count)
+	assert.Equal(t, time.Millisecond, lat.Put.Mean())
+	assert.Zero(t, Latency{}.Mean())
+}
+
+func TestErrorsNotCountedAsHitsOrMisses(t *testing.T) {
+	ctx := context.Background()
+	c := newWrapped(t, time.Microsecond)
+	c.Shutdown(ctx)
+
+	_, _, err := c.Get(ctx, "a")
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+
+	snap := c.Snapshot()
+	assert.Zero(t, snap.Hits)
+	assert.Zero(t, snap.Misses)
+	assert.Equal(t, uint64(1), snap.Errors)
+	assert.Equal(t, uint64(1), c.Latencies().Get.Count)
+}
+
+func TestPassthrough(t *testing.T) {
+	ctx := context.Background()
+	var c iface.Cache[string, int] = newWrapped(t, time.Microsecond)
+
+	require.NoError(t, c.Put(ctx, "a", 1))
+	found, err := c.Has(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	sz, err := c.Size()
+	require.NoError(t, err)
+	assert.Equal(t, 1, sz)
+
+	capacity, err := c.Capacity()
+	require.NoError(t, err)
+	assert.Equal(t, 4, capacity)
+
+	visited := 0
+	require.NoError(t, c.Traverse(ctx, func(_ context.Context, _ string, _ int) bool {
+		visited++
+		return true
+	}))
+	assert.Equal(t, 1, visited)
+}
+
+func TestEvictionHookTimesCallback(t *testing.T) {
+	ctx := context.Background()
+	var c Cache[string, int]
+	clk := &fakeClock{step: time.Millisecond}
+	c.now = clk.now
+	var evicted []string
+	inner, err := lru.New[string, int](
+		cachetypes.WithCapacity(1),
+		cachetypes.WithEvictionCB(c.EvictionHookWith(func(_ context.Context, k string, _ int) {
+			evicted = append(evicted, k)
+		})),
+	)
+	require.NoError(t, err)
+	c.Wrap(inner)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.Put(ctx, "a", 1))
+	require.NoError(t, c.Put(ctx, "b", 2))
+	assert.Equal(t, []string{"a"}, evicted)
+	assert.Equal(t, uint64(1), c.Snapshot().Evictions)
+	lat := c.Latencies()
+	assert.Equal(t, Latency{Count: 1, Total: time.Millisecond, Max: time.Millisecond}, lat.Evict)
+	// the eviction ran inside the second Put, which is timed around it
+	assert.Equal(t, 4*time.Millisecond, lat.Put.Total)
+}
+
+func TestZeroValueUsesSystemClock(t *testing.T) {
+	ctx := context.Background()
+	inner, err := lru.New[string, int](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	var c Cache[string, int]
+	c.Wrap(inner)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.Put(ctx, "a", 1))
+	assert.Equal(t, uint64(1), c.Latencies().Put.Count)
+	assert.Equal(t, uint64(1), c.Snapshot().Puts)
+}
+
+func TestMaxTracksLongestCall(t *testing.T) {
+	var l latency
+	l.observe(time.Millisecond)
+	l.observe(5 * time.Millisecond)
+	l.observe(2 * time.Millisecond)
+	got := l.load()
+	assert.Equal(t, uint64(3), got.Count)
+	assert.Equal(t, 8*time.Millisecond, got.Total)
+	assert.Equal(t, 5*time.Millisecond, got.Max)
+}
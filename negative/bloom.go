@@ -0,0 +1,73 @@
+package negative
+
+import (
+	"hash/maphash"
+	"math"
+	"sync/atomic"
+)
+
+// AbsenceFilter is a probabilistic set of the keys that exist in the source
+// of truth, e.g. a Bloom filter. MightContain may report false positives but
+// must never report false negatives for added keys. Implementations must be
+// safe for concurrent use.
+type AbsenceFilter[K comparable] interface {
+	Add(key K)
+	MightContain(key K) bool
+}
+
+// Ensure BloomFilter implements AbsenceFilter.
+var _ AbsenceFilter[string] = (*BloomFilter[string])(nil)
+
+// BloomFilter is a concurrency-safe Bloom filter usable as an AbsenceFilter.
+type BloomFilter[K comparable] struct {
+	seed   maphash.Seed
+	bits   []atomic.Uint64
+	nbits  uint64
+	hashes int
+}
+
+// NewBloomFilter returns a Bloom filter sized to hold n keys with about the
+// given false-positive rate, which must be in (0, 1).
+func NewBloomFilter[K comparable](n uint, falsePositiveRate float64) *BloomFilter[K] {
+	n = max(n, 1)
+	// optimal sizing: m = -n ln p / (ln 2)^2 bits and k = m/n ln 2 hashes
+	m := math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	nbits := max(uint64(m), 64)
+	words := (nbits + 63) / 64
+	return &BloomFilter[K]{
+		seed:   maphash.MakeSeed(),
+		bits:   make([]atomic.Uint64, words),
+		nbits:  words * 64,
+		hashes: max(int(math.Round(float64(nbits)/float64(n)*math.Ln2)), 1),
+	}
+}
+
+// positions calls fn with each bit position of key until fn returns false.
+func (b *BloomFilter[K]) positions(key K, fn func(pos uint64) bool) {
+	h := maphash.Comparable(b.seed, key)
+	h1, h2 := h, h>>32|1
+	for i := range b.hashes {
+		if !fn((h1 + uint64(i)*h2) % b.nbits) { //nolint:gosec // i is a small hash number
+			return
+		}
+	}
+}
+
+// Add records key as present.
+func (b *BloomFilter[K]) Add(key K) {
+	b.positions(key, func(pos uint64) bool {
+		b.bits[pos/64].Or(1 << (pos % 64))
+		return true
+	})
+}
+
+// MightContain reports whether key may have been added. A false result is
+// certain; a true result is wrong with about the configured rate.
+func (b *BloomFilter[K]) MightContain(key K) bool {
+	found := true
+	b.positions(key, func(pos uint64) bool {
+		found = b.bits[pos/64].Load()&(1<<(pos%64)) != 0
+		return found
+	})
+	return found
+}
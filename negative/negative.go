@@ -0,0 +1,453 @@
+// Package negative provides a cache decorator that loads missing keys and
+// remembers keys the loader did not find, so an expensive loader is not
+// called repeatedly for keys that do not exist.
+package negative
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/tlru"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Ensure Cache satisfies iface.Cache at compile time.
+var _ iface.Cache[struct{}, struct{}] = (*Cache[struct{}, struct{}])(nil)
+
+// Loader fetches the value for key from the source of truth. It reports
+// found=false when the key does not exist.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (value V, found bool, err error)
+
+// Cache wraps an iface.Cache and a Loader. On a miss Get calls the loader,
+// storing found values in the wrapped cache and recording a tombstone for
+// keys that were not found. Tombstones are served as misses until their TTL
+// expires and live in a separate bounded cache, so they do not count against
+// the capacity of the wrapped cache.
+//
+// With WithRefreshAhead, entries older than the refresh age are reloaded in
+// the background on a Get hit. With WithMaxAge, entries older than the max
+// age are expired: Get reloads them before returning, while GetAllowStale
+// returns them flagged as stale and reloads them in the background. A
+// background reload never overwrites a Put, Replace, Delete or Reset made
+// while it runs. Freshness markers live in other bounded caches; an entry
+// whose marker was dropped is simply refreshed or expired early.
+type Cache[K comparable, V any] struct {
+	inner      iface.Cache[K, V]
+	loader     Loader[K, V]
+	tombstones *tlru.Cache[K, struct{}]
+	absence    AbsenceFilter[K] // nil unless WithAbsenceFilter is set
+	tracer     Tracer           // nil unless WithTracer is set
+
+	fresh      *tlru.Cache[K, struct{}] // nil unless refresh-ahead is enabled
+	live       *tlru.Cache[K, struct{}] // nil unless a max age is set
+	mu         sync.Mutex
+	refreshing map[K]uint64 // keys being refreshed, with the writes each has seen since
+	closed     bool
+	wg         sync.WaitGroup
+}
+
+// New returns a Cache that loads misses of c through loader. The Cache takes
+// ownership of c: Shutdown shuts it down.
+func New[K comparable, V any](c iface.Cache[K, V], loader Loader[K, V],
+	options ...func(o *Options)) (*Cache[K, V], error) {
+	var o Options
+	for _, cb := range options {
+		cb(&o)
+	}
+	if o.MaxAge < 0 || (o.MaxAge > 0 && o.MaxAge <= o.RefreshAhead) {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "max age must be positive and exceed the refresh-ahead age",
+		}
+	}
+	if o.TTL <= 0 {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "negative TTL must be positive",
+		}
+	}
+	if loader == nil {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "loader cannot be nil",
+		}
+	}
+	var absence AbsenceFilter[K]
+	if o.AbsenceFilter != nil {
+		f, ok := o.AbsenceFilter.(AbsenceFilter[K])
+		if !ok {
+			return nil, &cachetypes.InvalidOptionsError{
+				Message: "incorrect type for AbsenceFilter",
+			}
+		}
+		absence = f
+	}
+	if o.MaxTombstones == 0 {
+		o.MaxTombstones = defaultMaxTombstones
+	}
+	tombstones, err := tlru.New[K, struct{}](
+		tlru.WithCapacity[K, struct{}](o.MaxTombstones),
+		tlru.WithDefaultTTL[K, struct{}](o.TTL),
+	)
+	if err != nil {
+		return nil, err
+	}
+	nc := &Cache[K, V]{
+		inner:      c,
+		loader:     loader,
+		tombstones: tombstones,
+		absence:    absence,
+		tracer:     o.Tracer,
+		refreshing: make(map[K]uint64),
+	}
+	if o.RefreshAhead > 0 {
+		if nc.fresh, err = newFreshMarkers[K](c, o.RefreshAhead); err != nil {
+			tombstones.Shutdown(context.Background())
+			return nil, err
+		}
+	}
+	if o.MaxAge > 0 {
+		if nc.live, err = newFreshMarkers[K](c, o.MaxAge); err != nil {
+			if nc.fresh != nil {
+				nc.fresh.Shutdown(context.Background())
+			}
+			tombstones.Shutdown(context.Background())
+			return nil, err
+		}
+	}
+	return nc, nil
+}
+
+// newFreshMarkers returns the cache remembering which keys of c were loaded
+// or stored less than age ago. It is sized like c, falling back to
+// defaultMaxTombstones when c is unbounded.
+func newFreshMarkers[K comparable, V any](c iface.Cache[K, V],
+	age time.Duration) (*tlru.Cache[K, struct{}], error) {
+	capacity, err := c.Capacity()
+	if err != nil {
+		return nil, err
+	}
+	if capacity <= 0 {
+		capacity = defaultMaxTombstones
+	}
+	return tlru.New[K, struct{}](
+		tlru.WithCapacity[K, struct{}](uint(capacity)),
+		tlru.WithDefaultTTL[K, struct{}](age),
+	)
+}
+
+// Get returns the cached value, or loads it on a miss. Keys with a live
+// tombstone, or ruled out by the absence filter, are reported as misses
+// without calling the loader. Loader errors are returned and not remembered.
+// An entry past the max age is reloaded before Get returns; a key the loader
+// no longer finds is then removed and reported as a miss.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	ctx, end := c.startSpan(ctx, "cache.Get")
+	defer end()
+	var zero V
+	v, found, err := c.inner.Get(ctx, key)
+	if err != nil {
+		return zero, false, err
+	}
+	if found {
+		expired, err := c.isExpired(ctx, key)
+		if err != nil {
+			return zero, false, err
+		}
+		if !expired {
+			c.maybeRefresh(ctx, key)
+			return v, true, nil
+		}
+		return c.loadAndStore(ctx, key)
+	}
+	return c.getMiss(ctx, key)
+}
+
+// GetAllowStale is like Get, but an entry past the max age is returned at
+// once with stale set while it is reloaded in the background, the
+// stale-while-revalidate pattern, so a hit never waits for the loader. A
+// failed reload keeps the stale value, and the next call retries. Misses
+// still load synchronously. Without WithMaxAge no entry is ever stale.
+func (c *Cache[K, V]) GetAllowStale(ctx context.Context, key K) (value V, stale, ok bool, err error) {
+	ctx, end := c.startSpan(ctx, "cache.GetAllowStale")
+	defer end()
+	v, found, err := c.inner.Get(ctx, key)
+	if err != nil {
+		return v, false, false, err
+	}
+	if !found {
+		v, found, err = c.getMiss(ctx, key)
+		return v, false, found, err
+	}
+	expired, err := c.isExpired(ctx, key)
+	if err != nil {
+		return v, false, false, err
+	}
+	if expired {
+		c.startRefresh(ctx, key)
+		return v, true, true, nil
+	}
+	c.maybeRefresh(ctx, key)
+	return v, false, true, nil
+}
+
+// getMiss handles a key absent from the wrapped cache.
+func (c *Cache[K, V]) getMiss(ctx context.Context, key K) (V, bool, error) {
+	var zero V
+	if c.absence != nil && !c.absence.MightContain(key) {
+		return zero, false, nil
+	}
+	if isNeg, err := c.tombstones.Has(ctx, key); err != nil || isNeg {
+		return zero, false, err
+	}
+	return c.loadAndStore(ctx, key)
+}
+
+// loadAndStore calls the loader for key, caching a found value and recording
+// a tombstone otherwise.
+func (c *Cache[K, V]) loadAndStore(ctx context.Context, key K) (V, bool, error) {
+	var zero V
+	v, found, err := c.load(ctx, key)
+	if err != nil {
+		return zero, false, err
+	}
+	if !found {
+		if _, err := c.inner.Delete(ctx, key); err != nil {
+			return zero, false, err
+		}
+		return zero, false, c.tombstones.Put(ctx, key, struct{}{})
+	}
+	if err := c.markFresh(ctx, key); err != nil {
+		return zero, false, err
+	}
+	return v, true, c.inner.Put(ctx, key, v)
+}
+
+// noopEnd ends the span of a Cache without a tracer.
+func noopEnd() {}
+
+// startSpan starts a span if a tracer is configured.
+func (c *Cache[K, V]) startSpan(ctx context.Context, name string) (context.Context, func()) {
+	if c.tracer == nil {
+		return ctx, noopEnd
+	}
+	return c.tracer.StartSpan(ctx, name)
+}
+
+// load calls the loader within a span.
+func (c *Cache[K, V]) load(ctx context.Context, key K) (V, bool, error) {
+	ctx, end := c.startSpan(ctx, "cache.load")
+	defer end()
+	return c.loader(ctx, key)
+}
+
+// markFresh records that key was just loaded or stored.
+func (c *Cache[K, V]) markFresh(ctx context.Context, key K) error {
+	if c.fresh != nil {
+		if err := c.fresh.Put(ctx, key, struct{}{}); err != nil {
+			return err
+		}
+	}
+	if c.live != nil {
+		return c.live.Put(ctx, key, struct{}{})
+	}
+	return nil
+}
+
+// isExpired reports whether a max age is set and key was loaded or stored
+// longer ago.
+func (c *Cache[K, V]) isExpired(ctx context.Context, key K) (bool, error) {
+	if c.live == nil {
+		return false, nil
+	}
+	isLive, err := c.live.Has(ctx, key)
+	return !isLive, err
+}
+
+// maybeRefresh starts a background reload of key if refresh-ahead is
+// enabled, the entry is older than the refresh age and no reload of key is
+// already running.
+func (c *Cache[K, V]) maybeRefresh(ctx context.Context, key K) {
+	if c.fresh == nil {
+		return
+	}
+	if isFresh, err := c.fresh.Has(ctx, key); err != nil || isFresh {
+		return
+	}
+	c.startRefresh(ctx, key)
+}
+
+// startRefresh reloads key in the background unless a reload of key is
+// already running or the cache is shutting down.
+func (c *Cache[K, V]) startRefresh(ctx context.Context, key K) {
+	c.mu.Lock()
+	if _, busy := c.refreshing[key]; busy || c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = 0
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+		c.refresh(context.WithoutCancel(ctx), key)
+	}()
+}
+
+// refresh reloads key into the wrapped cache. A key the loader no longer
+// finds is removed and remembered as a miss. On a loader error the stale
+// value is kept and the next Get hit retries. The result is dropped if the
+// key was written while the loader ran, and a found value only replaces an
+// entry that is still cached, so that a concurrent Put or Delete wins. It is
+// applied with mu held, which orders it against those writes.
+func (c *Cache[K, V]) refresh(ctx context.Context, key K) {
+	v, found, err := c.load(ctx, key)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refreshing[key] != 0 {
+		return
+	}
+	if !found {
+		_, _ = c.inner.Delete(ctx, key)
+		_ = c.tombstones.Put(ctx, key, struct{}{})
+		return
+	}
+	if _, replaced, err := c.inner.Replace(ctx, key, v); err == nil && replaced {
+		_ = c.markFresh(ctx, key)
+	}
+}
+
+// invalidate makes the background refresh of key, if one is in flight, drop
+// its result. Writers call it before writing to the wrapped cache.
+func (c *Cache[K, V]) invalidate(key K) {
+	c.mu.Lock()
+	if gen, busy := c.refreshing[key]; busy {
+		c.refreshing[key] = gen + 1
+	}
+	c.mu.Unlock()
+}
+
+// IsNegative reports whether a miss for key is currently remembered.
+func (c *Cache[K, V]) IsNegative(ctx context.Context, key K) (bool, error) {
+	return c.tombstones.Has(ctx, key)
+}
+
+// Has reports whether the key is present in the wrapped cache. It neither
+// loads the key nor consults tombstones.
+func (c *Cache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	return c.inner.Has(ctx, key)
+}
+
+// Put stores the value and forgets any tombstone for the key.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	ctx, end := c.startSpan(ctx, "cache.Put")
+	defer end()
+	c.invalidate(key)
+	if _, err := c.tombstones.Delete(ctx, key); err != nil {
+		return err
+	}
+	if c.absence != nil {
+		c.absence.Add(key)
+	}
+	if err := c.markFresh(ctx, key); err != nil {
+		return err
+	}
+	return c.inner.Put(ctx, key, value)
+}
+
+// Replace updates an existing key of the wrapped cache and returns its
+// previous value. It does not call the loader for an absent key.
+func (c *Cache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	c.invalidate(key)
+	old, found, err := c.inner.Replace(ctx, key, value)
+	if err != nil || !found {
+		return old, found, err
+	}
+	return old, true, c.markFresh(ctx, key)
+}
+
+// Delete removes the key and any tombstone for it, so the next Get calls
+// the loader again.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	c.invalidate(key)
+	if _, err := c.tombstones.Delete(ctx, key); err != nil {
+		return false, err
+	}
+	return c.inner.Delete(ctx, key)
+}
+
+// GetAndDelete removes the key and any tombstone for it and returns the
+// cached value. It does not call the loader.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	c.invalidate(key)
+	if _, err := c.tombstones.Delete(ctx, key); err != nil {
+		var zero V
+		return zero, false, err
+	}
+	return c.inner.GetAndDelete(ctx, key)
+}
+
+// Size returns the number of entries in the wrapped cache; tombstones are
+// not counted.
+func (c *Cache[K, V]) Size() (int, error) {
+	return c.inner.Size()
+}
+
+// Capacity returns the capacity of the wrapped cache.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	return c.inner.Capacity()
+}
+
+// Reset clears the wrapped cache and all tombstones. Background refreshes
+// in flight drop their results.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	for key, gen := range c.refreshing {
+		c.refreshing[key] = gen + 1
+	}
+	c.mu.Unlock()
+	if err := c.tombstones.Reset(ctx); err != nil {
+		return err
+	}
+	if c.fresh != nil {
+		if err := c.fresh.Reset(ctx); err != nil {
+			return err
+		}
+	}
+	if c.live != nil {
+		if err := c.live.Reset(ctx); err != nil {
+			return err
+		}
+	}
+	return c.inner.Reset(ctx)
+}
+
+// Traverse visits the entries of the wrapped cache.
+func (c *Cache[K, V]) Traverse(ctx context.Context, fn func(context.Context, K, V) bool) error {
+	return c.inner.Traverse(ctx, fn)
+}
+
+// Shutdown waits for background refreshes to finish, then shuts down the
+// wrapped cache and the tombstone cache.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.wg.Wait()
+	if c.fresh != nil {
+		c.fresh.Shutdown(ctx)
+	}
+	if c.live != nil {
+		c.live.Shutdown(ctx)
+	}
+	c.tombstones.Shutdown(ctx)
+	c.inner.Shutdown(ctx)
+}
@@ -0,0 +1,510 @@
+package negative_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/lru"
+	"github.com/mcphone2004/cache/negative"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// countingLoader finds only the keys in values and counts its calls.
+type countingLoader struct {
+	values map[int]string
+	calls  map[int]int
+	err    error
+}
+
+func (l *countingLoader) load(_ context.Context, key int) (string, bool, error) {
+	l.calls[key]++
+	if l.err != nil {
+		return "", false, l.err
+	}
+	v, ok := l.values[key]
+	return v, ok, nil
+}
+
+func newNegative(t *testing.T, capacity uint, l *countingLoader,
+	options ...func(o *negative.Options)) *negative.Cache[int, string] {
+	t.Helper()
+	inner, err := lru.New[int, string](cachetypes.WithCapacity(capacity))
+	require.NoError(t, err)
+	c, err := negative.New(inner, l.load, options...)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Shutdown(context.Background()) })
+	return c
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	inner, err := lru.New[int, string](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	defer inner.Shutdown(context.Background())
+
+	var aerr *cachetypes.InvalidOptionsError
+	_, err = negative.New[int, string](inner, nil, negative.WithTTL(time.Second))
+	require.ErrorAs(t, err, &aerr)
+	_, err = negative.New(inner, (&countingLoader{}).load)
+	require.ErrorAs(t, err, &aerr)
+	_, err = negative.New(inner, (&countingLoader{}).load,
+		negative.WithTTL(time.Second),
+		negative.WithRefreshAhead(time.Minute),
+		negative.WithMaxAge(time.Second),
+	)
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "max age must be positive and exceed the refresh-ahead age", aerr.Error())
+}
+
+func TestGetLoadsAndRemembersMisses(t *testing.T) {
+	ctx := context.Background()
+	l := &countingLoader{values: map[int]string{1: "one"}, calls: map[int]int{}}
+	c := newNegative(t, 2, l, negative.WithTTL(time.Hour))
+
+	for range 3 {
+		v, ok, err := c.Get(ctx, 1)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "one", v)
+
+		_, ok, err = c.Get(ctx, 2)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	require.Equal(t, map[int]int{1: 1, 2: 1}, l.calls)
+
+	isNeg, err := c.IsNegative(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, isNeg)
+
+	// Put replaces the tombstone with a real value
+	require.NoError(t, c.Put(ctx, 2, "two"))
+	v, ok, err := c.Get(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "two", v)
+}
+
+func TestTombstoneExpires(t *testing.T) {
+	ctx := context.Background()
+	l := &countingLoader{values: map[int]string{}, calls: map[int]int{}}
+	c := newNegative(t, 2, l, negative.WithTTL(20*time.Millisecond))
+
+	_, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.Eventually(t, func() bool {
+		isNeg, err := c.IsNegative(ctx, 1)
+		return err == nil && !isNeg
+	}, time.Second, 5*time.Millisecond)
+
+	l.values[1] = "one"
+	v, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "one", v)
+	require.Equal(t, 2, l.calls[1])
+}
+
+func TestTombstonesDoNotStarveEntries(t *testing.T) {
+	ctx := context.Background()
+	l := &countingLoader{values: map[int]string{1: "one"}, calls: map[int]int{}}
+	c := newNegative(t, 1, l, negative.WithTTL(time.Hour), negative.WithMaxTombstones(4))
+
+	_, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	for k := 100; k < 110; k++ {
+		_, ok, err = c.Get(ctx, k)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+
+	// the real entry survives many misses in a cache of capacity 1
+	found, err := c.Has(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Equal(t, 1, size)
+
+	// only the most recent tombstones are kept
+	isNeg, err := c.IsNegative(ctx, 100)
+	require.NoError(t, err)
+	require.False(t, isNeg)
+	isNeg, err = c.IsNegative(ctx, 109)
+	require.NoError(t, err)
+	require.True(t, isNeg)
+}
+
+func TestLoaderErrorNotRemembered(t *testing.T) {
+	ctx := context.Background()
+	errLoad := errors.New("backend down")
+	l := &countingLoader{values: map[int]string{}, calls: map[int]int{}, err: errLoad}
+	c := newNegative(t, 2, l, negative.WithTTL(time.Hour))
+
+	_, _, err := c.Get(ctx, 1)
+	require.ErrorIs(t, err, errLoad)
+	isNeg, err := c.IsNegative(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, isNeg)
+}
+
+func TestDeleteAndResetForgetTombstones(t *testing.T) {
+	ctx := context.Background()
+	l := &countingLoader{values: map[int]string{}, calls: map[int]int{}}
+	c := newNegative(t, 2, l, negative.WithTTL(time.Hour))
+
+	_, _, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	_, err = c.Delete(ctx, 1)
+	require.NoError(t, err)
+	_, _, err = c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, l.calls[1])
+
+	require.NoError(t, c.Reset(ctx))
+	_, _, err = c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 3, l.calls[1])
+}
+
+// versionLoader returns a value that changes with every call, and blocks
+// while gate is held so tests can observe a refresh in flight.
+type versionLoader struct {
+	gate  sync.RWMutex
+	calls atomic.Int32
+}
+
+func (l *versionLoader) load(_ context.Context, _ int) (int32, bool, error) {
+	l.gate.RLock()
+	defer l.gate.RUnlock()
+	return l.calls.Add(1), true, nil
+}
+
+func TestRefreshAhead(t *testing.T) {
+	ctx := context.Background()
+	inner, err := lru.New[int, int32](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	l := &versionLoader{}
+	c, err := negative.New(inner, l.load,
+		negative.WithTTL(time.Hour),
+		negative.WithRefreshAhead(20*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	v, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int32(1), v)
+
+	// a fresh hit does not reload
+	v, _, err = c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), v)
+	require.Equal(t, int32(1), l.calls.Load())
+
+	time.Sleep(40 * time.Millisecond)
+
+	// a stale hit returns the cached value while the refresh is blocked,
+	// and concurrent hits do not start another refresh
+	l.gate.Lock()
+	for range 5 {
+		v, ok, err = c.Get(ctx, 1)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, int32(1), v)
+	}
+	l.gate.Unlock()
+
+	require.Eventually(t, func() bool {
+		v, _, err := c.Get(ctx, 1)
+		return err == nil && v == 2
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, int32(2), l.calls.Load())
+}
+
+func TestRefreshAheadRemovesVanishedKey(t *testing.T) {
+	ctx := context.Background()
+	inner, err := lru.New[int, string](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	var gone atomic.Bool
+	loader := func(context.Context, int) (string, bool, error) {
+		return "one", !gone.Load(), nil
+	}
+	c, err := negative.New(inner, loader,
+		negative.WithTTL(time.Hour),
+		negative.WithRefreshAhead(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	_, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	gone.Store(true)
+	time.Sleep(20 * time.Millisecond)
+	_, ok, err = c.Get(ctx, 1) // served stale, triggers the refresh
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.Eventually(t, func() bool {
+		isNeg, err := c.IsNegative(ctx, 1)
+		return err == nil && isNeg
+	}, time.Second, 5*time.Millisecond)
+	found, err := c.Has(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+// shutdownSnapshot records the entries of the wrapped cache when it is shut
+// down, i.e. after every background refresh of a negative.Cache has finished.
+type shutdownSnapshot struct {
+	iface.Cache[int, string]
+	entries map[int]string
+}
+
+func (s *shutdownSnapshot) Shutdown(ctx context.Context) {
+	s.entries = map[int]string{}
+	_ = s.Traverse(ctx, func(_ context.Context, k int, v string) bool {
+		s.entries[k] = v
+		return true
+	})
+	s.Cache.Shutdown(ctx)
+}
+
+func TestRefreshDoesNotOverwriteWrites(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		write func(context.Context, *negative.Cache[int, string]) error
+		want  map[int]string
+	}{
+		{"Put", func(ctx context.Context, c *negative.Cache[int, string]) error {
+			return c.Put(ctx, 1, "put")
+		}, map[int]string{1: "put"}},
+		{"Delete", func(ctx context.Context, c *negative.Cache[int, string]) error {
+			_, err := c.Delete(ctx, 1)
+			return err
+		}, map[int]string{}},
+		{"Reset", func(ctx context.Context, c *negative.Cache[int, string]) error {
+			return c.Reset(ctx)
+		}, map[int]string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			lc, err := lru.New[int, string](cachetypes.WithCapacity(2))
+			require.NoError(t, err)
+			inner := &shutdownSnapshot{Cache: lc}
+			started := make(chan struct{})
+			release := make(chan struct{})
+			var calls atomic.Int32
+			loader := func(context.Context, int) (string, bool, error) {
+				if calls.Add(1) > 1 {
+					close(started)
+					<-release
+				}
+				return "loaded", true, nil
+			}
+			c, err := negative.New(inner, loader,
+				negative.WithTTL(time.Hour),
+				negative.WithRefreshAhead(10*time.Millisecond),
+			)
+			require.NoError(t, err)
+
+			_, _, err = c.Get(ctx, 1)
+			require.NoError(t, err)
+			time.Sleep(20 * time.Millisecond)
+			_, _, err = c.Get(ctx, 1) // starts the refresh
+			require.NoError(t, err)
+			<-started
+
+			// the write lands while the loader runs, and must survive it
+			require.NoError(t, tc.write(ctx, c))
+			close(release)
+			c.Shutdown(ctx) // waits for the refresh
+			require.Equal(t, tc.want, inner.entries)
+		})
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	ctx := context.Background()
+	inner, err := lru.New[int, int32](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	l := &versionLoader{}
+	c, err := negative.New(inner, l.load,
+		negative.WithTTL(time.Hour),
+		negative.WithMaxAge(20*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	v, stale, ok, err := c.GetAllowStale(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, stale)
+	require.Equal(t, int32(1), v)
+
+	// an expired entry is reloaded before Get returns
+	time.Sleep(40 * time.Millisecond)
+	v, ok, err = c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int32(2), v)
+	require.Equal(t, int32(2), l.calls.Load())
+}
+
+func TestGetAllowStale(t *testing.T) {
+	ctx := context.Background()
+	inner, err := lru.New[int, int32](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	l := &versionLoader{}
+	c, err := negative.New(inner, l.load,
+		negative.WithTTL(time.Hour),
+		negative.WithMaxAge(20*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	_, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	time.Sleep(40 * time.Millisecond)
+
+	// while the reload is blocked, the expired value is served as stale
+	// without waiting, and only one reload starts
+	l.gate.Lock()
+	for range 5 {
+		v, stale, ok, err := c.GetAllowStale(ctx, 1)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.True(t, stale)
+		require.Equal(t, int32(1), v)
+	}
+	l.gate.Unlock()
+
+	require.Eventually(t, func() bool {
+		v, stale, _, err := c.GetAllowStale(ctx, 1)
+		return err == nil && !stale && v == 2
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, int32(2), l.calls.Load())
+
+	// without a max age nothing is stale
+	plain, err := lru.New[int, int32](cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	c2, err := negative.New(plain, l.load, negative.WithTTL(time.Hour))
+	require.NoError(t, err)
+	defer c2.Shutdown(ctx)
+	_, stale, ok, err := c2.GetAllowStale(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, stale)
+}
+
+func TestBloomFilter(t *testing.T) {
+	f := negative.NewBloomFilter[int](1000, 0.01)
+	for k := range 1000 {
+		f.Add(k)
+	}
+	for k := range 1000 {
+		require.True(t, f.MightContain(k))
+	}
+	falsePositives := 0
+	for k := 1000; k < 11000; k++ {
+		if f.MightContain(k) {
+			falsePositives++
+		}
+	}
+	// about 1% is expected; allow generous slack for the random seed
+	require.Less(t, falsePositives, 300)
+}
+
+func TestAbsenceFilterSkipsLoader(t *testing.T) {
+	ctx := context.Background()
+	l := &countingLoader{values: map[int]string{1: "one"}, calls: map[int]int{}}
+	f := negative.NewBloomFilter[int](100, 0.001)
+	f.Add(1) // warm-up from the backing store's key set
+	c := newNegative(t, 2, l, negative.WithTTL(time.Hour), negative.WithAbsenceFilter[int](f))
+
+	v, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "one", v)
+
+	_, ok, err = c.Get(ctx, 2)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, map[int]int{1: 1}, l.calls, "the filter rules out key 2")
+
+	// a Put adds the key, so it is loaded again once evicted
+	require.NoError(t, c.Put(ctx, 3, "three"))
+	require.True(t, f.MightContain(3))
+}
+
+func TestAbsenceFilterWrongType(t *testing.T) {
+	inner, err := lru.New[int, string](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	defer inner.Shutdown(context.Background())
+
+	_, err = negative.New(inner, (&countingLoader{}).load, negative.WithTTL(time.Second),
+		negative.WithAbsenceFilter[string](negative.NewBloomFilter[string](10, 0.01)))
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "incorrect type for AbsenceFilter", aerr.Error())
+}
+
+// recordingTracer records span starts and ends, and tags the context so that
+// nesting can be checked.
+type recordingTracer struct {
+	mu     sync.Mutex
+	events []string
+}
+
+type spanKey struct{}
+
+func (r *recordingTracer) StartSpan(ctx context.Context, name string) (context.Context, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if parent, ok := ctx.Value(spanKey{}).(string); ok {
+		name = parent + "/" + name
+	}
+	r.events = append(r.events, "start "+name)
+	return context.WithValue(ctx, spanKey{}, name), func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, "end "+name)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	ctx := context.Background()
+	l := &countingLoader{values: map[int]string{1: "one"}, calls: map[int]int{}}
+	tr := &recordingTracer{}
+	c := newNegative(t, 2, l, negative.WithTTL(time.Hour), negative.WithTracer(tr))
+
+	_, _, err := c.Get(ctx, 1) // miss and load
+	require.NoError(t, err)
+	_, _, err = c.Get(ctx, 1) // hit
+	require.NoError(t, err)
+	require.NoError(t, c.Put(ctx, 2, "two"))
+
+	require.Equal(t, []string{
+		"start cache.Get", "start cache.Get/cache.load", "end cache.Get/cache.load", "end cache.Get",
+		"start cache.Get", "end cache.Get",
+		"start cache.Put", "end cache.Put",
+	}, tr.events)
+}
@@ -0,0 +1,93 @@
+package negative
+
+import (
+	"context"
+	"time"
+)
+
+// defaultMaxTombstones is used when WithMaxTombstones is not set.
+const defaultMaxTombstones = 1024
+
+// Tracer starts a span named name as a child of any span in ctx. It returns
+// the context carrying the new span and a function that ends it. Adapt it to
+// OpenTelemetry or any other tracing library.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, func())
+}
+
+// Options defines the configuration of the negative cache.
+type Options struct {
+	// TTL is how long a miss is remembered. It must be positive.
+	TTL time.Duration
+	// MaxTombstones bounds the number of remembered misses. Tombstones are
+	// kept apart from the wrapped cache so they never displace real entries.
+	MaxTombstones uint
+	// RefreshAhead, when positive, is the age after which a Get hit is
+	// refreshed in the background while the cached value is returned.
+	RefreshAhead time.Duration
+	// MaxAge, when positive, is the age after which an entry is expired:
+	// Get reloads it and GetAllowStale serves it as stale. It must exceed
+	// RefreshAhead.
+	MaxAge time.Duration
+	// AbsenceFilter, when set, is consulted before the loader; keys it
+	// rules out are reported as misses without loading.
+	AbsenceFilter any // Will cast to AbsenceFilter[K] inside Cache
+	// Tracer, when set, wraps Get, Put and loader calls in spans.
+	Tracer Tracer
+}
+
+// WithTTL sets how long a miss is remembered.
+func WithTTL(ttl time.Duration) func(o *Options) {
+	return func(o *Options) {
+		o.TTL = ttl
+	}
+}
+
+// WithMaxTombstones sets the maximum number of remembered misses; the least
+// recently used tombstone is dropped when the limit is reached.
+func WithMaxTombstones(n uint) func(o *Options) {
+	return func(o *Options) {
+		o.MaxTombstones = n
+	}
+}
+
+// WithRefreshAhead makes a Get that hits an entry loaded or stored more than
+// d ago return the cached value immediately and reload it in the background.
+// At most one refresh per key runs at a time.
+func WithRefreshAhead(d time.Duration) func(o *Options) {
+	return func(o *Options) {
+		o.RefreshAhead = d
+	}
+}
+
+// WithMaxAge expires entries loaded or stored more than d ago. Get then
+// blocks on the loader for them, as for a miss, while GetAllowStale returns
+// the expired value flagged as stale and reloads it in the background. When
+// combined with WithRefreshAhead, d must be longer than the refresh age, so
+// that entries that keep being read are refreshed before they expire.
+func WithMaxAge(d time.Duration) func(o *Options) {
+	return func(o *Options) {
+		o.MaxAge = d
+	}
+}
+
+// WithAbsenceFilter makes Get skip the loader for keys the filter, e.g. a
+// BloomFilter populated with the backing store's key set at warm-up, says are
+// definitely absent. A false positive only costs an unnecessary loader call.
+// Keys stored with Put are added to the filter, so that they are loaded
+// again after being evicted.
+func WithAbsenceFilter[K comparable](f AbsenceFilter[K]) func(o *Options) {
+	return func(o *Options) {
+		o.AbsenceFilter = f
+	}
+}
+
+// WithTracer wraps Get, Put and every loader call, including background
+// refreshes, in spans named "cache.Get", "cache.Put" and "cache.load", so a
+// slow request can be attributed to a miss and its load. Without a tracer
+// no spans are started.
+func WithTracer(t Tracer) func(o *Options) {
+	return func(o *Options) {
+		o.Tracer = t
+	}
+}
@@ -0,0 +1,261 @@
+// Package overflow provides a cache decorator that spills entries evicted
+// from a small primary cache into a larger secondary cache and promotes them
+// back on access. It is a simpler alternative to tiered when the secondary
+// should only hold what no longer fits in the primary.
+package overflow
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/lru"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Ensure Cache satisfies iface.Cache at compile time.
+var _ iface.Cache[struct{}, struct{}] = (*Cache[struct{}, struct{}])(nil)
+
+// Cache keeps each key in at most one of its two levels. Entries evicted from
+// the primary are put into the secondary, and a Get that misses the primary
+// but hits the secondary moves the entry back into the primary. A spill whose
+// Put into the secondary fails is dropped. The levels are updated one after
+// the other, not atomically, so a key written concurrently with its spill or
+// promotion may briefly be visible in both.
+type Cache[K comparable, V any] struct {
+	primary   iface.Cache[K, V]
+	secondary iface.Cache[K, V]
+	// closing stops spills once Shutdown starts emptying the primary.
+	closing atomic.Bool
+
+	// mu guards removing and resetting, which keep the entries that Delete,
+	// GetAndDelete and Reset remove from the primary out of the secondary:
+	// only capacity evictions are spilled.
+	mu sync.Mutex
+	// removing counts, per key, the deletes in progress on the primary.
+	removing map[K]int
+	// resetting counts the Resets in progress on the primary.
+	resetting int
+}
+
+// New builds an lru primary from options, with an eviction callback that
+// spills into secondary. A callback set with WithEvictionCB in options is
+// still called, after the spill, for each entry leaving the primary. The
+// spill must run before the primary call that evicted the entry returns, so
+// WithAsyncEvictionCB and WithEvictionChannel are rejected. The Cache takes
+// ownership of secondary: Shutdown shuts down both levels.
+func New[K comparable, V any](secondary iface.Cache[K, V],
+	options ...func(o *cachetypes.Options)) (*Cache[K, V], error) {
+	if secondary == nil {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "secondary cannot be nil",
+		}
+	}
+	var o cachetypes.Options
+	for _, cb := range options {
+		cb(&o)
+	}
+	if o.AsyncEviction || o.EvictionChannel != nil {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "overflow does not support asynchronous eviction",
+		}
+	}
+	var onEvict cachetypes.CBFunc[K, V]
+	if o.OnEvict != nil {
+		cb, ok := o.OnEvict.(cachetypes.CBFunc[K, V])
+		if !ok {
+			return nil, &cachetypes.InvalidOptionsError{
+				Message: "incorrect type for OnEvict",
+			}
+		}
+		onEvict = cb
+	}
+	c := &Cache[K, V]{secondary: secondary, removing: make(map[K]int)}
+	primary, err := lru.New[K, V](append(slices.Clip(options),
+		cachetypes.WithEvictionCB(func(ctx context.Context, key K, value V) {
+			c.spill(ctx, key, value)
+			if onEvict != nil {
+				onEvict(ctx, key, value)
+			}
+		}))...)
+	if err != nil {
+		return nil, err
+	}
+	c.primary = primary
+	return c, nil
+}
+
+// spill is the eviction callback of the primary. It skips the entries that
+// are leaving the primary because they are being deleted or reset rather than
+// evicted for capacity.
+func (c *Cache[K, V]) spill(ctx context.Context, key K, value V) {
+	if c.closing.Load() {
+		return
+	}
+	c.mu.Lock()
+	skip := c.resetting > 0 || c.removing[key] > 0
+	c.mu.Unlock()
+	if skip {
+		return
+	}
+	_ = c.secondary.Put(ctx, key, value)
+}
+
+// primaryGetAndDelete removes the key from the primary without spilling it.
+func (c *Cache[K, V]) primaryGetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	c.removing[key]++
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		if c.removing[key]--; c.removing[key] == 0 {
+			delete(c.removing, key)
+		}
+		c.mu.Unlock()
+	}()
+	return c.primary.GetAndDelete(ctx, key)
+}
+
+// Get returns the value from the primary, or from the secondary on a primary
+// miss. A secondary hit is put into the primary, which may in turn spill
+// another entry, and only then removed from the secondary. If the promotion
+// fails the entry stays in the secondary and Get reports a miss with the
+// error.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	if v, found, err := c.primary.Get(ctx, key); err != nil || found {
+		return v, found, err
+	}
+	v, found, err := c.secondary.Get(ctx, key)
+	if err != nil || !found {
+		return v, found, err
+	}
+	if err := c.primary.Put(ctx, key, v); err != nil {
+		var zero V
+		return zero, false, err
+	}
+	// the value is already in the primary; a copy left behind by a failed
+	// Delete is removed by the next Put or Delete of the key
+	_, _ = c.secondary.Delete(ctx, key)
+	return v, true, nil
+}
+
+// Has reports whether the key is present in either level without promoting it.
+func (c *Cache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	if found, err := c.primary.Has(ctx, key); err != nil || found {
+		return found, err
+	}
+	return c.secondary.Has(ctx, key)
+}
+
+// Put writes the value to the primary and removes any spilled copy of the
+// key from the secondary.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.primary.Put(ctx, key, value); err != nil {
+		return err
+	}
+	_, err := c.secondary.Delete(ctx, key)
+	return err
+}
+
+// Delete removes the key from both levels and reports whether either held it.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete removes the key from both levels and returns its value,
+// preferring the primary copy. The deleted entry is not spilled, so it
+// neither evicts another entry from the secondary nor lands there.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	v1, found1, err1 := c.primaryGetAndDelete(ctx, key)
+	v2, found2, err2 := c.secondary.GetAndDelete(ctx, key)
+	v := v2
+	if found1 {
+		v = v1
+	}
+	if err1 != nil {
+		return v, found1 || found2, err1
+	}
+	return v, found1 || found2, err2
+}
+
+// Replace updates the key in whichever level holds it and returns the
+// previous value, without promoting it.
+func (c *Cache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	if v, found, err := c.primary.Replace(ctx, key, value); err != nil || found {
+		return v, found, err
+	}
+	return c.secondary.Replace(ctx, key, value)
+}
+
+// Size returns the number of entries in both levels.
+func (c *Cache[K, V]) Size() (int, error) {
+	n1, err := c.primary.Size()
+	if err != nil {
+		return 0, err
+	}
+	n2, err := c.secondary.Size()
+	if err != nil {
+		return 0, err
+	}
+	return n1 + n2, nil
+}
+
+// Capacity returns the combined capacity of both levels.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	c1, err := c.primary.Capacity()
+	if err != nil {
+		return 0, err
+	}
+	c2, err := c.secondary.Capacity()
+	if err != nil {
+		return 0, err
+	}
+	return c1 + c2, nil
+}
+
+// Reset clears both levels. Entries removed from the primary are not
+// spilled, and neither are capacity evictions racing with the Reset. The
+// secondary is reset even if resetting the primary fails; the first error is
+// returned.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	c.resetting++
+	c.mu.Unlock()
+	err1 := c.primary.Reset(ctx)
+	c.mu.Lock()
+	c.resetting--
+	c.mu.Unlock()
+	err2 := c.secondary.Reset(ctx)
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// Traverse visits the entries of the primary and then those of the
+// secondary, stopping as soon as fn returns false.
+func (c *Cache[K, V]) Traverse(ctx context.Context, fn func(context.Context, K, V) bool) error {
+	stop := false
+	err := c.primary.Traverse(ctx, func(innerCtx context.Context, k K, v V) bool {
+		if !fn(innerCtx, k, v) {
+			stop = true
+			return false
+		}
+		return true
+	})
+	if err != nil || stop {
+		return err
+	}
+	return c.secondary.Traverse(ctx, fn)
+}
+
+// Shutdown shuts down both levels. Entries left in the primary are not
+// spilled.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.closing.Store(true)
+	c.primary.Shutdown(ctx)
+	c.secondary.Shutdown(ctx)
+}
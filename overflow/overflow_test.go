@@ -0,0 +1,265 @@
+package overflow_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/lru"
+	"github.com/mcphone2004/cache/overflow"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// newCache returns an overflow cache over an lru primary of capacity 2 and an
+// lru secondary of capacity 4, and the secondary.
+func newCache(t *testing.T) (*overflow.Cache[int, string], iface.Cache[int, string]) {
+	t.Helper()
+	secondary, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	c, err := overflow.New(secondary, cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	return c, secondary
+}
+
+func requireHas(t *testing.T, c iface.Cache[int, string], key int, want bool) {
+	t.Helper()
+	found, err := c.Has(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, want, found, "key %d", key)
+}
+
+func TestSpillAndPromote(t *testing.T) {
+	ctx := context.Background()
+	c, secondary := newCache(t)
+	defer c.Shutdown(ctx)
+
+	for k := 1; k <= 3; k++ {
+		require.NoError(t, c.Put(ctx, k, "v"+strconv.Itoa(k)))
+	}
+	// 1 was evicted from the primary and spilled
+	requireHas(t, secondary, 1, true)
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Equal(t, 3, size)
+
+	// a secondary hit moves 1 back, spilling the primary's oldest entry, 2
+	v, found, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "v1", v)
+	requireHas(t, secondary, 1, false)
+	requireHas(t, secondary, 2, true)
+
+	// a Put removes the spilled copy, so each key lives in one level
+	require.NoError(t, c.Put(ctx, 2, "new"))
+	requireHas(t, secondary, 2, false)
+	requireHas(t, secondary, 3, true)
+	size, err = c.Size()
+	require.NoError(t, err)
+	require.Equal(t, 3, size)
+	capacity, err := c.Capacity()
+	require.NoError(t, err)
+	require.Equal(t, 6, capacity)
+}
+
+func TestDeleteRemovesBothLevels(t *testing.T) {
+	ctx := context.Background()
+	c, secondary := newCache(t)
+	defer c.Shutdown(ctx)
+
+	for k := 1; k <= 3; k++ {
+		require.NoError(t, c.Put(ctx, k, "v"))
+	}
+	// deleting from the primary spills nothing
+	found, err := c.Delete(ctx, 3)
+	require.NoError(t, err)
+	require.True(t, found)
+	requireHas(t, c, 3, false)
+	requireHas(t, secondary, 3, false)
+
+	// a spilled key is found and removed from the secondary
+	v, found, err := c.GetAndDelete(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "v", v)
+	requireHas(t, c, 1, false)
+
+	found, err = c.Delete(ctx, 42)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, c.Reset(ctx))
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+}
+
+func TestDeleteDoesNotSpill(t *testing.T) {
+	ctx := context.Background()
+	secondary, err := lru.New[int, string](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	c, err := overflow.New(secondary, cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.Put(ctx, 1, "v"))
+	require.NoError(t, c.Put(ctx, 2, "v"))
+	requireHas(t, secondary, 1, true)
+
+	// deleting 2 from the primary must not push it into the full secondary,
+	// which would evict the unrelated key 1
+	found, err := c.Delete(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, found)
+	requireHas(t, c, 1, true)
+	requireHas(t, c, 2, false)
+}
+
+func TestResetDoesNotSpill(t *testing.T) {
+	ctx := context.Background()
+	var spilled int
+	secondary, err := lru.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(context.Context, int, string) { spilled++ }),
+	)
+	require.NoError(t, err)
+	c, err := overflow.New(secondary, cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.Put(ctx, 1, "v"))
+	require.NoError(t, c.Put(ctx, 2, "v"))
+	require.NoError(t, c.Reset(ctx))
+	// the primary's entries were never put into the secondary
+	require.Zero(t, spilled)
+
+	// spilling resumes once the Reset is done
+	for k := 1; k <= 3; k++ {
+		require.NoError(t, c.Put(ctx, k, "v"))
+	}
+	requireHas(t, secondary, 1, true)
+}
+
+func TestReplaceAndTraverse(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newCache(t)
+	defer c.Shutdown(ctx)
+
+	for k := 1; k <= 3; k++ {
+		require.NoError(t, c.Put(ctx, k, "v"))
+	}
+	// Replace updates a spilled entry in place without promoting it
+	old, found, err := c.Replace(ctx, 1, "one")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "v", old)
+
+	seen := map[int]string{}
+	require.NoError(t, c.Traverse(ctx, func(_ context.Context, k int, v string) bool {
+		seen[k] = v
+		return true
+	}))
+	require.Equal(t, map[int]string{1: "one", 2: "v", 3: "v"}, seen)
+}
+
+func TestShutdownDoesNotSpill(t *testing.T) {
+	ctx := context.Background()
+	var spilled int
+	secondary, err := lru.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(context.Context, int, string) { spilled++ }),
+	)
+	require.NoError(t, err)
+	c, err := overflow.New(secondary, cachetypes.WithCapacity(2))
+	require.NoError(t, err)
+	require.NoError(t, c.Put(ctx, 1, "v"))
+	require.NoError(t, c.Put(ctx, 2, "v"))
+
+	// the primary's entries are dropped rather than spilled
+	c.Shutdown(ctx)
+	require.Zero(t, spilled)
+	_, err = secondary.Size()
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+func TestNewErrors(t *testing.T) {
+	secondary, err := lru.New[int, string](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	defer secondary.Shutdown(context.Background())
+
+	var aerr *cachetypes.InvalidOptionsError
+	_, err = overflow.New[int, string](nil, cachetypes.WithCapacity(1))
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "secondary cannot be nil", aerr.Error())
+
+	_, err = overflow.New[int, string](secondary,
+		cachetypes.WithCapacity(1),
+		cachetypes.WithEvictionCB(func(context.Context, string, string) {}))
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "incorrect type for OnEvict", aerr.Error())
+
+	_, err = overflow.New[int, string](secondary)
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "capacity must be positive", aerr.Error())
+
+	_, err = overflow.New[int, string](secondary,
+		cachetypes.WithCapacity(1),
+		cachetypes.WithAsyncEvictionCB(func(context.Context, int, string) {}, 1))
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "overflow does not support asynchronous eviction", aerr.Error())
+
+	_, err = overflow.New[int, string](secondary,
+		cachetypes.WithCapacity(1),
+		cachetypes.WithEvictionChannel(make(chan cachetypes.Entry[int, string], 1)))
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "overflow does not support asynchronous eviction", aerr.Error())
+}
+
+func TestUserEvictionCBAfterSpill(t *testing.T) {
+	ctx := context.Background()
+	secondary, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	var evicted []int
+	c, err := overflow.New(secondary,
+		cachetypes.WithCapacity(1),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.Put(ctx, 1, "v"))
+	require.NoError(t, c.Put(ctx, 2, "v"))
+	require.Equal(t, []int{1}, evicted)
+	requireHas(t, secondary, 1, true)
+}
+
+func TestGetKeepsEntryWhenPromotionFails(t *testing.T) {
+	ctx := context.Background()
+	secondary, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	c, err := overflow.New(secondary,
+		cachetypes.WithCapacity(2),
+		cachetypes.WithMaxValueBytes(8, func(v string) int { return len(v) }),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	// an entry the primary rejects as too large
+	require.NoError(t, secondary.Put(ctx, 1, "far too large"))
+	v, found, err := c.Get(ctx, 1)
+	var cerr *cachetypes.CapacityExceededError
+	require.ErrorAs(t, err, &cerr)
+	require.False(t, found)
+	require.Empty(t, v)
+	requireHas(t, secondary, 1, true)
+}
@@ -0,0 +1,77 @@
+// Package prommetrics exposes cache statistics as a Prometheus collector. It
+// is a separate module so that the cache packages stay free of the
+// Prometheus client dependency; import it only if you use Prometheus.
+package prommetrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mcphone2004/cache/stats"
+)
+
+// Source is a cache that reports counters and occupancy, such as
+// *stats.Cache.
+type Source interface {
+	Snapshot() stats.Snapshot
+	Size() (int, error)
+	Capacity() (int, error)
+}
+
+// Ensure Collector implements prometheus.Collector.
+var _ prometheus.Collector = (*Collector)(nil)
+
+// Collector reports hits, misses, evictions, size and capacity of one cache,
+// labelled with the cache's name. Values are read from the cache on every
+// scrape.
+type Collector struct {
+	source Source
+
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+	size      *prometheus.Desc
+	capacity  *prometheus.Desc
+}
+
+// NewCollector returns a collector for source. name becomes the value of
+// the cache label, so register one collector per cache with distinct names:
+//
+//	prometheus.MustRegister(prommetrics.NewCollector("users", sc))
+func NewCollector(name string, source Source) *Collector {
+	labels := prometheus.Labels{"cache": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("cache", "", metric), help, nil, labels)
+	}
+	return &Collector{
+		source:    source,
+		hits:      desc("hits_total", "Number of Get calls that found an entry."),
+		misses:    desc("misses_total", "Number of Get calls that found no entry."),
+		evictions: desc("evictions_total", "Number of entries removed by the eviction policy."),
+		size:      desc("size", "Number of entries in the cache."),
+		capacity:  desc("capacity", "Maximum number of entries the cache can hold."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.size
+	ch <- c.capacity
+}
+
+// Collect implements prometheus.Collector. Size and capacity are omitted
+// when the cache returns an error, e.g. after Shutdown.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.source.Snapshot()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions))
+	if size, err := c.source.Size(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(size))
+	}
+	if capacity, err := c.source.Capacity(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(capacity))
+	}
+}
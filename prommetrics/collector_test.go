@@ -0,0 +1,66 @@
+package prommetrics_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/lru"
+	"github.com/mcphone2004/cache/prommetrics"
+	"github.com/mcphone2004/cache/stats"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	var sc stats.Cache[string, int]
+	inner, err := lru.New[string, int](
+		cachetypes.WithCapacity(2),
+		cachetypes.WithEvictionCB(sc.EvictionHook()),
+	)
+	require.NoError(t, err)
+	sc.Wrap(inner)
+
+	for i, k := range []string{"a", "b", "c"} {
+		require.NoError(t, sc.Put(ctx, k, i))
+	}
+	_, _, err = sc.Get(ctx, "c")
+	require.NoError(t, err)
+	_, _, err = sc.Get(ctx, "a")
+	require.NoError(t, err)
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(prommetrics.NewCollector("users", &sc)))
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP cache_capacity Maximum number of entries the cache can hold.
+# TYPE cache_capacity gauge
+cache_capacity{cache="users"} 2
+# HELP cache_evictions_total Number of entries removed by the eviction policy.
+# TYPE cache_evictions_total counter
+cache_evictions_total{cache="users"} 1
+# HELP cache_hits_total Number of Get calls that found an entry.
+# TYPE cache_hits_total counter
+cache_hits_total{cache="users"} 1
+# HELP cache_misses_total Number of Get calls that found no entry.
+# TYPE cache_misses_total counter
+cache_misses_total{cache="users"} 1
+# HELP cache_size Number of entries in the cache.
+# TYPE cache_size gauge
+cache_size{cache="users"} 2
+`)))
+
+	// after Shutdown only the counters are reported
+	sc.Shutdown(ctx)
+	n, err := testutil.GatherAndCount(reg)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+}
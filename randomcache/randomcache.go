@@ -0,0 +1,256 @@
+// Package randomcache provides a fixed-capacity cache that evicts a uniformly
+// random entry to make room. It keeps no recency information, so Get only
+// takes a read lock and never reorders anything. Random eviction does
+// surprisingly well on workloads without strong locality, such as scans and
+// loops slightly larger than the cache, and is a useful baseline for the
+// other policies.
+package randomcache
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Cache is a thread-safe random-eviction cache.
+type Cache[K comparable, V any] struct {
+	mu         sync.RWMutex
+	isShutdown bool
+
+	// items maps each key to its index in entries, so that a random victim
+	// can be picked and removed in O(1) by swapping in the last entry.
+	items    map[K]int
+	entries  []cachetypes.Entry[K, V]
+	capacity int
+	intn     func(n int) int // picks the victim index in [0, n)
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+}
+
+// Ensure Cache implements the Cache interface.
+var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
+
+// New creates a new random-eviction cache with the given capacity.
+func New[K comparable, V any](options ...func(o *cachetypes.Options)) (
+	*Cache[K, V], error) {
+	var o cachetypes.Options
+	for _, cb := range options {
+		cb(&o)
+	}
+
+	o1, err := internal.ToOptions[K, V](o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache[K, V]{
+		items:     make(map[K]int, o1.Capacity),
+		entries:   make([]cachetypes.Entry[K, V], 0, o1.Capacity),
+		capacity:  int(o1.Capacity), //nolint:gosec // capacity is validated positive above
+		intn:      rand.IntN,
+		evictor:   internal.NewEvictor(o1),
+		sizeLimit: o1.SizeLimit,
+	}, nil
+}
+
+// Get retrieves a value from the cache.
+func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var zero V
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	return c.entries[i].Value, true, nil
+}
+
+// Has reports whether the key is present.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.items[key]
+	return ok, nil
+}
+
+// Put inserts or updates a value in the cache. When the cache is full, a new
+// key replaces an entry chosen uniformly at random. It returns
+// cachetypes.ErrEntryTooLarge if the entry exceeds a configured size limit.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	if i, ok := c.items[key]; ok {
+		c.entries[i].Value = value
+		c.mu.Unlock()
+		return nil
+	}
+	var evicted cachetypes.Entry[K, V]
+	var hasEvicted bool
+	if len(c.entries) >= c.capacity {
+		evicted = c.remove(c.intn(len(c.entries)))
+		hasEvicted = true
+	}
+	c.items[key] = len(c.entries)
+	c.entries = append(c.entries, cachetypes.Entry[K, V]{Key: key, Value: value})
+	c.mu.Unlock()
+	if hasEvicted {
+		c.evictor.Evict(ctx, evicted.Key, evicted.Value)
+	}
+	return nil
+}
+
+// remove deletes the entry at index i by moving the last entry into its place
+// and returns it. It must be called with the write lock held.
+func (c *Cache[K, V]) remove(i int) cachetypes.Entry[K, V] {
+	en := c.entries[i]
+	delete(c.items, en.Key)
+	last := len(c.entries) - 1
+	if i != last {
+		c.entries[i] = c.entries[last]
+		c.items[c.entries[i].Key] = i
+	}
+	c.entries[last] = cachetypes.Entry[K, V]{} // drop references for the GC
+	c.entries = c.entries[:last]
+	return en
+}
+
+// Replace updates the value of an existing key and returns the previous
+// value. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	old := c.entries[i].Value
+	c.entries[i].Value = value
+	return old, true, nil
+}
+
+// Delete removes the entry with the specified key from the cache.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete atomically removes the entry and returns its value.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	var zero V
+	if c.isShutdown {
+		c.mu.Unlock()
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return zero, false, nil
+	}
+	en := c.remove(i)
+	c.mu.Unlock() // Unlock before callback to avoid deadlock
+	c.evictor.Evict(ctx, en.Key, en.Value)
+	return en.Value, true, nil
+}
+
+// Size returns the current number of items in the cache.
+func (c *Cache[K, V]) Size() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return len(c.entries), nil
+}
+
+// Capacity returns the maximum number of items the cache can hold.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return c.capacity, nil
+}
+
+// Reset clears the cache and calls the eviction callback for each removed item.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := c.entries
+	c.entries = make([]cachetypes.Entry[K, V], 0, c.capacity)
+	clear(c.items)
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	return nil
+}
+
+// Traverse calls fn for each entry, in no particular order, until fn returns
+// false. fn runs on a snapshot taken under the lock, so it may call back into
+// the cache.
+func (c *Cache[K, V]) Traverse(ctx context.Context,
+	fn func(context.Context, K, V) bool) error {
+	c.mu.RLock()
+	if c.isShutdown {
+		c.mu.RUnlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := make([]cachetypes.Entry[K, V], len(c.entries))
+	copy(entries, c.entries)
+	c.mu.RUnlock()
+	for _, en := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(ctx, en.Key, en.Value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Shutdown clears the cache, calling the eviction callback for each item.
+// Every later operation returns ErrShutdown.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return
+	}
+	c.isShutdown = true
+	entries := c.entries
+	c.entries = nil
+	c.items = nil
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	c.evictor.Close(ctx)
+}
@@ -0,0 +1,56 @@
+package randomcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal/testhelper"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// newFirstVictimCache creates a cache that always evicts index 0. Since
+// removal moves the last entry into the freed slot, that is the oldest key
+// while the cache holds at most two entries, which makes the LRU tests that
+// check which key was evicted deterministic.
+func newFirstVictimCache(capacity uint, evictionCB func(context.Context, int, string)) (
+	iface.Cache[int, string], error) {
+	c, err := New[int, string](
+		cachetypes.WithCapacity(capacity),
+		cachetypes.WithEvictionCB(evictionCB),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.intn = func(int) int { return 0 }
+	return c, nil
+}
+
+func TestBasic(t *testing.T) {
+	testhelper.CommonLRUCacheBasicTest(t, newFirstVictimCache)
+}
+
+func TestEvictionCallback(t *testing.T) {
+	testhelper.CommonEvictionCallbackTest(t, newFirstVictimCache)
+}
+
+func TestRemoveKeepsIndex(t *testing.T) {
+	ctx := context.Background()
+	c, err := New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	for k := range 4 {
+		require.NoError(t, c.Put(ctx, k, "v"))
+	}
+	// deleting from the middle moves the last entry into the freed slot
+	found, err := c.Delete(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Len(t, c.entries, 3)
+	for k, i := range c.items {
+		require.Equal(t, k, c.entries[i].Key)
+	}
+}
@@ -0,0 +1,124 @@
+package randomcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal/testhelper"
+	"github.com/mcphone2004/cache/randomcache"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func newCache[K comparable, T any](capacity uint, evictionCB func(context.Context, K, T)) (iface.Cache[K, T], error) {
+	return randomcache.New[K, T](
+		cachetypes.WithCapacity(capacity),
+		cachetypes.WithEvictionCB(evictionCB),
+	)
+}
+
+func TestNewCache(t *testing.T) {
+	cache, err := randomcache.New[int, string]()
+	require.Nil(t, cache)
+	var aerr *cachetypes.InvalidOptionsError
+	require.True(t, errors.As(err, &aerr))
+	require.Equal(t, "capacity must be positive", aerr.Error())
+}
+
+func TestReset(t *testing.T) {
+	testhelper.CommonLRUResetTest(t, newCache)
+}
+
+func TestUpdate(t *testing.T) {
+	testhelper.CommonLRUCacheUpdateTest(t, newCache)
+}
+
+func TestTraverse(t *testing.T) {
+	testhelper.CommonTraverseTest(t, newCache)
+}
+
+func TestTraverseReentrant(t *testing.T) {
+	testhelper.CommonTraverseReentrantTest(t, newCache)
+}
+
+func TestTraverseCancel(t *testing.T) {
+	testhelper.CommonTraverseCancelTest(t, newCache)
+}
+
+func TestDelete(t *testing.T) {
+	testhelper.CommonDeleteTest(t, newCache)
+	testhelper.CommonDeleteNonExistentTest(t, newCache)
+}
+
+func TestGetMultiIter(t *testing.T) {
+	testhelper.CommonGetMultiIterTest(t, newCache)
+}
+
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache)
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache)
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache)
+}
+
+func TestShutdown(t *testing.T) {
+	testhelper.CommonShutdownTest(t, newCache)
+}
+
+func TestEvictionCallbackNoEviction(t *testing.T) {
+	testhelper.CommonUpdateNoEvictionTest(t, newCache)
+	testhelper.CommonEvictionCallbackPanicTest(t, newCache)
+}
+
+func TestConcurrent(t *testing.T) {
+	testhelper.CommonConcurrentTest(t, newCache)
+	testhelper.CommonConcurrentStressTest(t, newCache)
+}
+
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache, testhelper.ExactCapacity)
+}
+
+func TestStressShutdown(t *testing.T) {
+	testhelper.CommonStressShutdownTest(t, newCache[int, string])
+}
+
+func TestRandomEviction(t *testing.T) {
+	ctx := context.Background()
+	evicted := map[int]int{}
+	cache, err := randomcache.New[int, string](
+		cachetypes.WithCapacity(3),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted[k]++
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for k := range 100 {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Equal(t, 3, size)
+	require.Len(t, evicted, 97)
+	for k := range 100 {
+		found, err := cache.Has(ctx, k)
+		require.NoError(t, err)
+		require.Equal(t, evicted[k] == 0, found, "key %d", k)
+		require.LessOrEqual(t, evicted[k], 1)
+	}
+}
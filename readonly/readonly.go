@@ -0,0 +1,87 @@
+// Package readonly provides a read-only view of a cache, e.g. to hand a
+// shared cache to plugins that must not change it.
+package readonly
+
+import (
+	"context"
+
+	"github.com/mcphone2004/cache/iface"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Ensure Cache implements the Cache interface.
+var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
+
+// Cache is a read-only view of another cache. Get, Has, Traverse, Size and
+// Capacity pass through; every operation that would modify the cache returns
+// cachetypes.ErrReadOnly without reaching it.
+//
+// Reads are not side-effect free in every implementation: Get on an LRU cache
+// still marks the entry as recently used. Use Has to check for a key without
+// affecting eviction.
+type Cache[K comparable, V any] struct {
+	inner iface.Cache[K, V]
+}
+
+// Wrap returns a read-only view of c. The view holds no resources of its own.
+func Wrap[K comparable, V any](c iface.Cache[K, V]) *Cache[K, V] {
+	return &Cache[K, V]{inner: c}
+}
+
+// Get retrieves a value from the underlying cache.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	return c.inner.Get(ctx, key)
+}
+
+// Has reports whether the key is present in the underlying cache.
+func (c *Cache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	return c.inner.Has(ctx, key)
+}
+
+// Put returns cachetypes.ErrReadOnly.
+func (c *Cache[K, V]) Put(context.Context, K, V) error {
+	return cachetypes.ErrReadOnly
+}
+
+// Replace returns cachetypes.ErrReadOnly.
+func (c *Cache[K, V]) Replace(context.Context, K, V) (V, bool, error) {
+	var zero V
+	return zero, false, cachetypes.ErrReadOnly
+}
+
+// Delete returns cachetypes.ErrReadOnly.
+func (c *Cache[K, V]) Delete(context.Context, K) (bool, error) {
+	return false, cachetypes.ErrReadOnly
+}
+
+// GetAndDelete returns cachetypes.ErrReadOnly.
+func (c *Cache[K, V]) GetAndDelete(context.Context, K) (V, bool, error) {
+	var zero V
+	return zero, false, cachetypes.ErrReadOnly
+}
+
+// Size returns the number of items in the underlying cache.
+func (c *Cache[K, V]) Size() (int, error) {
+	return c.inner.Size()
+}
+
+// Capacity returns the capacity of the underlying cache.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	return c.inner.Capacity()
+}
+
+// Reset returns cachetypes.ErrReadOnly.
+func (c *Cache[K, V]) Reset(context.Context) error {
+	return cachetypes.ErrReadOnly
+}
+
+// Traverse iterates over the underlying cache.
+func (c *Cache[K, V]) Traverse(ctx context.Context,
+	fn func(context.Context, K, V) bool) error {
+	return c.inner.Traverse(ctx, fn)
+}
+
+// Shutdown does nothing: the view does not own the underlying cache, which
+// must be shut down by its owner. Shutdown has no error result, so the
+// attempt cannot be reported as ErrReadOnly.
+func (c *Cache[K, V]) Shutdown(context.Context) {}
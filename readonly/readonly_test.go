@@ -0,0 +1,86 @@
+package readonly_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/lru"
+	"github.com/mcphone2004/cache/readonly"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestReadsPassThrough(t *testing.T) {
+	ctx := context.Background()
+	c, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.NoError(t, c.Put(ctx, 2, "two"))
+
+	ro := readonly.Wrap[int, string](c)
+	v, found, err := ro.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "one", v)
+	found, err = ro.Has(ctx, 3)
+	require.NoError(t, err)
+	require.False(t, found)
+	size, err := ro.Size()
+	require.NoError(t, err)
+	require.Equal(t, 2, size)
+	capacity, err := ro.Capacity()
+	require.NoError(t, err)
+	require.Equal(t, 4, capacity)
+	seen := map[int]string{}
+	require.NoError(t, ro.Traverse(ctx, func(_ context.Context, k int, v string) bool {
+		seen[k] = v
+		return true
+	}))
+	require.Equal(t, map[int]string{1: "one", 2: "two"}, seen)
+
+	// the view sees later writes made by the owner
+	require.NoError(t, c.Put(ctx, 3, "three"))
+	found, err = ro.Has(ctx, 3)
+	require.NoError(t, err)
+	require.True(t, found)
+}
+
+func TestWritesAreRejected(t *testing.T) {
+	ctx := context.Background()
+	// the mock fails the test if any mutating call reaches it
+	m := iface.NewMockCache[int, string](t)
+	ro := readonly.Wrap[int, string](m)
+
+	require.ErrorIs(t, ro.Put(ctx, 1, "one"), cachetypes.ErrReadOnly)
+	_, _, err := ro.Replace(ctx, 1, "one")
+	require.ErrorIs(t, err, cachetypes.ErrReadOnly)
+	_, err = ro.Delete(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrReadOnly)
+	_, _, err = ro.GetAndDelete(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrReadOnly)
+	require.ErrorIs(t, ro.Reset(ctx), cachetypes.ErrReadOnly)
+	ro.Shutdown(ctx)
+	var target *cachetypes.ReadOnlyError
+	require.ErrorAs(t, ro.Put(ctx, 1, "one"), &target)
+}
+
+func TestErrorsPassThrough(t *testing.T) {
+	ctx := context.Background()
+	c, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	ro := readonly.Wrap[int, string](c)
+	c.Shutdown(ctx)
+
+	_, _, err = ro.Get(ctx, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	_, err = ro.Size()
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
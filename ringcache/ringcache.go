@@ -0,0 +1,313 @@
+// Package ringcache provides a fixed-capacity FIFO cache backed by an array
+// of slots. The slots and the key index are allocated once, sized for the
+// capacity, so Put and Get do not allocate; this suits constrained
+// environments where per-operation allocation is unacceptable. When the
+// cache is full, a new key overwrites the oldest entry, regardless of how
+// often it is read.
+package ringcache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// none marks the end of a chain of slots.
+const none = -1
+
+// slot holds one entry of the cache, or nothing while it is free.
+type slot[K comparable, V any] struct {
+	key   K
+	value V
+	// prev and next link used slots from oldest to newest, and free slots
+	// through next alone.
+	prev, next int
+}
+
+// Cache is a thread-safe FIFO cache over a fixed array of slots.
+//
+// Used slots are chained by index from head, the oldest entry, to tail, the
+// newest; free slots are chained from free. A new key takes a free slot and
+// becomes the tail, and a deleted entry returns its slot to the free chain,
+// so every operation is O(1) however entries are deleted.
+type Cache[K comparable, V any] struct {
+	mu         sync.RWMutex
+	isShutdown bool
+
+	items map[K]int // key to slot index
+	slots []slot[K, V]
+	head  int // slot of the oldest entry, or none
+	tail  int // slot of the newest entry, or none
+	free  int // first free slot, or none
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+}
+
+// Ensure Cache implements the Cache interface.
+var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
+
+// New creates a new ring-buffer cache with the given capacity.
+func New[K comparable, V any](options ...func(o *cachetypes.Options)) (
+	*Cache[K, V], error) {
+	var o cachetypes.Options
+	for _, cb := range options {
+		cb(&o)
+	}
+
+	o1, err := internal.ToOptions[K, V](o)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache[K, V]{
+		items:     make(map[K]int, o1.Capacity),
+		slots:     make([]slot[K, V], o1.Capacity),
+		evictor:   internal.NewEvictor(o1),
+		sizeLimit: o1.SizeLimit,
+	}
+	c.clearSlots()
+	return c, nil
+}
+
+// clearSlots empties every slot and chains them all as free; the caller
+// holds mu or owns c.
+func (c *Cache[K, V]) clearSlots() {
+	for i := range c.slots {
+		c.slots[i] = slot[K, V]{prev: none, next: i + 1}
+	}
+	if n := len(c.slots); n > 0 {
+		c.slots[n-1].next = none
+	}
+	c.head, c.tail, c.free = none, none, 0
+}
+
+// Get retrieves a value from the cache. Reads do not affect eviction order.
+func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var zero V
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	return c.slots[i].value, true, nil
+}
+
+// Has reports whether the key is present.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.items[key]
+	return ok, nil
+}
+
+// Put inserts or updates a value in the cache. Updating a key keeps its
+// position; a new key overwrites the oldest entry when the cache is full,
+// calling the eviction callback for it. It returns
+// cachetypes.ErrEntryTooLarge if the entry exceeds a configured size limit.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	if i, ok := c.items[key]; ok {
+		c.slots[i].value = value
+		c.mu.Unlock()
+		return nil
+	}
+	var evicted slot[K, V]
+	var hasEvicted bool
+	if c.free == none {
+		evicted = c.remove(c.head)
+		hasEvicted = true
+	}
+	i := c.free
+	c.free = c.slots[i].next
+	c.slots[i] = slot[K, V]{key: key, value: value, prev: c.tail, next: none}
+	if c.tail == none {
+		c.head = i
+	} else {
+		c.slots[c.tail].next = i
+	}
+	c.tail = i
+	c.items[key] = i
+	c.mu.Unlock()
+	if hasEvicted {
+		c.evictor.Evict(ctx, evicted.key, evicted.value)
+	}
+	return nil
+}
+
+// remove unlinks slot i, returns its content and puts it on the free chain.
+// It must be called with the write lock held.
+func (c *Cache[K, V]) remove(i int) slot[K, V] {
+	s := c.slots[i]
+	delete(c.items, s.key)
+	if s.prev == none {
+		c.head = s.next
+	} else {
+		c.slots[s.prev].next = s.next
+	}
+	if s.next == none {
+		c.tail = s.prev
+	} else {
+		c.slots[s.next].prev = s.prev
+	}
+	c.slots[i] = slot[K, V]{prev: none, next: c.free}
+	c.free = i
+	return s
+}
+
+// Replace updates the value of an existing key, keeping its position, and
+// returns the previous value. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	old := c.slots[i].value
+	c.slots[i].value = value
+	return old, true, nil
+}
+
+// Delete removes the entry with the specified key from the cache.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete atomically removes the entry and returns its value.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	var zero V
+	if c.isShutdown {
+		c.mu.Unlock()
+		return zero, false, cachetypes.ErrShutdown
+	}
+	i, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return zero, false, nil
+	}
+	s := c.remove(i)
+	c.mu.Unlock() // Unlock before callback to avoid deadlock
+	c.evictor.Evict(ctx, s.key, s.value)
+	return s.value, true, nil
+}
+
+// Size returns the current number of items in the cache.
+func (c *Cache[K, V]) Size() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return len(c.items), nil
+}
+
+// Capacity returns the maximum number of items the cache can hold.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return len(c.slots), nil
+}
+
+// Reset clears the cache and calls the eviction callback for each removed item.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := c.drain()
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	return nil
+}
+
+// drain empties the cache and returns its entries, oldest first; the caller
+// holds mu. The returned slice is the only allocation it makes.
+func (c *Cache[K, V]) drain() []cachetypes.Entry[K, V] {
+	entries := c.oldestFirst()
+	c.clearSlots()
+	clear(c.items)
+	return entries
+}
+
+// oldestFirst copies the entries from oldest to newest; the caller holds mu.
+func (c *Cache[K, V]) oldestFirst() []cachetypes.Entry[K, V] {
+	entries := make([]cachetypes.Entry[K, V], 0, len(c.items))
+	for i := c.head; i != none; i = c.slots[i].next {
+		s := &c.slots[i]
+		entries = append(entries, cachetypes.Entry[K, V]{Key: s.key, Value: s.value})
+	}
+	return entries
+}
+
+// Traverse calls fn for each entry, newest first, until fn returns false.
+// fn runs on a snapshot taken under the lock, so it may call back into the
+// cache.
+func (c *Cache[K, V]) Traverse(ctx context.Context,
+	fn func(context.Context, K, V) bool) error {
+	c.mu.RLock()
+	if c.isShutdown {
+		c.mu.RUnlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := c.oldestFirst()
+	c.mu.RUnlock()
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(ctx, entries[i].Key, entries[i].Value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Shutdown clears the cache, calling the eviction callback for each item.
+// Every later operation returns ErrShutdown.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return
+	}
+	c.isShutdown = true
+	entries := c.drain()
+	c.items = nil
+	c.slots = nil
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	c.evictor.Close(ctx)
+}
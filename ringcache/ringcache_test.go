@@ -0,0 +1,188 @@
+package ringcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal/testhelper"
+	"github.com/mcphone2004/cache/ringcache"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func newCache[K comparable, T any](capacity uint, evictionCB func(context.Context, K, T)) (iface.Cache[K, T], error) {
+	return ringcache.New[K, T](
+		cachetypes.WithCapacity(capacity),
+		cachetypes.WithEvictionCB(evictionCB),
+	)
+}
+
+func TestNewCache(t *testing.T) {
+	cache, err := ringcache.New[int, string]()
+	require.Nil(t, cache)
+	var aerr *cachetypes.InvalidOptionsError
+	require.True(t, errors.As(err, &aerr))
+	require.Equal(t, "capacity must be positive", aerr.Error())
+}
+
+func TestReset(t *testing.T) {
+	testhelper.CommonLRUResetTest(t, newCache)
+}
+
+// TestBasic relies on the oldest entry being evicted first, which FIFO shares
+// with LRU when nothing is read.
+func TestBasic(t *testing.T) {
+	testhelper.CommonLRUCacheBasicTest(t, newCache)
+}
+
+func TestUpdate(t *testing.T) {
+	testhelper.CommonLRUCacheUpdateTest(t, newCache)
+}
+
+func TestTraverse(t *testing.T) {
+	testhelper.CommonTraverseTest(t, newCache)
+}
+
+func TestTraverseReentrant(t *testing.T) {
+	testhelper.CommonTraverseReentrantTest(t, newCache)
+}
+
+func TestTraverseCancel(t *testing.T) {
+	testhelper.CommonTraverseCancelTest(t, newCache)
+}
+
+func TestDelete(t *testing.T) {
+	testhelper.CommonDeleteTest(t, newCache)
+	testhelper.CommonDeleteNonExistentTest(t, newCache)
+}
+
+func TestGetMultiIter(t *testing.T) {
+	testhelper.CommonGetMultiIterTest(t, newCache)
+}
+
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache)
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache)
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache)
+}
+
+func TestShutdown(t *testing.T) {
+	testhelper.CommonShutdownTest(t, newCache)
+}
+
+func TestEvictionCallback(t *testing.T) {
+	testhelper.CommonEvictionCallbackTest(t, newCache)
+	testhelper.CommonUpdateNoEvictionTest(t, newCache)
+	testhelper.CommonEvictionCallbackPanicTest(t, newCache)
+}
+
+func TestConcurrent(t *testing.T) {
+	testhelper.CommonConcurrentTest(t, newCache)
+	testhelper.CommonConcurrentStressTest(t, newCache)
+}
+
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache, testhelper.ExactCapacity)
+}
+
+func TestStressShutdown(t *testing.T) {
+	testhelper.CommonStressShutdownTest(t, newCache[int, string])
+}
+
+func TestFIFOOrder(t *testing.T) {
+	ctx := context.Background()
+	var evicted []int
+	cache, err := ringcache.New[int, string](
+		cachetypes.WithCapacity(3),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for k := 1; k <= 3; k++ {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	// reads and updates do not move an entry: 1 is still the oldest
+	_, _, err = cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 1, "w"))
+	require.NoError(t, cache.Put(ctx, 4, "v"))
+	require.NoError(t, cache.Put(ctx, 5, "v"))
+	require.Equal(t, []int{1, 2}, evicted)
+
+	var keys []int
+	require.NoError(t, cache.Traverse(ctx, func(_ context.Context, k int, _ string) bool {
+		keys = append(keys, k)
+		return true
+	}))
+	require.Equal(t, []int{5, 4, 3}, keys)
+}
+
+func TestDeleteLeavesRoom(t *testing.T) {
+	ctx := context.Background()
+	var evicted []int
+	cache, err := ringcache.New[int, string](
+		cachetypes.WithCapacity(4),
+		cachetypes.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for k := 1; k <= 4; k++ {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	// slots freed in the middle are reused before anything is evicted
+	for _, k := range []int{2, 3} {
+		found, err := cache.Delete(ctx, k)
+		require.NoError(t, err)
+		require.True(t, found)
+	}
+	evicted = nil
+	require.NoError(t, cache.Put(ctx, 5, "v"))
+	require.NoError(t, cache.Put(ctx, 6, "v"))
+	require.Empty(t, evicted)
+
+	size, err := cache.Size()
+	require.NoError(t, err)
+	require.Equal(t, 4, size)
+
+	// the survivors keep their order
+	for k := 7; k <= 10; k++ {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	require.Equal(t, []int{1, 4, 5, 6}, evicted)
+}
+
+func TestPutDoesNotAllocate(t *testing.T) {
+	ctx := context.Background()
+	const capacity = 64
+	cache, err := ringcache.New[int, string](cachetypes.WithCapacity(capacity))
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	k := 0
+	allocs := testing.AllocsPerRun(1000, func() {
+		_ = cache.Put(ctx, k, "v")
+		_, _, _ = cache.Get(ctx, k-capacity/2)
+		k++
+	})
+	require.Zero(t, allocs)
+}
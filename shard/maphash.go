@@ -0,0 +1,32 @@
+package shard
+
+import "hash/maphash"
+
+// processSeed is the random seed used by WithMapHash. It is chosen once per
+// process, so shard placement is stable for the life of the process but
+// cannot be predicted from outside it.
+var processSeed = maphash.MakeSeed()
+
+// MapHashShardsFn returns a ShardsFn that hashes keys with hash/maphash using
+// seed. Unlike a fixed hash such as FNV, the placement of keys depends on the
+// seed, so when the seed is kept secret an attacker who controls the keys
+// cannot choose them to pile onto one shard. Keys must be comparable at run
+// time: an interface key holding a slice, map or func panics.
+func MapHashShardsFn[K comparable](seed maphash.Seed) func(K, uint) uint {
+	return func(k K, maxShards uint) uint {
+		return uint(maphash.Comparable(seed, k) % uint64(maxShards))
+	}
+}
+
+// WithMapHash selects shards with MapHashShardsFn and a random per-process
+// seed. It replaces any ShardsFn set before it.
+func WithMapHash[K comparable, V any]() func(o *Options[K, V]) {
+	return WithShardsFn[K, V](MapHashShardsFn[K](processSeed))
+}
+
+// WithHashSeed selects shards with MapHashShardsFn and the given seed, e.g. a
+// secret shared by all replicas of a deployment, or a fixed seed for
+// reproducible placement in tests. It replaces any ShardsFn set before it.
+func WithHashSeed[K comparable, V any](seed maphash.Seed) func(o *Options[K, V]) {
+	return WithShardsFn[K, V](MapHashShardsFn[K](seed))
+}
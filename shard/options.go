@@ -1,8 +1,12 @@
 package shard
 
 import (
+	"cmp"
+	"fmt"
 	"math/bits"
 	"runtime"
+	"slices"
+	"time"
 
 	"github.com/mcphone2004/cache/iface"
 	cachetypes "github.com/mcphone2004/cache/types"
@@ -22,13 +26,40 @@ type Options[K comparable, V any] struct {
 	ShardsFn func(K, uint) uint
 	// CacherMaker is a function that creates a new cache for each shard.
 	CacherMaker func(uint) (iface.Cache[K, V], error)
+	// CacherMakerIndexed is like CacherMaker but also receives the shard
+	// index. Exactly one of CacherMaker and CacherMakerIndexed must be set.
+	CacherMakerIndexed func(shardIndex, capacity uint) (iface.Cache[K, V], error)
+	// ConsistentHashReplicas enables consistent-hashing shard selection with
+	// this many virtual nodes per shard. It replaces ShardsFn when positive.
+	ConsistentHashReplicas int
+	// ShardWeights distributes Capacity across shards in proportion to the
+	// weights instead of evenly. Its length must equal the shard count.
+	ShardWeights []uint
+	// ShardCount fixes the number of shards, rounded up to a power of two.
+	// When set it takes precedence over MinShards and TargetPerShard.
+	ShardCount uint
+	// ExactShardCount keeps ShardCount or MinShards as given instead of
+	// rounding up to a power of two, and indexes shards with a modulo.
+	ExactShardCount bool
+	// SlowCallThreshold times every single-key call into a shard and
+	// counts those slower than it as slow; 0 disables the timing.
+	SlowCallThreshold time.Duration
+	// BalanceCheckKeys, when not empty, are run through the shard function
+	// by New, which fails if they pile up on one shard.
+	BalanceCheckKeys []K
+
+	// shardCountSet records that WithShardCount was applied, so that an
+	// explicit zero is rejected rather than treated as unset.
+	shardCountSet bool
 }
 
 // options is the internal representation of the sharded cache options.
 type options[K comparable, V any] struct {
 	maxShards   uint
 	shardsFn    func(K) uint
-	cacherMaker func() (iface.Cache[K, V], error)
+	cacherMaker func(index uint) (iface.Cache[K, V], error)
+
+	slowCallThreshold time.Duration
 }
 
 // WithCapacity sets the maximum capacity of each shard in the cache.
@@ -59,6 +90,185 @@ func WithCacherMaker[K comparable, V any](cacherMaker func(uint) (iface.Cache[K,
 	}
 }
 
+// WithCacherMakerIndexed sets the function that creates the cache for each
+// shard from its index, in [0, shard count), and its capacity, so shards can
+// be configured differently, e.g. with different TTLs or cache types. It
+// replaces WithCacherMaker; setting both is an error.
+func WithCacherMakerIndexed[K comparable, V any](
+	cacherMaker func(shardIndex, capacity uint) (iface.Cache[K, V], error)) func(o *Options[K, V]) {
+	return func(o *Options[K, V]) {
+		o.CacherMakerIndexed = cacherMaker
+	}
+}
+
+// WithConsistentHash selects shards with an FNV hash ring holding replicas
+// virtual nodes per shard, instead of ShardsFn. Changing the shard count then
+// remaps only a fraction of the keys rather than almost all of them. More
+// replicas spread keys more evenly at the cost of a larger ring.
+func WithConsistentHash[K comparable, V any](replicas int) func(o *Options[K, V]) {
+	return func(o *Options[K, V]) {
+		o.ConsistentHashReplicas = replicas
+	}
+}
+
+// WithShardWeights gives each shard a capacity proportional to its weight, so
+// shards that receive more keys can be given more room. The number of weights
+// must match the shard count, which is a power of two unless
+// WithExactShardCount is used; pin it with WithShardCount. The shares add up
+// to exactly the capacity, except that every shard gets at least one entry.
+func WithShardWeights[K comparable, V any](weights []uint) func(o *Options[K, V]) {
+	return func(o *Options[K, V]) {
+		o.ShardWeights = weights
+	}
+}
+
+// WithShardCount sets the number of shards directly, bypassing the heuristics
+// in ComputeMaxshards. The count is rounded up to a power of two and must be
+// positive. Precedence, highest first: WithShardCount, WithMinShards, then the
+// default derived from TargetPerShard, capacity and CPU count. Combine it with
+// WithExactShardCount to skip the rounding.
+func WithShardCount[K comparable, V any](n uint) func(o *Options[K, V]) {
+	return func(o *Options[K, V]) {
+		o.ShardCount = n
+		o.shardCountSet = true
+	}
+}
+
+// WithExactShardCount uses the shard count from WithShardCount or
+// WithMinShards as is, e.g. exactly 10 shards rather than 16. Shards are then
+// selected with a modulo instead of the cheaper power-of-two mask, so only use
+// it when the memory saved matters more than the extra division per call.
+func WithExactShardCount[K comparable, V any]() func(o *Options[K, V]) {
+	return func(o *Options[K, V]) {
+		o.ExactShardCount = true
+	}
+}
+
+// WithSlowCallMetrics times every single-key call into a shard and counts
+// the calls that take longer than threshold, reporting them per shard in
+// ShardStats. The wrapper cannot see a shard's lock, so it measures the
+// latency of the whole call, including any loader or inline eviction callback
+// it runs; pick a threshold well above the latency of an uncontended call to
+// the shard cache, e.g. a few microseconds for lru. Timing costs two clock
+// reads per call, which is why it is off by default.
+func WithSlowCallMetrics[K comparable, V any](threshold time.Duration) func(o *Options[K, V]) {
+	return func(o *Options[K, V]) {
+		o.SlowCallThreshold = threshold
+	}
+}
+
+// WithShardBalanceCheck makes New map sampleKeys, e.g. a slice of real keys,
+// to shards and fail with an InvalidOptionsError if the distribution is
+// pathologically skewed, catching a broken ShardsFn before it creates a hot
+// shard in production. The check costs one shard lookup per sample key, so
+// it is opt-in. The keys should be distinct and several times more numerous
+// than the shards; the check allows the busiest shard up to four times its
+// even share, and at most half of the samples beyond that share, so only
+// gross skew is rejected.
+func WithShardBalanceCheck[K comparable, V any](sampleKeys []K) func(o *Options[K, V]) {
+	return func(o *Options[K, V]) {
+		o.BalanceCheckKeys = sampleKeys
+	}
+}
+
+// checkBalance reports an InvalidOptionsError if keys are skewed across the
+// shards chosen by shardsFn; see WithShardBalanceCheck.
+func checkBalance[K comparable](keys []K, maxShards uint, shardsFn func(K) uint) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	counts := make([]int, maxShards)
+	for _, k := range keys {
+		counts[shardsFn(k)]++
+	}
+	busiest := 0
+	for i, n := range counts {
+		if n > counts[busiest] {
+			busiest = i
+		}
+	}
+	n := len(keys)
+	even := (n + int(maxShards) - 1) / int(maxShards) //nolint:gosec // shard counts are small
+	limit := min(4*even, (even+n)/2)
+	if counts[busiest] > limit {
+		return &cachetypes.InvalidOptionsError{
+			Message: fmt.Sprintf("shardsFn is skewed: %d of %d sample keys map to shard %d of %d",
+				counts[busiest], n, busiest, maxShards),
+		}
+	}
+	return nil
+}
+
+// shardCapacities splits capacity across the shards, evenly or by weight. An
+// even split rounds up to a whole number of entries per shard. Weighted shares
+// add up to exactly capacity: each shard gets the floor of its share, and the
+// entries left over go to the shards with the largest remainders. A shard
+// whose share rounds to zero still gets one entry, taken from the largest
+// shard while that has more than one.
+func shardCapacities(capacity, maxShards uint, weights []uint) ([]uint, error) {
+	caps := make([]uint, maxShards)
+	if weights == nil {
+		perShard := (capacity + maxShards - 1) / maxShards
+		for i := range caps {
+			caps[i] = perShard
+		}
+		return caps, nil
+	}
+	if uint(len(weights)) != maxShards {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: fmt.Sprintf("shard weights has %d entries but the cache has %d shards",
+				len(weights), maxShards),
+		}
+	}
+	var total uint
+	for _, w := range weights {
+		if w == 0 {
+			return nil, &cachetypes.InvalidOptionsError{
+				Message: "shard weights must be positive",
+			}
+		}
+		total += w
+	}
+	rems := make([]uint, maxShards)
+	left := capacity
+	for i, w := range weights {
+		caps[i] = capacity * w / total
+		rems[i] = capacity * w % total
+		left -= caps[i]
+	}
+	order := make([]int, maxShards)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(rems[b], rems[a])
+	})
+	for _, i := range order[:left] {
+		caps[i]++
+	}
+	for i := range caps {
+		if caps[i] > 0 {
+			continue
+		}
+		caps[i] = 1
+		if j := largest(caps); caps[j] > 1 {
+			caps[j]--
+		}
+	}
+	return caps, nil
+}
+
+// largest returns the index of the first largest value in caps.
+func largest(caps []uint) int {
+	j := 0
+	for i, c := range caps {
+		if c > caps[j] {
+			j = i
+		}
+	}
+	return j
+}
+
 // helper to round up to the next power of two
 func nextPowerOfTwo(n uint) uint {
 	if n <= 1 {
@@ -117,26 +327,74 @@ func toOptions[K comparable, V any](o Options[K, V]) (options[K, V], error) {
 		return opt, &cachetypes.InvalidOptionsError{
 			Message: "capacity must be positive",
 		}
-	case o.ShardsFn == nil:
+	case o.ConsistentHashReplicas < 0:
+		return opt, &cachetypes.InvalidOptionsError{
+			Message: "consistent hash replicas cannot be negative",
+		}
+	case o.ShardsFn == nil && o.ConsistentHashReplicas == 0:
 		return opt, &cachetypes.InvalidOptionsError{
 			Message: "shardsFn cannot be nil",
 		}
-	case o.CacherMaker == nil:
+	case o.CacherMaker == nil && o.CacherMakerIndexed == nil:
 		return opt, &cachetypes.InvalidOptionsError{
 			Message: "cacherMaker cannot be nil",
 		}
+	case o.CacherMaker != nil && o.CacherMakerIndexed != nil:
+		return opt, &cachetypes.InvalidOptionsError{
+			Message: "cacherMaker and cacherMakerIndexed are mutually exclusive",
+		}
+	case o.shardCountSet && o.ShardCount == 0:
+		return opt, &cachetypes.InvalidOptionsError{
+			Message: "shard count must be positive",
+		}
+	case o.SlowCallThreshold < 0:
+		return opt, &cachetypes.InvalidOptionsError{
+			Message: "slow call threshold cannot be negative",
+		}
 	}
+	opt.slowCallThreshold = o.SlowCallThreshold
 
-	// Compute the maximum number of shards based on capacity, target items per shard, and minimum shards
-	opt.maxShards = ComputeMaxshards(o.Capacity, o.TargetPerShard, o.MinShards)
+	switch {
+	case o.ExactShardCount && o.ShardCount > 0:
+		opt.maxShards = o.ShardCount
+	case o.ExactShardCount && o.MinShards > 0:
+		opt.maxShards = o.MinShards
+	case o.ShardCount > 0:
+		opt.maxShards = nextPowerOfTwo(o.ShardCount)
+	default:
+		// Compute the maximum number of shards based on capacity, target items per shard, and minimum shards
+		opt.maxShards = ComputeMaxshards(o.Capacity, o.TargetPerShard, o.MinShards)
+	}
 
-	perShardCapacity := (o.Capacity + opt.maxShards - 1) / opt.maxShards
-	mask := opt.maxShards - 1
-	opt.shardsFn = func(k K) uint {
-		return o.ShardsFn(k, opt.maxShards) & mask
+	capacities, err := shardCapacities(o.Capacity, opt.maxShards, o.ShardWeights)
+	if err != nil {
+		return opt, err
 	}
-	opt.cacherMaker = func() (iface.Cache[K, V], error) {
-		return o.CacherMaker(perShardCapacity)
+	maxShards := opt.maxShards
+	mask := maxShards - 1
+	switch {
+	case o.ConsistentHashReplicas > 0:
+		ring := newHashRing(maxShards, o.ConsistentHashReplicas)
+		opt.shardsFn = func(k K) uint {
+			return ring.lookup(hashKey(k))
+		}
+	case maxShards&mask == 0:
+		opt.shardsFn = func(k K) uint {
+			return o.ShardsFn(k, maxShards) & mask
+		}
+	default:
+		opt.shardsFn = func(k K) uint {
+			return o.ShardsFn(k, maxShards) % maxShards
+		}
+	}
+	if err := checkBalance(o.BalanceCheckKeys, maxShards, opt.shardsFn); err != nil {
+		return opt, err
+	}
+	opt.cacherMaker = func(index uint) (iface.Cache[K, V], error) {
+		if o.CacherMakerIndexed != nil {
+			return o.CacherMakerIndexed(index, capacities[index])
+		}
+		return o.CacherMaker(capacities[index])
 	}
 	return opt, nil
 }
@@ -1,7 +1,12 @@
 package shard
 
 import (
+	"errors"
+	"hash/maphash"
+	"slices"
 	"testing"
+
+	cachetypes "github.com/mcphone2004/cache/types"
 )
 
 // isPowerOfTwo reports whether x is a power of two for any unsigned integer type.
@@ -82,3 +87,79 @@ func TestComputeMaxshards_VaryTarget(t *testing.T) {
 		}
 	}
 }
+
+func TestShardCapacities(t *testing.T) {
+	caps, err := shardCapacities(10, 4, nil)
+	if err != nil || !slices.Equal(caps, []uint{3, 3, 3, 3}) {
+		t.Errorf("even split = %v, %v; want [3 3 3 3]", caps, err)
+	}
+
+	// weighted shares add up to the capacity; the leftover entry goes to
+	// the first of the shards with the largest remainder
+	caps, err = shardCapacities(100, 4, []uint{1, 1, 2, 4})
+	if err != nil || !slices.Equal(caps, []uint{13, 12, 25, 50}) {
+		t.Errorf("weighted split = %v, %v; want [13 12 25 50]", caps, err)
+	}
+	caps, err = shardCapacities(10, 3, []uint{1, 1, 1})
+	if err != nil || !slices.Equal(caps, []uint{4, 3, 3}) {
+		t.Errorf("weighted remainder = %v, %v; want [4 3 3]", caps, err)
+	}
+
+	// a small weight still gets room for one entry, taken from the largest
+	caps, err = shardCapacities(2, 2, []uint{1, 1000})
+	if err != nil || !slices.Equal(caps, []uint{1, 1}) {
+		t.Errorf("tiny weight = %v, %v; want [1 1]", caps, err)
+	}
+	caps, err = shardCapacities(100, 3, []uint{1, 1, 1000})
+	if err != nil || !slices.Equal(caps, []uint{1, 1, 98}) {
+		t.Errorf("tiny weights = %v, %v; want [1 1 98]", caps, err)
+	}
+
+	var aerr *cachetypes.InvalidOptionsError
+	if _, err = shardCapacities(10, 4, []uint{1, 2}); !errors.As(err, &aerr) {
+		t.Errorf("length mismatch: got %v, want InvalidOptionsError", err)
+	} else if aerr.Error() != "shard weights has 2 entries but the cache has 4 shards" {
+		t.Errorf("unexpected message %q", aerr.Error())
+	}
+	if _, err = shardCapacities(10, 2, []uint{1, 0}); !errors.As(err, &aerr) {
+		t.Errorf("zero weight: got %v, want InvalidOptionsError", err)
+	}
+}
+
+func TestCheckBalance(t *testing.T) {
+	keys := make([]int, 100)
+	for i := range keys {
+		keys[i] = i
+	}
+	hashFn := MapHashShardsFn[int](maphash.MakeSeed())
+	mod := func(n uint) func(int) uint {
+		return func(k int) uint { return uint(k) % n } //nolint:gosec // test keys are non-negative
+	}
+
+	err := checkBalance(keys, 4, func(int) uint { return 0 })
+	var aerr *cachetypes.InvalidOptionsError
+	if !errors.As(err, &aerr) || aerr.Error() != "shardsFn is skewed: 100 of 100 sample keys map to shard 0 of 4" {
+		t.Fatalf("constant shardsFn: got %v", err)
+	}
+	// two busy shards out of 16 are still skewed
+	if err := checkBalance(keys, 16, mod(2)); !errors.As(err, &aerr) {
+		t.Fatalf("two of 16 shards: got %v", err)
+	}
+
+	for _, c := range []struct {
+		name      string
+		keys      []int
+		maxShards uint
+		shardsFn  func(int) uint
+	}{
+		{"even", keys, 16, mod(16)},
+		{"maphash", keys, 8, func(k int) uint { return hashFn(k, 8) }},
+		{"single shard", keys, 1, func(int) uint { return 0 }},
+		{"no samples", nil, 4, func(int) uint { return 0 }},
+		{"fewer samples than shards", keys[:3], 64, mod(64)},
+	} {
+		if err := checkBalance(c.keys, c.maxShards, c.shardsFn); err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package shard
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+)
+
+// ringPoint is one virtual node on the hash ring.
+type ringPoint struct {
+	hash  uint64
+	shard uint
+}
+
+// hashRing maps keys to shards by consistent hashing, so that changing the
+// number of shards only remaps about 1/n of the keys. It is immutable after
+// construction and safe for concurrent lookups without locking.
+type hashRing struct {
+	points []ringPoint
+}
+
+// newHashRing places replicas virtual nodes for each of the shards on the ring.
+func newHashRing(shards uint, replicas int) *hashRing {
+	points := make([]ringPoint, 0, int(shards)*replicas) //nolint:gosec // shard count is bounded by ComputeMaxshards
+	for s := range shards {
+		for r := range replicas {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(strconv.FormatUint(uint64(s), 10) + "#" + strconv.Itoa(r)))
+			points = append(points, ringPoint{hash: mix64(h.Sum64()), shard: s})
+		}
+	}
+	slices.SortFunc(points, func(a, b ringPoint) int {
+		switch {
+		case a.hash < b.hash:
+			return -1
+		case a.hash > b.hash:
+			return 1
+		}
+		return int(a.shard) - int(b.shard) //nolint:gosec // shard indices are small
+	})
+	return &hashRing{points: points}
+}
+
+// lookup returns the shard owning the first virtual node at or after h.
+func (r *hashRing) lookup(h uint64) uint {
+	i, _ := slices.BinarySearchFunc(r.points, h, func(p ringPoint, t uint64) int {
+		switch {
+		case p.hash < t:
+			return -1
+		case p.hash > t:
+			return 1
+		}
+		return 0
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].shard
+}
+
+// hashKey hashes a key with FNV-1a. Strings and integers are hashed from
+// their bytes; other key types from their fmt %v representation.
+func hashKey[K comparable](k K) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	switch v := any(k).(type) {
+	case string:
+		_, _ = h.Write([]byte(v))
+	case int:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v)) //nolint:gosec // reinterpreting bits for hashing
+		_, _ = h.Write(buf[:])
+	case int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v)) //nolint:gosec // reinterpreting bits for hashing
+		_, _ = h.Write(buf[:])
+	case int32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v)) //nolint:gosec // reinterpreting bits for hashing
+		_, _ = h.Write(buf[:])
+	case uint:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		_, _ = h.Write(buf[:])
+	case uint64:
+		binary.LittleEndian.PutUint64(buf[:], v)
+		_, _ = h.Write(buf[:])
+	case uint32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		_, _ = h.Write(buf[:])
+	default:
+		_, _ = fmt.Fprintf(h, "%v", v)
+	}
+	return mix64(h.Sum64())
+}
+
+// mix64 is the murmur3 finalizer. FNV of short, similar inputs leaves the high
+// bits poorly distributed, which unbalances the ring; mixing fixes that.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
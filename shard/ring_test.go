@@ -0,0 +1,54 @@
+package shard
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashRingRemapFraction(t *testing.T) {
+	const keys = 10000
+	const replicas = 100
+	for _, n := range []uint{4, 8, 16} {
+		before := newHashRing(n, replicas)
+		after := newHashRing(n+1, replicas)
+		moved := 0
+		for k := range keys {
+			h := hashKey(k)
+			from, to := before.lookup(h), after.lookup(h)
+			require.Less(t, from, n)
+			require.Less(t, to, n+1)
+			if from != to {
+				// keys only move to the new shard
+				require.Equal(t, n, to)
+				moved++
+			}
+		}
+		fraction := float64(moved) / keys
+		t.Logf("%d -> %d shards: %.3f of keys remapped", n, n+1, fraction)
+		// ideal is 1/(n+1); modulo hashing would remap about n/(n+1)
+		require.Less(t, fraction, 2.0/float64(n+1))
+	}
+}
+
+func TestHashRingBalance(t *testing.T) {
+	const shards = 8
+	ring := newHashRing(shards, 100)
+	counts := make([]int, shards)
+	for k := range 8000 {
+		counts[ring.lookup(hashKey("key-"+strconv.Itoa(k)))]++
+	}
+	for s, c := range counts {
+		require.Greater(t, c, 500, "shard %d", s)
+		require.Less(t, c, 1500, "shard %d", s)
+	}
+}
+
+func TestHashKeyTypes(t *testing.T) {
+	type point struct{ X, Y int }
+	require.Equal(t, hashKey("a"), hashKey("a"))
+	require.NotEqual(t, hashKey(1), hashKey(2))
+	require.Equal(t, hashKey(point{1, 2}), hashKey(point{1, 2}))
+	require.NotEqual(t, hashKey(point{1, 2}), hashKey(point{2, 1}))
+}
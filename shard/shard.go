@@ -3,7 +3,9 @@ package shard
 
 import (
 	"context"
+	"fmt"
 	"sync/atomic"
+	"time"
 
 	"github.com/mcphone2004/cache/iface"
 	cachetypes "github.com/mcphone2004/cache/types"
@@ -17,10 +19,16 @@ type Cache[K comparable, V any] struct {
 	// so no lock is needed to read from it after New returns.
 	shards   []iface.Cache[K, V]
 	shutdown atomic.Bool
+
+	// callStats holds per-shard counters, or nil when calls are not timed.
+	callStats         []callStats
+	slowCallThreshold time.Duration
 }
 
 var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
 
+var _ iface.Toucher[string] = (*Cache[string, int])(nil)
+
 // New creates a new sharded cache with the specified options.
 func New[K comparable, V any](options ...func(o *Options[K, V])) (*Cache[K, V], error) {
 	var o Options[K, V]
@@ -31,12 +39,20 @@ func New[K comparable, V any](options ...func(o *Options[K, V])) (*Cache[K, V],
 	if err != nil {
 		return nil, err
 	}
-	return newCache(o1.maxShards, o1.shardsFn, o1.cacherMaker)
+	c, err := newCache(o1.maxShards, o1.shardsFn, o1.cacherMaker)
+	if err != nil {
+		return nil, err
+	}
+	if o1.slowCallThreshold > 0 {
+		c.callStats = make([]callStats, c.maxShards)
+		c.slowCallThreshold = o1.slowCallThreshold
+	}
+	return c, nil
 }
 
 // newCache creates a new sharded cache with the specified number of shards and a function
 func newCache[K comparable, V any](maxShards uint, shardsFn func(K) uint,
-	cacherMaker func() (iface.Cache[K, V], error)) (*Cache[K, V], error) {
+	cacherMaker func(uint) (iface.Cache[K, V], error)) (*Cache[K, V], error) {
 
 	switch {
 	case maxShards == 0:
@@ -56,9 +72,13 @@ func newCache[K comparable, V any](maxShards uint, shardsFn func(K) uint,
 	shards := make([]iface.Cache[K, V], maxShards)
 	for i := range maxShards {
 		var err error
-		shards[i], err = cacherMaker()
+		shards[i], err = cacherMaker(i)
 		if err != nil {
-			return nil, err
+			// release the shards created so far, e.g. their expiry goroutines
+			for _, s := range shards[:i] {
+				s.Shutdown(context.Background())
+			}
+			return nil, &cachetypes.ShardError{Index: i, Err: err}
 		}
 	}
 
@@ -70,23 +90,86 @@ func newCache[K comparable, V any](maxShards uint, shardsFn func(K) uint,
 }
 
 // keyToShardIndex calculates the shard index for a given key using the provided shards function.
+// The shards function built by toOptions already reduces the index to the shard count.
 func (c *Cache[K, V]) keyToShardIndex(key K) uint {
 	return c.shardsFn(key)
 }
 
+// ShardIndex returns the index of the shard that holds key, e.g. for logging
+// when diagnosing hot shards. It takes no lock.
+func (c *Cache[K, V]) ShardIndex(key K) uint {
+	return c.keyToShardIndex(key)
+}
+
 // Get retrieves a value from the appropriate shard based on the key.
 func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
-	return c.shards[c.keyToShardIndex(key)].Get(ctx, key)
+	i := c.keyToShardIndex(key)
+	if c.callStats != nil {
+		defer c.callStats[i].observe(time.Now(), c.slowCallThreshold)
+	}
+	return c.shards[i].Get(ctx, key)
 }
 
 // Put stores a value in the appropriate shard based on the key.
 func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
-	return c.shards[c.keyToShardIndex(key)].Put(ctx, key, value)
+	i := c.keyToShardIndex(key)
+	if c.callStats != nil {
+		defer c.callStats[i].observe(time.Now(), c.slowCallThreshold)
+	}
+	return c.shards[i].Put(ctx, key, value)
+}
+
+// Has reports whether the key is present in the appropriate shard.
+func (c *Cache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	i := c.keyToShardIndex(key)
+	if c.callStats != nil {
+		defer c.callStats[i].observe(time.Now(), c.slowCallThreshold)
+	}
+	return c.shards[i].Has(ctx, key)
+}
+
+// Touch marks the key as recently used in the appropriate shard.
+// Shards that do not implement iface.Toucher fall back to Get, which also
+// refreshes recency but reads the value.
+func (c *Cache[K, V]) Touch(ctx context.Context, key K) (bool, error) {
+	i := c.keyToShardIndex(key)
+	if c.callStats != nil {
+		defer c.callStats[i].observe(time.Now(), c.slowCallThreshold)
+	}
+	s := c.shards[i]
+	if t, ok := s.(iface.Toucher[K]); ok {
+		return t.Touch(ctx, key)
+	}
+	_, found, err := s.Get(ctx, key)
+	return found, err
 }
 
 // Delete removes a value from the appropriate shard based on the key.
 func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
-	return c.shards[c.keyToShardIndex(key)].Delete(ctx, key)
+	i := c.keyToShardIndex(key)
+	if c.callStats != nil {
+		defer c.callStats[i].observe(time.Now(), c.slowCallThreshold)
+	}
+	return c.shards[i].Delete(ctx, key)
+}
+
+// GetAndDelete atomically retrieves and removes a value from the appropriate shard.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	i := c.keyToShardIndex(key)
+	if c.callStats != nil {
+		defer c.callStats[i].observe(time.Now(), c.slowCallThreshold)
+	}
+	return c.shards[i].GetAndDelete(ctx, key)
+}
+
+// Replace updates an existing key in the appropriate shard and returns its
+// previous value.
+func (c *Cache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	i := c.keyToShardIndex(key)
+	if c.callStats != nil {
+		defer c.callStats[i].observe(time.Now(), c.slowCallThreshold)
+	}
+	return c.shards[i].Replace(ctx, key, value)
 }
 
 // Reset clears all shards in the cache.
@@ -102,6 +185,21 @@ func (c *Cache[K, V]) Reset(ctx context.Context) error {
 	return nil
 }
 
+// ResetShard clears a single shard, calling its eviction callbacks, and
+// leaves the other shards untouched. It returns an InvalidOptionsError if
+// index is not below the shard count.
+func (c *Cache[K, V]) ResetShard(ctx context.Context, index uint) error {
+	if c.isShutdown() {
+		return cachetypes.ErrShutdown
+	}
+	if index >= c.maxShards {
+		return &cachetypes.InvalidOptionsError{
+			Message: fmt.Sprintf("shard index %d out of range [0, %d)", index, c.maxShards),
+		}
+	}
+	return c.shards[index].Reset(ctx)
+}
+
 func (c *Cache[K, V]) isShutdown() bool {
 	return c.shutdown.Load()
 }
@@ -146,7 +244,44 @@ func (c *Cache[K, V]) Traverse(ctx context.Context, fn func(context.Context, K,
 	return nil
 }
 
-// Size returns the total number of items across all shards.
+// TraverseSnapshot is like Traverse but copies the entries of each shard in
+// turn, then calls fn for that shard's copy outside of any shard, so a slow
+// fn, e.g. one exporting the whole cache to a monitoring sink, cannot block
+// Puts whatever the shard implementation does in its Traverse. The cost is
+// memory for a copy of the largest shard's entries.
+func (c *Cache[K, V]) TraverseSnapshot(ctx context.Context, fn func(context.Context, K, V) bool) error {
+	if c.isShutdown() {
+		return cachetypes.ErrShutdown
+	}
+	var entries []cachetypes.Entry[K, V]
+	collect := func(_ context.Context, k K, v V) bool {
+		entries = append(entries, cachetypes.Entry[K, V]{Key: k, Value: v})
+		return true
+	}
+	for _, shard := range c.shards {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		clear(entries)
+		entries = entries[:0]
+		if err := shard.Traverse(ctx, collect); err != nil {
+			return err
+		}
+		for _, en := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !fn(ctx, en.Key, en.Value) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Size returns the total number of items across all shards. It calls Size on
+// each shard in turn, which for lru and lru2 shards takes no lock, so it never
+// waits for writers; the total is not a snapshot of a single instant.
 func (c *Cache[K, V]) Size() (int, error) {
 	if c.isShutdown() {
 		return 0, cachetypes.ErrShutdown
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"iter"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
@@ -25,7 +26,7 @@ func TestNewCache(t *testing.T) {
 		func(k uint) uint {
 			return k
 		},
-		func() (iface.Cache[uint, string], error) {
+		func(uint) (iface.Cache[uint, string], error) {
 			return &nop.Cache[uint, string]{}, nil
 		})
 	require.Error(t, err)
@@ -36,7 +37,7 @@ func TestNewCache(t *testing.T) {
 	require.Equal(t, "maxShards must be positive", aerr.Error())
 
 	_, err = newCache(1, nil,
-		func() (iface.Cache[uint, string], error) {
+		func(uint) (iface.Cache[uint, string], error) {
 			return &nop.Cache[uint, string]{}, nil
 		})
 	require.Error(t, err)
@@ -60,7 +61,7 @@ func TestNewCache(t *testing.T) {
 		func(k uint) uint {
 			return k
 		},
-		func() (iface.Cache[uint, string], error) {
+		func(uint) (iface.Cache[uint, string], error) {
 			return &nop.Cache[uint, string]{}, nil
 		})
 	require.NoError(t, err)
@@ -74,6 +75,31 @@ func TestNewCache(t *testing.T) {
 	require.Zero(t, size)
 }
 
+func TestNewCacheCacherMakerFails(t *testing.T) {
+	errMake := errors.New("out of memory")
+	var created []*iface.MockCache[uint, string]
+	_, err := newCache(4,
+		func(k uint) uint {
+			return k
+		},
+		func(i uint) (iface.Cache[uint, string], error) {
+			if i == 2 {
+				return nil, errMake
+			}
+			m := iface.NewMockCache[uint, string](t)
+			m.EXPECT().Shutdown(mock.Anything).Return().Once()
+			created = append(created, m)
+			return m, nil
+		})
+	require.ErrorIs(t, err, errMake)
+	var sErr *lrutypes.ShardError
+	require.ErrorAs(t, err, &sErr)
+	require.Equal(t, uint(2), sErr.Index)
+	require.Equal(t, "cannot create shard 2: out of memory", err.Error())
+	// the mocks assert at cleanup that both created shards were shut down
+	require.Len(t, created, 2)
+}
+
 func TestShardCacheWithMocks(t *testing.T) {
 	ctx := context.Background()
 
@@ -106,6 +132,16 @@ func TestShardCacheWithMocks(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, "odd", v)
 
+	// --- Has ---
+	mockShard1.EXPECT().Has(ctx, uint(2)).Return(true, nil).Once()
+	mockShard2.EXPECT().Has(ctx, uint(3)).Return(false, nil).Once()
+	ok, err = cache.Has(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = cache.Has(ctx, 3)
+	require.NoError(t, err)
+	require.False(t, ok)
+
 	// --- Put ---
 	mockShard1.EXPECT().Put(ctx, uint(2), "val2").Once().Return(nil)
 	mockShard2.EXPECT().Put(ctx, uint(3), "val3").Once().Return(nil)
@@ -124,6 +160,17 @@ func TestShardCacheWithMocks(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, found)
 
+	// --- GetAndDelete ---
+	mockShard1.EXPECT().GetAndDelete(ctx, uint(2)).Return("even", true, nil).Once()
+	mockShard2.EXPECT().GetAndDelete(ctx, uint(3)).Return("", false, nil).Once()
+	v, found, err = cache.GetAndDelete(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "even", v)
+	_, found, err = cache.GetAndDelete(ctx, 3)
+	require.NoError(t, err)
+	require.False(t, found)
+
 	// --- Size ---
 	mockShard1.EXPECT().Size().Return(5, nil).Once()
 	mockShard2.EXPECT().Size().Return(7, nil).Once()
@@ -207,6 +254,9 @@ func TestShutdownOpsReturnErrShutdown(t *testing.T) {
 	err = cache.Traverse(ctx, func(_ context.Context, _ uint, _ string) bool { return true })
 	require.ErrorIs(t, err, lrutypes.ErrShutdown)
 
+	err = cache.TraverseSnapshot(ctx, func(_ context.Context, _ uint, _ string) bool { return true })
+	require.ErrorIs(t, err, lrutypes.ErrShutdown)
+
 	size, err := cache.Size()
 	require.Zero(t, size)
 	require.ErrorIs(t, err, lrutypes.ErrShutdown)
@@ -317,3 +367,155 @@ func TestCapacityShardError(t *testing.T) {
 	require.Zero(t, total)
 	require.ErrorIs(t, err, sentinel)
 }
+
+func TestTouchFallsBackToGet(t *testing.T) {
+	ctx := context.Background()
+
+	mockShard := iface.NewMockCache[uint, string](t)
+	cache := &Cache[uint, string]{
+		shardsFn:  func(_ uint) uint { return 0 },
+		maxShards: 1,
+		shards:    []iface.Cache[uint, string]{mockShard},
+	}
+
+	// MockCache does not implement iface.Toucher, so Touch routes through Get
+	mockShard.EXPECT().Get(ctx, uint(1)).Return("one", true, nil).Once()
+	mockShard.EXPECT().Get(ctx, uint(2)).Return("", false, nil).Once()
+
+	found, err := cache.Touch(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	found, err = cache.Touch(ctx, 2)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestResetShard(t *testing.T) {
+	ctx := context.Background()
+	mockShard1 := iface.NewMockCache[uint, string](t)
+	mockShard2 := iface.NewMockCache[uint, string](t)
+	cache := &Cache[uint, string]{
+		shardsFn:  func(k uint) uint { return k % 2 },
+		maxShards: 2,
+		shards:    []iface.Cache[uint, string]{mockShard1, mockShard2},
+	}
+
+	// only the selected shard is reset
+	mockShard2.EXPECT().Reset(ctx).Return(nil).Once()
+	require.NoError(t, cache.ResetShard(ctx, 1))
+
+	err := cache.ResetShard(ctx, 2)
+	var aerr *lrutypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "shard index 2 out of range [0, 2)", aerr.Error())
+
+	mockShard1.EXPECT().Shutdown(ctx).Once()
+	mockShard2.EXPECT().Shutdown(ctx).Once()
+	cache.Shutdown(ctx)
+	require.ErrorIs(t, cache.ResetShard(ctx, 0), lrutypes.ErrShutdown)
+}
+
+func TestShardIndex(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name  string
+		exact bool
+		count uint
+		want  []uint // indices for keys 2, 3, 4 and 9
+	}{
+		{name: "mask", count: 4, want: []uint{2, 3, 0, 1}},
+		{name: "modulo", exact: true, count: 3, want: []uint{2, 0, 1, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []func(*Options[uint, string]){
+				WithCapacity[uint, string](12),
+				WithShardCount[uint, string](tc.count),
+				// may exceed the shard count
+				WithShardsFn[uint, string](func(k uint, _ uint) uint { return k }),
+				WithCacherMaker(func(uint) (iface.Cache[uint, string], error) {
+					return &nop.Cache[uint, string]{}, nil
+				}),
+			}
+			if tc.exact {
+				opts = append(opts, WithExactShardCount[uint, string]())
+			}
+			cache, err := New(opts...)
+			require.NoError(t, err)
+			defer cache.Shutdown(ctx)
+
+			for i, k := range []uint{2, 3, 4, 9} {
+				require.Equal(t, tc.want[i], cache.ShardIndex(k), "key %d", k)
+			}
+		})
+	}
+}
+
+func TestTraverseSnapshotOutsideShardLock(t *testing.T) {
+	ctx := context.Background()
+
+	mockShard1 := iface.NewMockCache[uint, string](t)
+	mockShard2 := iface.NewMockCache[uint, string](t)
+
+	cache := &Cache[uint, string]{
+		shardsFn:  func(k uint) uint { return k % 2 },
+		maxShards: 2,
+		shards:    []iface.Cache[uint, string]{mockShard1, mockShard2},
+	}
+
+	// each shard calls fn while holding its lock, unlike the built-in caches
+	var mu sync.Mutex
+	traverseLocked := func(k uint, v string) func(context.Context, func(context.Context, uint, string) bool) error {
+		return func(_ context.Context, fn func(context.Context, uint, string) bool) error {
+			mu.Lock()
+			defer mu.Unlock()
+			fn(ctx, k, v)
+			return nil
+		}
+	}
+	mockShard1.EXPECT().Traverse(ctx,
+		mock.AnythingOfType("func(context.Context, uint, string) bool")).
+		RunAndReturn(traverseLocked(0, "zero")).Once()
+	mockShard2.EXPECT().Traverse(ctx,
+		mock.AnythingOfType("func(context.Context, uint, string) bool")).
+		RunAndReturn(traverseLocked(1, "one")).Once()
+
+	got := map[uint]string{}
+	err := cache.TraverseSnapshot(ctx, func(_ context.Context, k uint, v string) bool {
+		require.True(t, mu.TryLock(), "fn must run outside the shard lock")
+		mu.Unlock()
+		got[k] = v
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[uint]string{0: "zero", 1: "one"}, got)
+}
+
+func TestTraverseSnapshotStopSkipsLaterShards(t *testing.T) {
+	ctx := context.Background()
+
+	mockShard1 := iface.NewMockCache[uint, string](t)
+	mockShard2 := iface.NewMockCache[uint, string](t)
+
+	cache := &Cache[uint, string]{
+		shardsFn:  func(k uint) uint { return k % 2 },
+		maxShards: 2,
+		shards:    []iface.Cache[uint, string]{mockShard1, mockShard2},
+	}
+
+	// the second shard is never copied once fn stops in the first
+	mockShard1.EXPECT().Traverse(ctx,
+		mock.AnythingOfType("func(context.Context, uint, string) bool")).
+		RunAndReturn(func(_ context.Context, fn func(context.Context, uint, string) bool) error {
+			fn(ctx, 0, "zero")
+			fn(ctx, 2, "two")
+			return nil
+		}).Once()
+
+	var got []uint
+	err := cache.TraverseSnapshot(ctx, func(_ context.Context, k uint, _ string) bool {
+		got = append(got, k)
+		return false
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint{0}, got)
+}
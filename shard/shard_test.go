@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"hash/maphash"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -70,6 +73,18 @@ func TestGetMultiIter(t *testing.T) {
 	testhelper.CommonGetMultiIterTest(t, newCache)
 }
 
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache)
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache)
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache)
+}
+
 func TestNew_ErrorPaths(t *testing.T) {
 	ctx := context.Background()
 
@@ -138,6 +153,387 @@ func TestTraverseCancel(t *testing.T) {
 	testhelper.CommonTraverseCancelTest(t, newCache)
 }
 
+func TestConcurrentStress(t *testing.T) {
+	testhelper.CommonConcurrentStressTest(t, newCache)
+}
+
+// TestCapacity checks that capacity is rounded up to a whole number of
+// entries per shard across the power-of-two shard count.
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache, func(requested uint) int {
+		shards := shard.ComputeMaxshards(requested, 0, 0)
+		perShard := (requested + shards - 1) / shards
+		return int(perShard * shards) //nolint:gosec // test capacities are small
+	})
+}
+
 func TestStressShutdown(t *testing.T) {
 	testhelper.CommonStressShutdownTest(t, newCache[int, string])
 }
+
+func TestTouch(t *testing.T) {
+	ctx := context.Background()
+	c, err := newCache[int, string](4, nil)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+	sc := c.(*shard.Cache[int, string]) //nolint:forcetypeassert // newCache always returns *shard.Cache
+
+	require.NoError(t, sc.Put(ctx, 1, "one"))
+	found, err := sc.Touch(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	found, err = sc.Touch(ctx, 2)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestConsistentHash(t *testing.T) {
+	ctx := context.Background()
+	_, err := shard.New[int, string](
+		shard.WithCapacity[int, string](64),
+		shard.WithConsistentHash[int, string](-1),
+		shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+			return lru.New[int, string](cachetypes.WithCapacity(capacity))
+		}),
+	)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+
+	// no ShardsFn is needed with a hash ring
+	cache, err := shard.New[int, string](
+		shard.WithCapacity[int, string](64),
+		shard.WithMinShards[int, string](4),
+		shard.WithConsistentHash[int, string](50),
+		shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+			return lru.New[int, string](cachetypes.WithCapacity(capacity))
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(ctx)
+
+	for i := range 32 {
+		require.NoError(t, cache.Put(ctx, i, strconv.Itoa(i)))
+	}
+	for i := range 32 {
+		v, ok, err := cache.Get(ctx, i)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, strconv.Itoa(i), v)
+	}
+}
+
+func TestShardWeights(t *testing.T) {
+	var got []uint
+	newWeighted := func(weights []uint) (*shard.Cache[int, string], error) {
+		got = nil
+		return shard.New[int, string](
+			shard.WithCapacity[int, string](80),
+			shard.WithMinShards[int, string](4),
+			shard.WithShardsFn[int, string](func(k int, n uint) uint {
+				return uint(k) % n //nolint:gosec // test keys are non-negative
+			}),
+			shard.WithShardWeights[int, string](weights),
+			shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+				got = append(got, capacity)
+				return lru.New[int, string](cachetypes.WithCapacity(capacity))
+			}),
+		)
+	}
+
+	cache, err := newWeighted([]uint{1, 1, 1, 5})
+	require.NoError(t, err)
+	cache.Shutdown(context.Background())
+	require.Equal(t, []uint{10, 10, 10, 50}, got)
+
+	_, err = newWeighted([]uint{1, 1})
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "shard weights has 2 entries but the cache has 4 shards", aerr.Error())
+}
+
+func TestLoader(t *testing.T) {
+	// each shard fills its own misses from the loader
+	testhelper.CommonLoaderTest(t, func(loader cachetypes.LoaderFunc[int, string]) (
+		iface.Cache[int, string], error) {
+		return shard.New[int, string](
+			shard.WithCapacity[int, string](40),
+			shard.WithShardsFn[int, string](func(k int, n uint) uint {
+				return uint(k) % n //nolint:gosec // wraparound is fine for routing
+			}),
+			shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+				return lru.New[int, string](
+					cachetypes.WithCapacity(capacity),
+					cachetypes.WithLoader(loader))
+			}),
+		)
+	})
+}
+
+func TestCacherMakerIndexed(t *testing.T) {
+	ctx := context.Background()
+	var indexes []uint
+	c, err := shard.New[int, string](
+		shard.WithCapacity[int, string](8),
+		shard.WithShardCount[int, string](4),
+		shard.WithShardsFn[int, string](func(k int, n uint) uint {
+			return uint(k) % n //nolint:gosec // test keys are non-negative
+		}),
+		shard.WithCacherMakerIndexed(func(index, capacity uint) (iface.Cache[int, string], error) {
+			indexes = append(indexes, index)
+			require.EqualValues(t, 2, capacity)
+			// shard 0 holds a single entry, the others their full share
+			if index == 0 {
+				capacity = 1
+			}
+			return lru.New[int, string](cachetypes.WithCapacity(capacity))
+		}),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+	require.Equal(t, []uint{0, 1, 2, 3}, indexes)
+
+	// keys 0 and 4 land in shard 0, keys 1 and 5 in shard 1
+	for _, k := range []int{0, 4, 1, 5} {
+		require.NoError(t, c.Put(ctx, k, "v"))
+	}
+	for k, want := range map[int]bool{0: false, 4: true, 1: true, 5: true} {
+		ok, err := c.Has(ctx, k)
+		require.NoError(t, err)
+		require.Equal(t, want, ok, "key %d", k)
+	}
+
+	_, err = shard.New[int, string](
+		shard.WithCapacity[int, string](8),
+		shard.WithShardsFn[int, string](func(int, uint) uint { return 0 }),
+		shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+			return lru.New[int, string](cachetypes.WithCapacity(capacity))
+		}),
+		shard.WithCacherMakerIndexed(func(_, capacity uint) (iface.Cache[int, string], error) {
+			return lru.New[int, string](cachetypes.WithCapacity(capacity))
+		}),
+	)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "cacherMaker and cacherMakerIndexed are mutually exclusive", aerr.Error())
+}
+
+func TestShardCount(t *testing.T) {
+	newCounted := func(opts ...func(*shard.Options[int, string])) (int, error) {
+		var made int
+		opts = append([]func(*shard.Options[int, string]){
+			shard.WithCapacity[int, string](1000),
+			shard.WithShardsFn[int, string](func(k int, n uint) uint {
+				return uint(k) % n //nolint:gosec // test keys are non-negative
+			}),
+			shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+				made++
+				return lru.New[int, string](cachetypes.WithCapacity(capacity))
+			}),
+		}, opts...)
+		cache, err := shard.New[int, string](opts...)
+		if err != nil {
+			return 0, err
+		}
+		cache.Shutdown(context.Background())
+		return made, nil
+	}
+
+	made, err := newCounted(shard.WithShardCount[int, string](3))
+	require.NoError(t, err)
+	require.Equal(t, 4, made)
+
+	// WithShardCount takes precedence over WithMinShards.
+	made, err = newCounted(
+		shard.WithMinShards[int, string](64),
+		shard.WithShardCount[int, string](2))
+	require.NoError(t, err)
+	require.Equal(t, 2, made)
+
+	_, err = newCounted(shard.WithShardCount[int, string](0))
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "shard count must be positive", aerr.Error())
+}
+
+func TestExactShardCount(t *testing.T) {
+	var made int
+	cache, err := shard.New[int, string](
+		shard.WithCapacity[int, string](100),
+		shard.WithShardCount[int, string](10),
+		shard.WithExactShardCount[int, string](),
+		shard.WithShardsFn[int, string](func(k int, _ uint) uint {
+			return uint(k) //nolint:gosec // test keys are non-negative
+		}),
+		shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+			made++
+			return lru.New[int, string](cachetypes.WithCapacity(capacity))
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Shutdown(context.Background())
+	require.Equal(t, 10, made)
+
+	seen := make(map[uint]bool)
+	for k := range 100 {
+		idx := cache.ShardIndex(k)
+		require.Equal(t, uint(k%10), idx) //nolint:gosec // k is non-negative
+		seen[idx] = true
+	}
+	require.Len(t, seen, 10)
+}
+
+func TestMapHashShardsFn(t *testing.T) {
+	seed := maphash.MakeSeed()
+	fn := shard.MapHashShardsFn[string](seed)
+	same := shard.MapHashShardsFn[string](seed)
+	other := shard.MapHashShardsFn[string](maphash.MakeSeed())
+
+	counts := make([]int, 8)
+	moved := 0
+	for i := range 8000 {
+		k := "key" + strconv.Itoa(i)
+		s := fn(k, 8)
+		require.Less(t, s, uint(8))
+		// the same seed places keys identically
+		require.Equal(t, s, same(k, 8))
+		counts[s]++
+		if other(k, 8) != s {
+			moved++
+		}
+	}
+	for _, n := range counts {
+		require.InDelta(t, 1000, n, 200)
+	}
+	// a different seed places keys differently
+	require.Greater(t, moved, 1000)
+}
+
+func TestWithHashSeed(t *testing.T) {
+	ctx := context.Background()
+	seed := maphash.MakeSeed()
+	for _, option := range []func(*shard.Options[int, string]){
+		shard.WithHashSeed[int, string](seed),
+		shard.WithMapHash[int, string](),
+	} {
+		c, err := shard.New(
+			// room for every key in any one shard, so none is evicted
+			shard.WithCapacity[int, string](128),
+			shard.WithShardCount[int, string](4),
+			option,
+			shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+				return lru.New[int, string](cachetypes.WithCapacity(capacity))
+			}),
+		)
+		require.NoError(t, err)
+		for k := range 32 {
+			require.NoError(t, c.Put(ctx, k, strconv.Itoa(k)))
+		}
+		for k := range 32 {
+			v, found, err := c.Get(ctx, k)
+			require.NoError(t, err)
+			require.True(t, found)
+			require.Equal(t, strconv.Itoa(k), v)
+		}
+		c.Shutdown(ctx)
+	}
+}
+
+// slowGetCache delays every Get, standing in for a shard whose lock is held.
+type slowGetCache struct {
+	iface.Cache[int, string]
+	delay time.Duration
+}
+
+func (c slowGetCache) Get(ctx context.Context, key int) (string, bool, error) {
+	time.Sleep(c.delay)
+	return c.Cache.Get(ctx, key)
+}
+
+func TestSlowCallMetrics(t *testing.T) {
+	ctx := context.Background()
+	newTimed := func(opts ...func(*shard.Options[int, string])) (*shard.Cache[int, string], error) {
+		return shard.New[int, string](append([]func(*shard.Options[int, string]){
+			shard.WithCapacity[int, string](8),
+			shard.WithShardCount[int, string](2),
+			shard.WithShardsFn[int, string](func(k int, n uint) uint {
+				return uint(k) % n //nolint:gosec // test keys are non-negative
+			}),
+			shard.WithCacherMakerIndexed(func(index, capacity uint) (iface.Cache[int, string], error) {
+				c, err := lru.New[int, string](cachetypes.WithCapacity(capacity))
+				if err != nil || index != 0 {
+					return c, err
+				}
+				return slowGetCache{Cache: c, delay: 10 * time.Millisecond}, nil
+			}),
+		}, opts...)...)
+	}
+
+	c, err := newTimed(shard.WithSlowCallMetrics[int, string](2 * time.Millisecond))
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+	// keys 0 and 2 go to the slow shard 0, 1 to shard 1
+	for _, k := range []int{0, 1, 2} {
+		require.NoError(t, c.Put(ctx, k, "v"))
+		_, _, err := c.Get(ctx, k)
+		require.NoError(t, err)
+	}
+	stats, err := c.ShardStats()
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.Equal(t, uint(0), stats[0].Index)
+	require.Equal(t, 2, stats[0].Size)
+	require.Equal(t, uint64(4), stats[0].Ops)
+	require.Equal(t, uint64(2), stats[0].Slow)
+	require.GreaterOrEqual(t, stats[0].SlowTime, 20*time.Millisecond)
+	require.Equal(t, uint(1), stats[1].Index)
+	require.Equal(t, 1, stats[1].Size)
+	require.Equal(t, uint64(2), stats[1].Ops)
+
+	// without the option only sizes are reported
+	plain, err := newTimed()
+	require.NoError(t, err)
+	defer plain.Shutdown(ctx)
+	_, _, err = plain.Get(ctx, 0)
+	require.NoError(t, err)
+	stats, err = plain.ShardStats()
+	require.NoError(t, err)
+	require.Equal(t, []shard.ShardStats{{Index: 0}, {Index: 1}}, stats)
+
+	_, err = newTimed(shard.WithSlowCallMetrics[int, string](-time.Second))
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "slow call threshold cannot be negative", aerr.Error())
+
+	c.Shutdown(ctx)
+	_, err = c.ShardStats()
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+func TestShardBalanceCheck(t *testing.T) {
+	ctx := context.Background()
+	samples := make([]int, 256)
+	for i := range samples {
+		samples[i] = i
+	}
+	newChecked := func(shardsFn func(int, uint) uint) (*shard.Cache[int, string], error) {
+		return shard.New[int, string](
+			shard.WithCapacity[int, string](64),
+			shard.WithShardCount[int, string](8),
+			shard.WithShardsFn[int, string](shardsFn),
+			shard.WithShardBalanceCheck[int, string](samples),
+			shard.WithCacherMaker(func(capacity uint) (iface.Cache[int, string], error) {
+				return lru.New[int, string](cachetypes.WithCapacity(capacity))
+			}),
+		)
+	}
+
+	// a hash that ignores the key puts every sample on one shard
+	_, err := newChecked(func(int, uint) uint { return 3 })
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "shardsFn is skewed: 256 of 256 sample keys map to shard 3 of 8", aerr.Error())
+
+	c, err := newChecked(shard.MapHashShardsFn[int](maphash.MakeSeed()))
+	require.NoError(t, err)
+	c.Shutdown(ctx)
+}
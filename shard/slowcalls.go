@@ -0,0 +1,69 @@
+package shard
+
+import (
+	"sync/atomic"
+	"time"
+
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// ShardStats describes one shard of the cache.
+type ShardStats struct {
+	// Index is the position of the shard, in [0, shard count).
+	Index uint
+	// Size is the number of entries the shard holds.
+	Size int
+	// Ops is the number of timed single-key calls routed to the shard. It
+	// is zero unless WithSlowCallMetrics is used.
+	Ops uint64
+	// Slow is the number of those calls that took longer than the slow call
+	// threshold.
+	Slow uint64
+	// SlowTime is the total time spent in the slow calls.
+	SlowTime time.Duration
+}
+
+// callStats counts slow calls into one shard. Each shard's counters are
+// padded to their own cache lines so that shards do not share them.
+type callStats struct {
+	ops      atomic.Uint64
+	slow     atomic.Uint64
+	slowTime atomic.Int64
+	_        [40]byte
+}
+
+// observe records a call to the shard that began at start.
+func (s *callStats) observe(start time.Time, threshold time.Duration) {
+	s.ops.Add(1)
+	if d := time.Since(start); d > threshold {
+		s.slow.Add(1)
+		s.slowTime.Add(int64(d))
+	}
+}
+
+// ShardStats returns the size of every shard and, with WithSlowCallMetrics,
+// how often calls into it were slow. A call is timed as a whole, so it is
+// slow when it waits for the shard's lock, but also when it runs a loader or
+// an inline eviction callback. A shard whose Slow count stands out points to
+// a hot key or an expensive callback; slow calls spread over all shards
+// suggest increasing the shard count.
+func (c *Cache[K, V]) ShardStats() ([]ShardStats, error) {
+	if c.isShutdown() {
+		return nil, cachetypes.ErrShutdown
+	}
+	stats := make([]ShardStats, len(c.shards))
+	for i, s := range c.shards {
+		size, err := s.Size()
+		if err != nil {
+			return nil, err
+		}
+		stats[i] = ShardStats{Index: uint(i), Size: size} //nolint:gosec // i is a slice index
+		if c.callStats != nil {
+			m := &c.callStats[i]
+			stats[i].Ops = m.ops.Load()
+			stats[i].Slow = m.slow.Load()
+			stats[i].SlowTime = time.Duration(m.slowTime.Load())
+		}
+	}
+	return stats, nil
+}
@@ -130,6 +130,16 @@ func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
 	return v, found, nil
 }
 
+// Has implements [iface.Cache]. It is not counted as a hit or miss since it
+// does not read the value; Errors increments on a non-nil error.
+func (c *Cache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	found, err := c.inner.Has(ctx, key)
+	if err != nil {
+		c.errors.Add(1)
+	}
+	return found, err
+}
+
 // Put implements [iface.Cache]. Increments Puts on success, Errors on failure.
 func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
 	if err := c.inner.Put(ctx, key, value); err != nil {
@@ -154,6 +164,40 @@ func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
 	return found, nil
 }
 
+// GetAndDelete implements [iface.Cache]. A found entry increments both Hits
+// and Deletes; a miss increments Misses. Errors increments on a non-nil error.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	v, found, err := c.inner.GetAndDelete(ctx, key)
+	if err != nil {
+		c.errors.Add(1)
+		return v, false, err
+	}
+	if found {
+		c.hits.Add(1)
+		c.deletes.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return v, found, nil
+}
+
+// Replace implements [iface.Cache]. A replaced entry increments both Hits
+// and Puts; a miss increments Misses. Errors increments on a non-nil error.
+func (c *Cache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	old, found, err := c.inner.Replace(ctx, key, value)
+	if err != nil {
+		c.errors.Add(1)
+		return old, false, err
+	}
+	if found {
+		c.hits.Add(1)
+		c.puts.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return old, found, nil
+}
+
 // Size implements [iface.Cache].
 func (c *Cache[K, V]) Size() (int, error) {
 	return c.inner.Size()
@@ -116,6 +116,69 @@ func TestEvictionHookWith(t *testing.T) {
 	assert.Equal(t, 1, cbCalled)
 }
 
+func TestGetAndDeleteCounting(t *testing.T) {
+	ctx := context.Background()
+	inner := newInner(t)
+	defer inner.Shutdown(ctx)
+	sc := stats.New(inner)
+
+	require.NoError(t, sc.Put(ctx, "a", 1))
+
+	v, found, err := sc.GetAndDelete(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, v)
+
+	_, found, err = sc.GetAndDelete(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	snap := sc.Snapshot()
+	assert.Equal(t, uint64(1), snap.Hits)
+	assert.Equal(t, uint64(1), snap.Misses)
+	assert.Equal(t, uint64(1), snap.Deletes)
+}
+
+func TestReplaceCounting(t *testing.T) {
+	ctx := context.Background()
+	inner := newInner(t)
+	defer inner.Shutdown(ctx)
+	sc := stats.New(inner)
+
+	_, found, err := sc.Replace(ctx, "a", 1)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, sc.Put(ctx, "a", 1))
+	old, found, err := sc.Replace(ctx, "a", 2)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, old)
+
+	snap := sc.Snapshot()
+	assert.Equal(t, uint64(1), snap.Hits)
+	assert.Equal(t, uint64(1), snap.Misses)
+	assert.Equal(t, uint64(2), snap.Puts)
+}
+
+func TestHasNotCounted(t *testing.T) {
+	ctx := context.Background()
+	inner := newInner(t)
+	defer inner.Shutdown(ctx)
+	sc := stats.New(inner)
+
+	require.NoError(t, sc.Put(ctx, "a", 1))
+	found, err := sc.Has(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = sc.Has(ctx, "b")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	snap := sc.Snapshot()
+	assert.Equal(t, uint64(0), snap.Requests())
+}
+
 func TestErrorCounting(t *testing.T) {
 	ctx := context.Background()
 	inner := newInner(t)
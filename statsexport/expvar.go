@@ -0,0 +1,51 @@
+// Package statsexport publishes cache statistics to monitoring systems
+// without adding dependencies to the cache packages.
+package statsexport
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+
+	"github.com/mcphone2004/cache/stats"
+)
+
+// ErrNameTaken is returned when an expvar with the requested name already exists.
+var ErrNameTaken = errors.New("statsexport: expvar name already published")
+
+// Snapshotter is implemented by caches that report counters, such as
+// *stats.Cache.
+type Snapshotter interface {
+	Snapshot() stats.Snapshot
+}
+
+// publishMu serializes the existence check and expvar.Publish, which panics
+// on a duplicate name.
+var publishMu sync.Mutex
+
+// PublishExpvar registers the counters of c under name in expvar, so they
+// are served on /debug/vars. The counters are read on every request. Each
+// cache needs its own name; a name that is already published returns
+// ErrNameTaken. expvar has no way to unpublish, so call it once per cache
+// for the life of the process.
+func PublishExpvar(name string, c Snapshotter) error {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("%w: %q", ErrNameTaken, name)
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		s := c.Snapshot()
+		return map[string]any{
+			"hits":      s.Hits,
+			"misses":    s.Misses,
+			"puts":      s.Puts,
+			"deletes":   s.Deletes,
+			"evictions": s.Evictions,
+			"errors":    s.Errors,
+			"hit_rate":  s.HitRate(),
+		}
+	}))
+	return nil
+}
@@ -0,0 +1,53 @@
+package statsexport_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/lru"
+	"github.com/mcphone2004/cache/stats"
+	"github.com/mcphone2004/cache/statsexport"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestPublishExpvar(t *testing.T) {
+	ctx := context.Background()
+	newStats := func() *stats.Cache[string, int] {
+		inner, err := lru.New[string, int](cachetypes.WithCapacity(4))
+		require.NoError(t, err)
+		sc := stats.New(inner)
+		t.Cleanup(func() { sc.Shutdown(ctx) })
+		return sc
+	}
+	users, sessions := newStats(), newStats()
+	require.NoError(t, statsexport.PublishExpvar("test_users_cache", users))
+	require.NoError(t, statsexport.PublishExpvar("test_sessions_cache", sessions))
+
+	require.NoError(t, users.Put(ctx, "a", 1))
+	_, _, err := users.Get(ctx, "a")
+	require.NoError(t, err)
+	_, _, err = users.Get(ctx, "b")
+	require.NoError(t, err)
+
+	var got map[string]float64
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("test_users_cache").String()), &got))
+	require.Equal(t, map[string]float64{
+		"hits": 1, "misses": 1, "puts": 1, "deletes": 0,
+		"evictions": 0, "errors": 0, "hit_rate": 0.5,
+	}, got)
+
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("test_sessions_cache").String()), &got))
+	require.Zero(t, got["puts"])
+
+	err = statsexport.PublishExpvar("test_users_cache", sessions)
+	require.ErrorIs(t, err, statsexport.ErrNameTaken)
+}
@@ -0,0 +1,221 @@
+// Package syncmapcache provides an unbounded cache backed by sync.Map. Like
+// mapcache it never evicts on Put, but reads take no lock at all, so it suits
+// read-mostly data with very high read concurrency and little write churn.
+package syncmapcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// box holds a value so that entries can be compared by pointer in
+// CompareAndSwap even when V is not comparable.
+type box[V any] struct {
+	v V
+}
+
+// Cache is a thread-safe sync.Map-backed cache without eviction.
+//
+// Operations racing with Shutdown may still take effect, and entries they
+// add are dropped without an eviction callback.
+type Cache[K comparable, V any] struct {
+	items    sync.Map // K -> *box[V]
+	size     atomic.Int64
+	shutdown atomic.Bool
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+}
+
+// Ensure Cache implements the Cache interface.
+var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
+
+// New creates a new sync.Map-backed cache. Capacity must not be set; the
+// eviction options apply to entries removed by Delete, GetAndDelete, Reset
+// and Shutdown.
+func New[K comparable, V any](options ...func(o *cachetypes.Options)) (
+	*Cache[K, V], error) {
+	var o cachetypes.Options
+	for _, cb := range options {
+		cb(&o)
+	}
+
+	o1, err := internal.ToUnboundedOptions[K, V](o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache[K, V]{
+		evictor:   internal.NewEvictor(o1),
+		sizeLimit: o1.SizeLimit,
+	}, nil
+}
+
+// load returns the value stored for key.
+func (c *Cache[K, V]) load(key K) (*box[V], bool) {
+	b, ok := c.items.Load(key)
+	if !ok {
+		return nil, false
+	}
+	return b.(*box[V]), true //nolint:forcetypeassert // items only holds *box[V]
+}
+
+// Get retrieves a value from the cache.
+func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	var zero V
+	if c.shutdown.Load() {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	b, ok := c.load(key)
+	if !ok {
+		return zero, false, nil
+	}
+	return b.v, true, nil
+}
+
+// Has reports whether the key is present.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	if c.shutdown.Load() {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.items.Load(key)
+	return ok, nil
+}
+
+// Put inserts or updates a value in the cache. It never evicts. It returns
+// cachetypes.ErrEntryTooLarge if the entry exceeds a configured size limit.
+func (c *Cache[K, V]) Put(_ context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
+	if c.shutdown.Load() {
+		return cachetypes.ErrShutdown
+	}
+	if _, loaded := c.items.Swap(key, &box[V]{v: value}); !loaded {
+		c.size.Add(1)
+	}
+	return nil
+}
+
+// Replace updates the value of an existing key and returns the previous
+// value. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	if c.shutdown.Load() {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	next := &box[V]{v: value}
+	for {
+		cur, ok := c.load(key)
+		if !ok {
+			return zero, false, nil
+		}
+		// retry if another writer changed or deleted the entry meanwhile
+		if c.items.CompareAndSwap(key, cur, next) {
+			return cur.v, true, nil
+		}
+	}
+}
+
+// Delete removes the entry with the specified key from the cache.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete atomically removes the entry and returns its value.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	var zero V
+	if c.shutdown.Load() {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	b, ok := c.items.LoadAndDelete(key)
+	if !ok {
+		return zero, false, nil
+	}
+	c.size.Add(-1)
+	value := b.(*box[V]).v //nolint:forcetypeassert // items only holds *box[V]
+	c.evictor.Evict(ctx, key, value)
+	return value, true, nil
+}
+
+// Size returns the current number of items in the cache. It is read from a
+// counter without walking the map.
+func (c *Cache[K, V]) Size() (int, error) {
+	if c.shutdown.Load() {
+		return 0, cachetypes.ErrShutdown
+	}
+	return int(c.size.Load()), nil
+}
+
+// Capacity returns 0, meaning the cache is unbounded.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	if c.shutdown.Load() {
+		return 0, cachetypes.ErrShutdown
+	}
+	return 0, nil
+}
+
+// Reset clears the cache and calls the eviction callback for each removed item.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	if c.shutdown.Load() {
+		return cachetypes.ErrShutdown
+	}
+	c.evictor.EvictAll(ctx, c.drain())
+	return nil
+}
+
+// drain removes every entry and returns the removed ones. Entries deleted
+// concurrently are skipped, so each entry is returned at most once.
+func (c *Cache[K, V]) drain() []cachetypes.Entry[K, V] {
+	var entries []cachetypes.Entry[K, V]
+	c.items.Range(func(k, _ any) bool {
+		if b, ok := c.items.LoadAndDelete(k); ok {
+			c.size.Add(-1)
+			entries = append(entries, cachetypes.Entry[K, V]{
+				Key:   k.(K),         //nolint:forcetypeassert // items only holds K keys
+				Value: b.(*box[V]).v, //nolint:forcetypeassert // items only holds *box[V]
+			})
+		}
+		return true
+	})
+	return entries
+}
+
+// Traverse calls fn for each entry in unspecified order until fn returns
+// false. It ranges over the live map without a snapshot, so fn may call back
+// into the cache, and entries changed during the walk may or may not be seen.
+func (c *Cache[K, V]) Traverse(ctx context.Context,
+	fn func(context.Context, K, V) bool) error {
+	if c.shutdown.Load() {
+		return cachetypes.ErrShutdown
+	}
+	var err error
+	c.items.Range(func(k, b any) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		return fn(ctx, k.(K), b.(*box[V]).v) //nolint:forcetypeassert // items only holds K -> *box[V]
+	})
+	return err
+}
+
+// Shutdown clears the cache, calling the eviction callback for each item.
+// Every later operation returns ErrShutdown.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	if !c.shutdown.CompareAndSwap(false, true) {
+		return
+	}
+	c.evictor.EvictAll(ctx, c.drain())
+	c.evictor.Close(ctx)
+}
@@ -0,0 +1,19 @@
+package tiered
+
+import "context"
+
+// Options defines the configuration of the tiered cache.
+type Options[K comparable] struct {
+	// OnPromoteError, when set, is called with the error when Get fails to
+	// copy an L2 hit into L1.
+	OnPromoteError func(ctx context.Context, key K, err error)
+}
+
+// WithPromoteErrorHandler sets the function called when Get fails to copy an
+// L2 hit into L1, e.g. to log or count the failures. Get still returns the
+// value, so without a handler such failures go unnoticed.
+func WithPromoteErrorHandler[K comparable](h func(ctx context.Context, key K, err error)) func(o *Options[K]) {
+	return func(o *Options[K]) {
+		o.OnPromoteError = h
+	}
+}
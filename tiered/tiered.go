@@ -0,0 +1,159 @@
+// Package tiered provides a two-level cache that stacks a small, fast L1
+// cache over a larger L2 cache, e.g. an lru over a shard cache.
+package tiered
+
+import (
+	"context"
+
+	"github.com/mcphone2004/cache/iface"
+)
+
+// Ensure Cache satisfies iface.Cache at compile time.
+var _ iface.Cache[struct{}, struct{}] = (*Cache[struct{}, struct{}])(nil)
+
+// Cache reads from L1 first and falls back to L2, promoting L2 hits into L1.
+// Writes go through to both levels. L1 is treated as a subset of L2, so Size
+// and Capacity report L2.
+type Cache[K comparable, V any] struct {
+	l1 iface.Cache[K, V]
+	l2 iface.Cache[K, V]
+	// onPromoteError is nil unless WithPromoteErrorHandler is set.
+	onPromoteError func(ctx context.Context, key K, err error)
+}
+
+// New returns a Cache composed of l1 and l2. The Cache takes ownership of
+// both levels: Shutdown shuts them down.
+func New[K comparable, V any](l1, l2 iface.Cache[K, V],
+	options ...func(o *Options[K])) *Cache[K, V] {
+	var o Options[K]
+	for _, cb := range options {
+		cb(&o)
+	}
+	return &Cache[K, V]{l1: l1, l2: l2, onPromoteError: o.OnPromoteError}
+}
+
+// Get returns the value from L1, or from L2 on an L1 miss. An L2 hit is
+// copied into L1 on a best-effort basis: L2 holds the value either way, so a
+// failed copy is passed to the WithPromoteErrorHandler handler and Get still
+// returns the value with a nil error.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	if v, found, err := c.l1.Get(ctx, key); err != nil || found {
+		return v, found, err
+	}
+	v, found, err := c.l2.Get(ctx, key)
+	if err != nil || !found {
+		return v, found, err
+	}
+	if err := c.l1.Put(ctx, key, v); err != nil && c.onPromoteError != nil {
+		c.onPromoteError(ctx, key, err)
+	}
+	return v, true, nil
+}
+
+// Has reports whether the key is present in either level without promoting it.
+func (c *Cache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	if found, err := c.l1.Has(ctx, key); err != nil || found {
+		return found, err
+	}
+	return c.l2.Has(ctx, key)
+}
+
+// Put writes the value to L1 and then to L2.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.l1.Put(ctx, key, value); err != nil {
+		return err
+	}
+	return c.l2.Put(ctx, key, value)
+}
+
+// Delete removes the key from both levels and reports whether either held it.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	found1, err1 := c.l1.Delete(ctx, key)
+	found2, err2 := c.l2.Delete(ctx, key)
+	if err1 != nil {
+		return found1 || found2, err1
+	}
+	return found1 || found2, err2
+}
+
+// GetAndDelete removes the key from both levels and returns its value,
+// preferring the L1 copy.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	v1, found1, err1 := c.l1.GetAndDelete(ctx, key)
+	v2, found2, err2 := c.l2.GetAndDelete(ctx, key)
+	v := v2
+	if found1 {
+		v = v1
+	}
+	if err1 != nil {
+		return v, found1 || found2, err1
+	}
+	return v, found1 || found2, err2
+}
+
+// Replace updates the key in whichever levels hold it and returns the
+// previous value, preferring the L1 copy as Get would. It reports whether
+// either level held the key.
+func (c *Cache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	v1, found1, err1 := c.l1.Replace(ctx, key, value)
+	v2, found2, err2 := c.l2.Replace(ctx, key, value)
+	v := v2
+	if found1 {
+		v = v1
+	}
+	if err1 != nil {
+		return v, found1 || found2, err1
+	}
+	return v, found1 || found2, err2
+}
+
+// Size returns the number of entries in L2.
+func (c *Cache[K, V]) Size() (int, error) {
+	return c.l2.Size()
+}
+
+// Capacity returns the capacity of L2.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	return c.l2.Capacity()
+}
+
+// Reset clears both levels. L2 is reset even if resetting L1 fails; the
+// first error is returned.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	err1 := c.l1.Reset(ctx)
+	err2 := c.l2.Reset(ctx)
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// Traverse visits the entries of L1 and then those of L2 that were not
+// already visited, stopping as soon as fn returns false.
+func (c *Cache[K, V]) Traverse(ctx context.Context, fn func(context.Context, K, V) bool) error {
+	seen := make(map[K]struct{})
+	stop := false
+	err := c.l1.Traverse(ctx, func(innerCtx context.Context, k K, v V) bool {
+		seen[k] = struct{}{}
+		if !fn(innerCtx, k, v) {
+			stop = true
+			return false
+		}
+		return true
+	})
+	if err != nil || stop {
+		return err
+	}
+	return c.l2.Traverse(ctx, func(innerCtx context.Context, k K, v V) bool {
+		if _, ok := seen[k]; ok {
+			return true
+		}
+		return fn(innerCtx, k, v)
+	})
+}
+
+// Shutdown shuts down both levels.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.l1.Shutdown(ctx)
+	c.l2.Shutdown(ctx)
+}
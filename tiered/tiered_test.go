@@ -0,0 +1,193 @@
+package tiered_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/lru"
+	"github.com/mcphone2004/cache/tiered"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestGetPromotesFromL2(t *testing.T) {
+	ctx := context.Background()
+	l1 := iface.NewMockCache[int, string](t)
+	l2 := iface.NewMockCache[int, string](t)
+	c := tiered.New[int, string](l1, l2)
+
+	// L1 hit: L2 is not consulted
+	l1.EXPECT().Get(ctx, 1).Return("one", true, nil).Once()
+	v, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "one", v)
+
+	// L1 miss, L2 hit: value is promoted into L1
+	l1.EXPECT().Get(ctx, 2).Return("", false, nil).Once()
+	l2.EXPECT().Get(ctx, 2).Return("two", true, nil).Once()
+	l1.EXPECT().Put(ctx, 2, "two").Return(nil).Once()
+	v, ok, err = c.Get(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "two", v)
+
+	// miss in both levels
+	l1.EXPECT().Get(ctx, 3).Return("", false, nil).Once()
+	l2.EXPECT().Get(ctx, 3).Return("", false, nil).Once()
+	_, ok, err = c.Get(ctx, 3)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// L1 error is returned without consulting L2
+	l1.EXPECT().Get(ctx, 4).Return("", false, cachetypes.ErrShutdown).Once()
+	_, _, err = c.Get(ctx, 4)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+}
+
+func TestGetPromotionIsBestEffort(t *testing.T) {
+	ctx := context.Background()
+	l1 := iface.NewMockCache[int, string](t)
+	l2 := iface.NewMockCache[int, string](t)
+	var failed []error
+	c := tiered.New[int, string](l1, l2, tiered.WithPromoteErrorHandler(
+		func(_ context.Context, key int, err error) {
+			require.Equal(t, 1, key)
+			failed = append(failed, err)
+		}))
+
+	// a failed copy into L1 still returns the L2 value, without an error
+	errFull := errors.New("l1 full")
+	l1.EXPECT().Get(ctx, 1).Return("", false, nil).Once()
+	l2.EXPECT().Get(ctx, 1).Return("one", true, nil).Once()
+	l1.EXPECT().Put(ctx, 1, "one").Return(errFull).Once()
+	v, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "one", v)
+	require.Equal(t, []error{errFull}, failed)
+
+	// without a handler the failure is ignored
+	c = tiered.New[int, string](l1, l2)
+	l1.EXPECT().Get(ctx, 1).Return("", false, nil).Once()
+	l2.EXPECT().Get(ctx, 1).Return("one", true, nil).Once()
+	l1.EXPECT().Put(ctx, 1, "one").Return(errFull).Once()
+	v, ok, err = c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "one", v)
+}
+
+func TestWritesPropagate(t *testing.T) {
+	ctx := context.Background()
+	l1 := iface.NewMockCache[int, string](t)
+	l2 := iface.NewMockCache[int, string](t)
+	c := tiered.New[int, string](l1, l2)
+
+	l1.EXPECT().Put(ctx, 1, "one").Return(nil).Once()
+	l2.EXPECT().Put(ctx, 1, "one").Return(nil).Once()
+	require.NoError(t, c.Put(ctx, 1, "one"))
+
+	l1.EXPECT().Delete(ctx, 1).Return(false, nil).Once()
+	l2.EXPECT().Delete(ctx, 1).Return(true, nil).Once()
+	found, err := c.Delete(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	l1.EXPECT().GetAndDelete(ctx, 2).Return("l1", true, nil).Once()
+	l2.EXPECT().GetAndDelete(ctx, 2).Return("l2", true, nil).Once()
+	v, found, err := c.GetAndDelete(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "l1", v)
+
+	l1.EXPECT().Replace(ctx, 4, "four").Return("", false, nil).Once()
+	l2.EXPECT().Replace(ctx, 4, "four").Return("old", true, nil).Once()
+	v, found, err = c.Replace(ctx, 4, "four")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "old", v)
+
+	// Reset reaches L2 even when L1 fails
+	errL1 := errors.New("l1 failed")
+	l1.EXPECT().Reset(ctx).Return(errL1).Once()
+	l2.EXPECT().Reset(ctx).Return(nil).Once()
+	require.ErrorIs(t, c.Reset(ctx), errL1)
+
+	l1.EXPECT().Has(ctx, 3).Return(false, nil).Once()
+	l2.EXPECT().Has(ctx, 3).Return(true, nil).Once()
+	found, err = c.Has(ctx, 3)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	l2.EXPECT().Size().Return(5, nil).Once()
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Equal(t, 5, size)
+
+	l2.EXPECT().Capacity().Return(10, nil).Once()
+	capacity, err := c.Capacity()
+	require.NoError(t, err)
+	require.Equal(t, 10, capacity)
+
+	l1.EXPECT().Shutdown(ctx).Once()
+	l2.EXPECT().Shutdown(ctx).Once()
+	c.Shutdown(ctx)
+}
+
+func TestTraverseSkipsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	l1 := iface.NewMockCache[int, string](t)
+	l2 := iface.NewMockCache[int, string](t)
+	c := tiered.New[int, string](l1, l2)
+
+	l1.EXPECT().Traverse(ctx, mock.Anything).
+		RunAndReturn(func(ctx context.Context, fn func(context.Context, int, string) bool) error {
+			fn(ctx, 1, "one")
+			return nil
+		}).Once()
+	l2.EXPECT().Traverse(ctx, mock.Anything).
+		RunAndReturn(func(ctx context.Context, fn func(context.Context, int, string) bool) error {
+			fn(ctx, 1, "one")
+			fn(ctx, 2, "two")
+			return nil
+		}).Once()
+
+	var keys []int
+	err := c.Traverse(ctx, func(_ context.Context, k int, _ string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, keys)
+}
+
+func TestWithLRULevels(t *testing.T) {
+	ctx := context.Background()
+	l1, err := lru.New[int, string](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	l2, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	c := tiered.New[int, string](l1, l2)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.NoError(t, c.Put(ctx, 2, "two")) // evicts 1 from L1 only
+
+	v, ok, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "one", v)
+	found, err := l1.Has(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found, "L2 hit must be promoted into L1")
+}
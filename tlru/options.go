@@ -11,8 +11,12 @@ import (
 // and adds TTL-specific settings.
 type Options[K comparable, V any] struct {
 	Base       cachetypes.Options
-	DefaultTTL time.Duration // optional default TTL for Put; 0 means no expiry unless PutWithTTL is used
-	BucketSize time.Duration // granularity for expiry wheel; defaults to time.Second if 0
+	DefaultTTL time.Duration    // optional default TTL for Put; 0 means no expiry unless PutWithTTL is used
+	BucketSize time.Duration    // granularity for expiry wheel; defaults to Base.ExpiryBucket, then defaultBucketSize
+	Clock      cachetypes.Clock // time source for expiry; defaults to the system clock
+	// ExpiryJitter randomizes each expiry within ±ExpiryJitter of its TTL; it
+	// must be in [0, 1), and 0 keeps expiry exact.
+	ExpiryJitter float64
 }
 
 // WithCapacity sets the capacity in base options.
@@ -25,13 +29,67 @@ func WithEvictionCB[K comparable, V any](cb cachetypes.CBFunc[K, V]) func(*Optio
 	return func(o *Options[K, V]) { o.Base.OnEvict = cb }
 }
 
+// WithEvictionChannel sets the eviction channel in base options.
+func WithEvictionChannel[K comparable, V any](ch chan<- cachetypes.Entry[K, V]) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.EvictionChannel = ch }
+}
+
+// WithAsyncEvictionCB sets an asynchronous eviction callback in base options.
+func WithAsyncEvictionCB[K comparable, V any](cb cachetypes.CBFunc[K, V], queueSize uint) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { cachetypes.WithAsyncEvictionCB(cb, queueSize)(&o.Base) }
+}
+
+// WithBatchEvictionCB sets the batch eviction callback in base options.
+func WithBatchEvictionCB[K comparable, V any](cb cachetypes.BatchCBFunc[K, V], chunkSize uint) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { cachetypes.WithBatchEvictionCB(cb, chunkSize)(&o.Base) }
+}
+
+// WithPanicHandler sets the eviction callback panic handler in base options.
+func WithPanicHandler[K comparable, V any](h func(recovered any)) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.PanicHandler = h }
+}
+
+// WithEvictionPanicPolicy sets the eviction callback panic policy in base options.
+func WithEvictionPanicPolicy[K comparable, V any](policy cachetypes.EvictionPanicPolicy) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.EvictionPanicPolicy = policy }
+}
+
+// WithEntryPoolLimit sets the entry pool limit in base options.
+func WithEntryPoolLimit[K comparable, V any](limit uint) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.EntryPoolLimit = limit }
+}
+
+// WithMaxKeyBytes sets the maximum key size and its sizer in base options.
+func WithMaxKeyBytes[K comparable, V any](maxBytes uint, sizer func(K) int) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { cachetypes.WithMaxKeyBytes(maxBytes, sizer)(&o.Base) }
+}
+
+// WithMaxValueBytes sets the maximum value size and its sizer in base options.
+func WithMaxValueBytes[K comparable, V any](maxBytes uint, sizer func(V) int) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { cachetypes.WithMaxValueBytes(maxBytes, sizer)(&o.Base) }
+}
+
 // WithDefaultTTL sets the default TTL for entries inserted via Put.
 func WithDefaultTTL[K comparable, V any](ttl time.Duration) func(*Options[K, V]) {
 	return func(o *Options[K, V]) { o.DefaultTTL = ttl }
 }
 
+// WithClock sets the time source used for expiry, e.g. a fake clock in tests.
+func WithClock[K comparable, V any](clock cachetypes.Clock) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Clock = clock }
+}
+
+// WithExpiryJitter randomizes each registered expiry within ±fraction of the
+// TTL, so that keys inserted together with the same TTL do not all expire in
+// the same bucket and trigger a reload stampede. Jittered expiries are still
+// rounded up to the bucket size. A fraction of 0 preserves exact expiry.
+func WithExpiryJitter[K comparable, V any](fraction float64) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.ExpiryJitter = fraction }
+}
+
 // WithBucketSize sets the expiry bucket size. Larger buckets reduce timer churn but
 // can delay expirations up to the bucket size. If not set, a reasonable default is used.
+// It must not be negative.
 func WithBucketSize[K comparable, V any](d time.Duration) func(*Options[K, V]) {
 	return func(o *Options[K, V]) { o.BucketSize = d }
 }
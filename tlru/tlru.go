@@ -22,6 +22,10 @@ type valWrap[V any] struct {
 // Ensure Cache implements the Cache interface.
 var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
 
+// defaultBucketSize is the expiry bucket size used when none is configured.
+// It keeps millisecond precision so that short TTLs expire on time.
+const defaultBucketSize = time.Millisecond
+
 // Cache is a thread-safe TTL-enabled LRU cache.
 type Cache[K comparable, V any] struct {
 	mu         sync.Mutex
@@ -33,6 +37,11 @@ type Cache[K comparable, V any] struct {
 	// ttl registration state
 	expMap   *internal.ExpiryMap[K]
 	defaultT time.Duration
+	jitter   float64
+	clock    cachetypes.Clock
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
 }
 
 // New creates a new TTL-enabled LRU cache.
@@ -43,28 +52,63 @@ func New[K comparable, V any](options ...func(o *Options[K, V])) (*Cache[K, V],
 	}
 
 	// validate base options using existing internal helper
-	base, err := internal.ToOptions[K, V](o.Base)
+	base, err := internal.ToTTLOptions[K, V](o.Base)
 	if err != nil {
 		return nil, err
 	}
 
+	if o.ExpiryJitter < 0 || o.ExpiryJitter >= 1 {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "expiry jitter must be in [0, 1)",
+		}
+	}
+
+	if o.BucketSize < 0 {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "bucket size must not be negative",
+		}
+	}
 	bucket := o.BucketSize
-	if bucket <= 0 {
-		bucket = time.Millisecond
+	if bucket == 0 {
+		bucket = base.ExpiryBucket
+	}
+	if bucket == 0 {
+		bucket = defaultBucketSize
+	}
+	clock := o.Clock
+	if clock == nil {
+		clock = internal.SystemClock{}
 	}
 
+	evictor := internal.NewEvictor(base)
+	onEvict := evictor.Callback()
 	c := &Cache[K, V]{
 		items: make(map[K]*internal.ListEntry[K, valWrap[V]], base.Capacity),
 		queue: internal.NewList(base.Capacity, func(ctx context.Context, k K, wrap valWrap[V]) {
-			if base.OnEvict != nil {
-				base.OnEvict(ctx, k, wrap.Val)
+			if onEvict != nil {
+				onEvict(ctx, k, wrap.Val)
 			}
 		}),
-		defaultT: o.DefaultTTL,
+		defaultT:  o.DefaultTTL,
+		jitter:    o.ExpiryJitter,
+		clock:     clock,
+		evictor:   evictor,
+		sizeLimit: base.SizeLimit,
+	}
+	c.queue.SetPanicHandler(base.PanicHandler)
+	c.queue.SetEntryPoolLimit(base.EntryPoolLimit)
+	if onBatch := evictor.BatchCallback(); onBatch != nil {
+		c.queue.SetBatchEvict(func(ctx context.Context, wrapped []cachetypes.Entry[K, valWrap[V]]) {
+			entries := make([]cachetypes.Entry[K, V], len(wrapped))
+			for i, w := range wrapped {
+				entries[i] = cachetypes.Entry[K, V]{Key: w.Key, Value: w.Value.Val}
+			}
+			onBatch(ctx, entries)
+		})
 	}
 
 	// create expiry map with callback to delete expired keys
-	c.expMap = internal.New[K](func(s map[K]struct{}) {
+	c.expMap = internal.NewWithClock[K](func(s map[K]struct{}) {
 		ctx := context.Background()
 		c.mu.Lock()
 		if c.isShutdown {
@@ -84,7 +128,7 @@ func New[K comparable, V any](options ...func(o *Options[K, V])) (*Cache[K, V],
 		for _, en := range toEvict {
 			c.queue.OnEvict(ctx, en)
 		}
-	}, bucket)
+	}, bucket, clock)
 
 	return c, nil
 }
@@ -99,7 +143,12 @@ func (c *Cache[K, V]) PutWithTTL(ctx context.Context, key K, value V, ttl time.D
 	return c.putWithTTL(ctx, key, value, ttl)
 }
 
+// putWithTTL returns cachetypes.ErrEntryTooLarge, leaving the cache
+// unchanged, if the entry exceeds a configured size limit.
 func (c *Cache[K, V]) putWithTTL(ctx context.Context, key K, value V, ttl time.Duration) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
 	c.mu.Lock()
 	if c.isShutdown {
 		c.mu.Unlock()
@@ -147,6 +196,12 @@ func (c *Cache[K, V]) putWithTTL(ctx context.Context, key K, value V, ttl time.D
 	return nil
 }
 
+// DroppedEvictions returns how many evicted or expired entries were not
+// delivered because the eviction channel was full.
+func (c *Cache[K, V]) DroppedEvictions() uint64 {
+	return c.evictor.Dropped()
+}
+
 // Get retrieves a value and refreshes recency. Expired items are removed by the
 // background expiry map, so we don’t check time here to keep it simple.
 func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
@@ -163,9 +218,48 @@ func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
 	return zero, false, nil
 }
 
+// Has reports whether the key is present without changing its recency.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.items[key]
+	return ok, nil
+}
+
+// Replace updates the value of an existing key, marks it as recently used
+// and returns the previous value. Like Put, it resets the entry's expiry to
+// the default TTL. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	c.queue.MoveToFront(elem)
+	wrap := &elem.Value.Value
+	old := wrap.Val
+	wrap.Val = value
+	c.unregisterTTL(elem)
+	if c.defaultT > 0 {
+		c.registerTTL(elem, c.defaultT)
+	}
+	return old, true, nil
+}
+
 // registerTTL registers or re-registers the elem's key with the expiry map and stores the handle in-place.
 func (c *Cache[K, V]) registerTTL(elem *internal.ListEntry[K, valWrap[V]], ttl time.Duration) {
-	exp := time.Now().Add(ttl)
+	exp := c.clock.Now().Add(internal.Jitter(ttl, c.jitter))
 	h := c.expMap.Register(elem.Value.Key, exp)
 	v := &elem.Value.Value
 	v.Handle = h
@@ -183,23 +277,32 @@ func (c *Cache[K, V]) unregisterTTL(elem *internal.ListEntry[K, valWrap[V]]) {
 
 // Delete removes an entry from the cache and unregisters its TTL if present.
 func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete removes an entry from the cache, unregisters its TTL if present,
+// and returns its value. Lookup and removal happen under a single lock.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	var zero V
 	c.mu.Lock()
 	if c.isShutdown {
 		c.mu.Unlock()
-		return false, cachetypes.ErrShutdown
+		return zero, false, cachetypes.ErrShutdown
 	}
 	elem, ok := c.items[key]
 	if !ok {
 		c.mu.Unlock()
-		return false, nil
+		return zero, false, nil
 	}
 	delete(c.items, key)
 	// Unregister TTL before removing from the queue since Remove clears elem.Value
 	c.unregisterTTL(elem)
 	ent := c.queue.Remove(elem)
+	value := ent.Value.Val
 	c.mu.Unlock()
 	c.queue.OnEvict(ctx, ent)
-	return true, nil
+	return value, true, nil
 }
 
 // Traverse iterates over all items in the cache.
@@ -256,29 +359,26 @@ func (c *Cache[K, V]) Capacity() (int, error) {
 // Reset clears the cache and cancels all expiry registrations.
 func (c *Cache[K, V]) Reset(ctx context.Context) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	if c.isShutdown {
+		c.mu.Unlock()
 		return cachetypes.ErrShutdown
 	}
-	c.resetLocked(ctx)
+	toEvict := c.removeAll()
+	c.mu.Unlock()
+	c.queue.OnEvictAll(ctx, toEvict)
 	return nil
 }
 
-func (c *Cache[K, V]) resetLocked(ctx context.Context) {
-	for {
-		// evict returns *internal.Entry[K, valWrap[V]] now
-		en := c.evict()
-		if en == nil {
-			break
-		}
-		c.mu.Unlock()
-		c.queue.OnEvict(ctx, en)
-		c.mu.Lock()
-	}
-	// unregister TTL handles in-place for all remaining elements (none expected)
-	for e := range c.queue.Seq() {
-		c.unregisterTTL(e)
+// removeAll empties the cache, cancelling expiry registrations, and returns
+// its entries. The caller holds mu and must release it before passing the
+// entries to OnEvictAll, so that a callback panicking with
+// EvictionPanicPropagate does not unwind with the lock in an unknown state.
+func (c *Cache[K, V]) removeAll() []*internal.Entry[K, valWrap[V]] {
+	var toEvict []*internal.Entry[K, valWrap[V]]
+	for en := c.evict(); en != nil; en = c.evict() {
+		toEvict = append(toEvict, en)
 	}
+	return toEvict
 }
 
 // Shutdown releases resources and stops the expiry goroutine.
@@ -289,14 +389,19 @@ func (c *Cache[K, V]) Shutdown(ctx context.Context) {
 		return
 	}
 	c.isShutdown = true
-	c.resetLocked(ctx)
+	toEvict := c.removeAll()
 	c.items = nil
 	q := c.queue
 	r := c.expMap
 	c.mu.Unlock()
-	// destroy outside the lock
-	q.Destroy()
-	r.Shutdown()
+	// release resources outside the lock, even if a callback panics with
+	// EvictionPanicPropagate
+	defer func() {
+		q.Destroy()
+		r.Shutdown()
+		c.evictor.Close(ctx)
+	}()
+	q.OnEvictAll(ctx, toEvict)
 }
 
 // evict removes the least recently used item and returns it (without OnEvict call).
@@ -11,6 +11,7 @@ import (
 	"github.com/mcphone2004/cache/iface"
 	"github.com/mcphone2004/cache/internal/testhelper"
 	"github.com/mcphone2004/cache/tlru"
+	cachetypes "github.com/mcphone2004/cache/types"
 )
 
 func TestMain(m *testing.M) {
@@ -39,6 +40,19 @@ func TestShutdown(t *testing.T) {
 	testhelper.CommonShutdownTest(t, newCache[int, string])
 }
 
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache[int, string])
+	testhelper.CommonHasNoPromoteTest(t, newCache[int, string])
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache[int, string])
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache[int, string])
+}
+
 func TestDeleteNonExistent(t *testing.T) {
 	testhelper.CommonDeleteNonExistentTest(t, newCache[int, string])
 }
@@ -51,10 +65,22 @@ func TestEvictionCallbackPanic(t *testing.T) {
 	testhelper.CommonEvictionCallbackPanicTest(t, newCache[int, string])
 }
 
+func TestEvictionCallback(t *testing.T) {
+	testhelper.CommonEvictionCallbackTest(t, newCache[int, string])
+}
+
 func TestConcurrent(t *testing.T) {
 	testhelper.CommonConcurrentTest(t, newCache[int, string])
 }
 
+func TestConcurrentStress(t *testing.T) {
+	testhelper.CommonConcurrentStressTest(t, newCache[int, string])
+}
+
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache[int, string], testhelper.ExactCapacity)
+}
+
 func waitForExpiry(t *testing.T, ch <-chan struct{}, key string) {
 	t.Helper()
 	select {
@@ -312,3 +338,171 @@ func TestTraverseCancel(t *testing.T) {
 func TestStressShutdown(t *testing.T) {
 	testhelper.CommonStressShutdownTest(t, newCache[int, string])
 }
+
+func TestEvictionChannelExpiry(t *testing.T) {
+	ctx := context.Background()
+	ch := make(chan cachetypes.Entry[string, int], 1)
+	c, err := tlru.New[string, int](
+		tlru.WithCapacity[string, int](4),
+		tlru.WithEvictionChannel[string, int](ch),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.PutWithTTL(ctx, "a", 1, 20*time.Millisecond))
+	select {
+	case e := <-ch:
+		require.Equal(t, cachetypes.Entry[string, int]{Key: "a", Value: 1}, e)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timeout waiting for \"a\" on the eviction channel")
+	}
+	require.Zero(t, c.DroppedEvictions())
+}
+
+func TestBatchEvictionCB(t *testing.T) {
+	ctx := context.Background()
+	var got []cachetypes.Entry[string, int]
+	c, err := tlru.New[string, int](
+		tlru.WithCapacity[string, int](4),
+		tlru.WithBatchEvictionCB[string, int](func(_ context.Context, es []cachetypes.Entry[string, int]) {
+			got = append(got, es...)
+		}, 0),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.PutWithTTL(ctx, "a", 1, time.Hour))
+	require.NoError(t, c.Put(ctx, "b", 2))
+	c.Shutdown(ctx)
+	require.Equal(t, []cachetypes.Entry[string, int]{{Key: "a", Value: 1}, {Key: "b", Value: 2}}, got)
+}
+
+func TestTTLExpiryFakeClock(t *testing.T) {
+	testhelper.CommonTTLExpiryTest(t, func(clock cachetypes.Clock, capacity uint,
+		evictionCB func(context.Context, int, string)) (testhelper.TTLCache[int, string], error) {
+		return tlru.New[int, string](
+			tlru.WithCapacity[int, string](capacity),
+			tlru.WithEvictionCB[int, string](evictionCB),
+			tlru.WithClock[int, string](clock),
+		)
+	})
+}
+
+func TestEvictionPanicPropagateResetShutdown(t *testing.T) {
+	testhelper.CommonEvictionPanicPropagateTest(t, func(capacity uint,
+		evictionCB func(context.Context, int, string)) (iface.Cache[int, string], error) {
+		return tlru.New[int, string](
+			tlru.WithCapacity[int, string](capacity),
+			tlru.WithEvictionCB[int, string](evictionCB),
+			tlru.WithEvictionPanicPolicy[int, string](cachetypes.EvictionPanicPropagate),
+		)
+	})
+}
+
+func TestNoLeakAfterShutdown(t *testing.T) {
+	testhelper.CommonNoLeakAfterShutdownTest(t, func(clock cachetypes.Clock, capacity uint,
+		evictionCB func(context.Context, int, string)) (testhelper.TTLCache[int, string], error) {
+		return tlru.New[int, string](
+			tlru.WithCapacity[int, string](capacity),
+			tlru.WithEvictionCB[int, string](evictionCB),
+			tlru.WithClock[int, string](clock),
+		)
+	})
+}
+
+func TestMaxValueBytes(t *testing.T) {
+	ctx := context.Background()
+	c, err := tlru.New(
+		tlru.WithCapacity[int, string](2),
+		tlru.WithMaxValueBytes[int, string](3, func(v string) int { return len(v) }),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.ErrorIs(t, c.Put(ctx, 2, "three"), cachetypes.ErrEntryTooLarge)
+	require.ErrorIs(t, c.PutWithTTL(ctx, 2, "three", time.Hour), cachetypes.ErrEntryTooLarge)
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Equal(t, 1, size)
+}
+
+func TestExpiryJitter(t *testing.T) {
+	_, err := tlru.New(
+		tlru.WithCapacity[int, string](1),
+		tlru.WithExpiryJitter[int, string](1),
+	)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+
+	ctx := context.Background()
+	clock := testhelper.NewFakeClock(time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC))
+	c, err := tlru.New(
+		tlru.WithCapacity[int, string](200),
+		tlru.WithDefaultTTL[int, string](10*time.Second),
+		tlru.WithExpiryJitter[int, string](0.2),
+		tlru.WithBucketSize[int, string](100*time.Millisecond),
+		tlru.WithClock[int, string](clock),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	for k := range 200 {
+		require.NoError(t, c.Put(ctx, k, "v"))
+	}
+	sizeIs := func(cond func(int) bool) func() bool {
+		return func() bool {
+			size, err := c.Size()
+			return err == nil && cond(size)
+		}
+	}
+
+	// nothing expires before TTL-20%
+	clock.Advance(7900 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Equal(t, 200, size)
+
+	// at the nominal TTL only part of the keys have expired
+	clock.Advance(2100 * time.Millisecond)
+	require.Eventually(t, sizeIs(func(n int) bool { return n > 0 && n < 200 }),
+		time.Second, 5*time.Millisecond)
+
+	// everything is gone after TTL+20%
+	clock.Advance(2200 * time.Millisecond)
+	require.Eventually(t, sizeIs(func(n int) bool { return n == 0 }),
+		time.Second, 5*time.Millisecond)
+}
+
+func TestBucketSizeRounding(t *testing.T) {
+	_, err := tlru.New(
+		tlru.WithCapacity[int, string](1),
+		tlru.WithBucketSize[int, string](-time.Second),
+	)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "bucket size must not be negative", aerr.Error())
+
+	ctx := context.Background()
+	clock := testhelper.NewFakeClock(time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC))
+	c, err := tlru.New(
+		tlru.WithCapacity[int, string](1),
+		tlru.WithBucketSize[int, string](time.Second),
+		tlru.WithClock[int, string](clock),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	// the expiry is rounded up to the next one-second bucket
+	require.NoError(t, c.PutWithTTL(ctx, 1, "one", 100*time.Millisecond))
+	clock.Advance(500 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	found, err := c.Has(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	clock.Advance(500 * time.Millisecond)
+	require.Eventually(t, func() bool {
+		found, err := c.Has(ctx, 1)
+		return err == nil && !found
+	}, time.Second, 5*time.Millisecond)
+}
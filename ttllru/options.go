@@ -0,0 +1,66 @@
+package ttllru
+
+import (
+	"time"
+
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Options defines configuration for the TTL-aware LRU cache.
+// It embeds base cache options for capacity and eviction callback,
+// and adds TTL-specific settings.
+type Options[K comparable, V any] struct {
+	Base       cachetypes.Options
+	DefaultTTL time.Duration    // optional default TTL for Put; 0 means no expiry unless PutWithTTL is used
+	BucketSize time.Duration    // granularity for expiry removal; defaults to Base.ExpiryBucket, then defaultBucketSize
+	Clock      cachetypes.Clock // time source for expiry; defaults to the system clock
+	// SweepInterval runs a background sweep removing expired entries this
+	// often; 0 leaves removal to the expiry buckets alone.
+	SweepInterval time.Duration
+}
+
+// WithCapacity sets the capacity in base options.
+func WithCapacity[K comparable, V any](capacity uint) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.Capacity = capacity }
+}
+
+// WithEvictionCB sets the eviction callback in base options.
+func WithEvictionCB[K comparable, V any](cb cachetypes.CBFunc[K, V]) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.OnEvict = cb }
+}
+
+// WithPanicHandler sets the eviction callback panic handler in base options.
+func WithPanicHandler[K comparable, V any](h func(recovered any)) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.PanicHandler = h }
+}
+
+// WithEvictionPanicPolicy sets the eviction callback panic policy in base options.
+func WithEvictionPanicPolicy[K comparable, V any](policy cachetypes.EvictionPanicPolicy) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.EvictionPanicPolicy = policy }
+}
+
+// WithDefaultTTL sets the default TTL for entries inserted via Put.
+func WithDefaultTTL[K comparable, V any](ttl time.Duration) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.DefaultTTL = ttl }
+}
+
+// WithClock sets the time source used for expiry, e.g. a fake clock in tests.
+func WithClock[K comparable, V any](clock cachetypes.Clock) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Clock = clock }
+}
+
+// WithBucketSize sets how often expired entries are removed in the
+// background. Larger buckets reduce timer churn; entries that have expired but
+// not yet been removed are hidden from reads and evicted first.
+func WithBucketSize[K comparable, V any](d time.Duration) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.BucketSize = d }
+}
+
+// WithBackgroundSweep starts a goroutine that removes every expired entry each
+// interval, reclaiming memory for keys that are never read again without
+// waiting for their expiry bucket to pass. It is useful with large bucket
+// sizes; entries it removes are simply skipped when their bucket fires. The
+// goroutine is stopped by Shutdown.
+func WithBackgroundSweep[K comparable, V any](interval time.Duration) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.SweepInterval = interval }
+}
@@ -0,0 +1,467 @@
+// Package ttllru provides an LRU cache with per-key TTLs whose eviction
+// prefers expired entries: when the cache is full it evicts an entry that has
+// already expired, and only falls back to the least recently used one when
+// none has. Live but cold entries are thus kept longer than dead ones.
+package ttllru
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// valWrap wraps a user value with its expiry and TTL registration state.
+type valWrap[V any] struct {
+	Val       V
+	ExpiresAt time.Time // zero if the entry never expires
+	Handle    internal.Handle
+	HasHandle bool
+}
+
+// expired reports whether the entry has expired at now.
+func (w *valWrap[V]) expired(now time.Time) bool {
+	return w.HasHandle && !now.Before(w.ExpiresAt)
+}
+
+// Ensure Cache implements the Cache interface.
+var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
+
+// defaultBucketSize is the expiry bucket size used when none is configured.
+const defaultBucketSize = time.Millisecond
+
+// Cache is a thread-safe TTL-aware LRU cache.
+//
+// Expired entries are removed in the background once their expiry bucket
+// passes. Until then they are hidden from Get, Has, Replace and Traverse and
+// are the first to go when room is needed, but still count towards Size.
+type Cache[K comparable, V any] struct {
+	mu         sync.Mutex
+	isShutdown bool
+
+	items map[K]*internal.ListEntry[K, valWrap[V]]
+	queue *internal.List[K, valWrap[V]]
+
+	// ttl registration state
+	expMap   *internal.ExpiryMap[K]
+	defaultT time.Duration
+	clock    cachetypes.Clock
+
+	evictor *internal.Evictor[K, V]
+
+	// background sweep state; quit is nil unless WithBackgroundSweep is set
+	sweepWG sync.WaitGroup
+	quit    chan struct{}
+}
+
+// New creates a new TTL-aware LRU cache.
+func New[K comparable, V any](options ...func(o *Options[K, V])) (*Cache[K, V], error) {
+	var o Options[K, V]
+	for _, cb := range options {
+		cb(&o)
+	}
+
+	base, err := internal.ToTTLOptions[K, V](o.Base)
+	if err != nil {
+		return nil, err
+	}
+	if o.SweepInterval < 0 {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "sweep interval must not be negative",
+		}
+	}
+
+	bucket := o.BucketSize
+	if bucket <= 0 {
+		bucket = base.ExpiryBucket
+	}
+	if bucket <= 0 {
+		bucket = defaultBucketSize
+	}
+	clock := o.Clock
+	if clock == nil {
+		clock = internal.SystemClock{}
+	}
+
+	evictor := internal.NewEvictor(base)
+	onEvict := evictor.Callback()
+	c := &Cache[K, V]{
+		items: make(map[K]*internal.ListEntry[K, valWrap[V]], base.Capacity),
+		queue: internal.NewList(base.Capacity, func(ctx context.Context, k K, wrap valWrap[V]) {
+			if onEvict != nil {
+				onEvict(ctx, k, wrap.Val)
+			}
+		}),
+		defaultT: o.DefaultTTL,
+		clock:    clock,
+		evictor:  evictor,
+	}
+	c.queue.SetPanicHandler(base.PanicHandler)
+
+	c.expMap = internal.NewWithClock[K](func(s map[K]struct{}) {
+		ctx := context.Background()
+		c.mu.Lock()
+		if c.isShutdown {
+			c.mu.Unlock()
+			return
+		}
+		var toEvict []*internal.Entry[K, valWrap[V]]
+		for k := range s {
+			if elem, ok := c.items[k]; ok {
+				delete(c.items, k)
+				toEvict = append(toEvict, c.queue.Remove(elem))
+			}
+		}
+		c.mu.Unlock()
+		for _, en := range toEvict {
+			c.queue.OnEvict(ctx, en)
+		}
+	}, bucket, clock)
+
+	if o.SweepInterval > 0 {
+		c.quit = make(chan struct{})
+		c.sweepWG.Add(1)
+		// the timer is armed before New returns so that the first sweep is
+		// measured from construction
+		go c.runSweep(clock.NewTimer(o.SweepInterval), o.SweepInterval)
+	}
+	return c, nil
+}
+
+// runSweep calls sweep each time timer fires, rearming it with interval,
+// until Shutdown closes quit.
+func (c *Cache[K, V]) runSweep(timer cachetypes.Timer, interval time.Duration) {
+	defer c.sweepWG.Done()
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.quit:
+			return
+		case <-timer.C():
+			c.sweep(context.Background())
+			timer.Reset(interval)
+		}
+	}
+}
+
+// sweep removes every expired entry and calls the eviction callback for each.
+// Removed entries are unregistered from the expiry map, so their buckets no
+// longer hold them when they fire.
+func (c *Cache[K, V]) sweep(ctx context.Context) {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return
+	}
+	var toEvict []*internal.Entry[K, valWrap[V]]
+	for elem := c.expiredEntry(); elem != nil; elem = c.expiredEntry() {
+		toEvict = append(toEvict, c.remove(elem))
+	}
+	c.mu.Unlock()
+	for _, en := range toEvict {
+		c.queue.OnEvict(ctx, en)
+	}
+}
+
+// Put inserts or updates a value in the cache using the default TTL if configured.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	return c.PutWithTTL(ctx, key, value, c.defaultT)
+}
+
+// PutWithTTL inserts or updates a value in the cache with an explicit TTL;
+// a ttl of 0 means the entry never expires. When the cache is full, an
+// expired entry is evicted in preference to the least recently used one.
+func (c *Cache[K, V]) PutWithTTL(ctx context.Context, key K, value V, ttl time.Duration) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+
+	if elem, ok := c.items[key]; ok {
+		c.queue.MoveToFront(elem)
+		elem.Value.Value.Val = value
+		c.unregisterTTL(elem)
+		if ttl > 0 {
+			c.registerTTL(elem, ttl)
+		}
+		c.mu.Unlock()
+		return nil
+	}
+
+	var evicted *internal.Entry[K, valWrap[V]]
+	if c.queue.Size() >= c.queue.Capacity() {
+		evicted = c.evict()
+	}
+	entry := c.queue.PushFront(key, valWrap[V]{Val: value})
+	c.items[key] = entry
+	if ttl > 0 {
+		c.registerTTL(entry, ttl)
+	}
+	c.mu.Unlock()
+
+	if evicted != nil {
+		c.queue.OnEvict(ctx, evicted)
+	}
+	return nil
+}
+
+// DroppedEvictions returns how many evicted or expired entries were not
+// delivered because the eviction channel was full.
+func (c *Cache[K, V]) DroppedEvictions() uint64 {
+	return c.evictor.Dropped()
+}
+
+// live returns the entry for key if it is present and has not expired.
+// It must be called with the mutex held.
+func (c *Cache[K, V]) live(key K) (*internal.ListEntry[K, valWrap[V]], bool) {
+	elem, ok := c.items[key]
+	if !ok || elem.Value.Value.expired(c.clock.Now()) {
+		return nil, false
+	}
+	return elem, true
+}
+
+// Get retrieves a value and marks it as recently used. An expired entry is
+// reported as missing even if it has not been removed yet.
+func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.live(key)
+	if !ok {
+		return zero, false, nil
+	}
+	c.queue.MoveToFront(elem)
+	return elem.Value.Value.Val, true, nil
+}
+
+// Has reports whether an unexpired entry for key is present without changing
+// its recency.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.live(key)
+	return ok, nil
+}
+
+// Replace updates the value of an existing, unexpired key, marks it as
+// recently used and returns the previous value. Like Put, it resets the
+// entry's expiry to the default TTL. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.live(key)
+	if !ok {
+		return zero, false, nil
+	}
+	c.queue.MoveToFront(elem)
+	wrap := &elem.Value.Value
+	old := wrap.Val
+	wrap.Val = value
+	c.unregisterTTL(elem)
+	if c.defaultT > 0 {
+		c.registerTTL(elem, c.defaultT)
+	}
+	return old, true, nil
+}
+
+// registerTTL registers the elem's key with the expiry map and records its expiry in-place.
+func (c *Cache[K, V]) registerTTL(elem *internal.ListEntry[K, valWrap[V]], ttl time.Duration) {
+	v := &elem.Value.Value
+	v.ExpiresAt = c.clock.Now().Add(ttl)
+	v.Handle = c.expMap.Register(elem.Value.Key, v.ExpiresAt)
+	v.HasHandle = true
+}
+
+// unregisterTTL cancels expiry registration for the elem's key if present and clears it in-place.
+func (c *Cache[K, V]) unregisterTTL(elem *internal.ListEntry[K, valWrap[V]]) {
+	v := &elem.Value.Value
+	if v.HasHandle {
+		c.expMap.Unregister(v.Handle, elem.Value.Key)
+		v.HasHandle = false
+		v.ExpiresAt = time.Time{}
+	}
+}
+
+// Delete removes an entry from the cache and unregisters its TTL if present.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete removes an entry from the cache, unregisters its TTL if present,
+// and returns its value. An expired entry is removed but reported as missing.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	var zero V
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return zero, false, nil
+	}
+	expired := elem.Value.Value.expired(c.clock.Now())
+	ent := c.remove(elem)
+	value := ent.Value.Val
+	c.mu.Unlock()
+	c.queue.OnEvict(ctx, ent)
+	if expired {
+		return zero, false, nil
+	}
+	return value, true, nil
+}
+
+// Traverse iterates over all unexpired items in the cache.
+// It snapshots under lock and calls the user function without holding the mutex.
+func (c *Cache[K, V]) Traverse(ctx context.Context, fn func(context.Context, K, V) bool) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	now := c.clock.Now()
+	pairs := make([]cachetypes.Entry[K, V], 0, c.queue.Size())
+	for e := range c.queue.Seq() {
+		if e.Value.Value.expired(now) {
+			continue
+		}
+		pairs = append(pairs, cachetypes.Entry[K, V]{Key: e.Value.Key, Value: e.Value.Value.Val})
+	}
+	c.mu.Unlock()
+	for _, p := range pairs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !fn(ctx, p.Key, p.Value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Size returns the number of items in the cache, including expired entries
+// that have not been removed yet.
+func (c *Cache[K, V]) Size() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return c.queue.Size(), nil
+}
+
+// Capacity returns the capacity of the cache.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return c.queue.Capacity(), nil
+}
+
+// Reset clears the cache and cancels all expiry registrations.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	toEvict := c.removeAll()
+	c.mu.Unlock()
+	c.queue.OnEvictAll(ctx, toEvict)
+	return nil
+}
+
+// removeAll empties the cache, cancelling expiry registrations, and returns
+// its entries. The caller holds mu and must release it before passing the
+// entries to OnEvictAll, so that a callback panicking with
+// EvictionPanicPropagate does not unwind with the lock in an unknown state.
+func (c *Cache[K, V]) removeAll() []*internal.Entry[K, valWrap[V]] {
+	var toEvict []*internal.Entry[K, valWrap[V]]
+	for elem := c.queue.Back(); elem != nil; elem = c.queue.Back() {
+		toEvict = append(toEvict, c.remove(elem))
+	}
+	return toEvict
+}
+
+// Shutdown releases resources and stops the expiry goroutine.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return
+	}
+	c.isShutdown = true
+	toEvict := c.removeAll()
+	c.items = nil
+	q := c.queue
+	r := c.expMap
+	c.mu.Unlock()
+	if c.quit != nil {
+		close(c.quit)
+		c.sweepWG.Wait()
+	}
+	// release resources outside the lock, even if a callback panics with
+	// EvictionPanicPropagate
+	defer func() {
+		q.Destroy()
+		r.Shutdown()
+		c.evictor.Close(ctx)
+	}()
+	q.OnEvictAll(ctx, toEvict)
+}
+
+// evict removes an expired entry if there is one, otherwise the least
+// recently used entry, and returns it (without OnEvict call).
+func (c *Cache[K, V]) evict() *internal.Entry[K, valWrap[V]] {
+	if elem := c.expiredEntry(); elem != nil {
+		return c.remove(elem)
+	}
+	if elem := c.queue.Back(); elem != nil {
+		return c.remove(elem)
+	}
+	return nil
+}
+
+// expiredEntry returns an entry that has expired but not been removed yet,
+// or nil. Only the earliest expiry bucket needs to be scanned: if none of its
+// entries has expired, no later one has either.
+func (c *Cache[K, V]) expiredEntry() *internal.ListEntry[K, valWrap[V]] {
+	now := c.clock.Now()
+	var found *internal.ListEntry[K, valWrap[V]]
+	c.expMap.Earliest(func(k K) bool {
+		if elem, ok := c.items[k]; ok && elem.Value.Value.expired(now) {
+			found = elem
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// remove removes elem from the cache, unregistering its TTL, and returns its
+// entry (without OnEvict call).
+func (c *Cache[K, V]) remove(elem *internal.ListEntry[K, valWrap[V]]) *internal.Entry[K, valWrap[V]] {
+	delete(c.items, elem.Value.Key)
+	// Unregister TTL before removing from the queue since Remove clears elem.Value
+	c.unregisterTTL(elem)
+	return c.queue.Remove(elem)
+}
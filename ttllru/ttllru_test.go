@@ -0,0 +1,225 @@
+package ttllru_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal/testhelper"
+	"github.com/mcphone2004/cache/ttllru"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func newCache[K comparable, T any](capacity uint, evictionCB func(context.Context, K, T)) (iface.Cache[K, T], error) {
+	return ttllru.New[K, T](
+		ttllru.WithCapacity[K, T](capacity),
+		ttllru.WithEvictionCB[K, T](evictionCB),
+	)
+}
+
+func TestBasicLRUSemantics(t *testing.T) {
+	// Reuse the shared LRU tests with default TTL 0 (no expiry)
+	testhelper.CommonLRUResetTest(t, newCache[int, string])
+	testhelper.CommonLRUCacheBasicTest(t, newCache[int, string])
+	testhelper.CommonLRUCacheUpdateTest(t, newCache[string, int])
+	testhelper.CommonLRUCacheEvictionOrderTest(t, newCache[int, string])
+	testhelper.CommonTraverseTest(t, newCache[int, string])
+	testhelper.CommonTraverseReentrantTest(t, newCache[int, string])
+	testhelper.CommonDeleteTest(t, newCache[int, string])
+}
+
+func TestEvictionPanicPropagateResetShutdown(t *testing.T) {
+	testhelper.CommonEvictionPanicPropagateTest(t, func(capacity uint,
+		evictionCB func(context.Context, int, string)) (iface.Cache[int, string], error) {
+		return ttllru.New[int, string](
+			ttllru.WithCapacity[int, string](capacity),
+			ttllru.WithEvictionCB[int, string](evictionCB),
+			ttllru.WithEvictionPanicPolicy[int, string](cachetypes.EvictionPanicPropagate),
+		)
+	})
+}
+
+func TestNoLeakAfterShutdown(t *testing.T) {
+	// the background sweep adds a second goroutine that must also stop
+	testhelper.CommonNoLeakAfterShutdownTest(t, func(clock cachetypes.Clock, capacity uint,
+		evictionCB func(context.Context, int, string)) (testhelper.TTLCache[int, string], error) {
+		return ttllru.New[int, string](
+			ttllru.WithCapacity[int, string](capacity),
+			ttllru.WithEvictionCB[int, string](evictionCB),
+			ttllru.WithClock[int, string](clock),
+			ttllru.WithBackgroundSweep[int, string](time.Second),
+		)
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	testhelper.CommonShutdownTest(t, newCache[int, string])
+}
+
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache[int, string])
+	testhelper.CommonHasNoPromoteTest(t, newCache[int, string])
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache[int, string])
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache[int, string])
+}
+
+func TestEvictionCallback(t *testing.T) {
+	testhelper.CommonEvictionCallbackTest(t, newCache[int, string])
+}
+
+func TestConcurrentStress(t *testing.T) {
+	testhelper.CommonConcurrentStressTest(t, newCache[int, string])
+}
+
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache[int, string], testhelper.ExactCapacity)
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	_, err := ttllru.New[int, string]()
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "capacity must be positive", aerr.Error())
+}
+
+func TestEvictsExpiredBeforeLRU(t *testing.T) {
+	ctx := context.Background()
+	clock := testhelper.NewFakeClock(time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC))
+	var evicted []int
+	c, err := ttllru.New(
+		ttllru.WithCapacity[int, string](3),
+		ttllru.WithBucketSize[int, string](time.Second),
+		ttllru.WithClock[int, string](clock),
+		ttllru.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	// 1 is the least recently used but never expires; 3 expires first
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.NoError(t, c.PutWithTTL(ctx, 2, "two", 800*time.Millisecond))
+	require.NoError(t, c.PutWithTTL(ctx, 3, "three", 100*time.Millisecond))
+
+	// 3 has expired but its one-second bucket has not passed yet
+	clock.Advance(200 * time.Millisecond)
+	found, err := c.Has(ctx, 3)
+	require.NoError(t, err)
+	require.False(t, found, "an expired entry is hidden before it is removed")
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Equal(t, 3, size)
+
+	require.NoError(t, c.Put(ctx, 4, "four"))
+	require.Equal(t, []int{3}, evicted)
+
+	// with nothing expired, the least recently used entry goes
+	require.NoError(t, c.Put(ctx, 5, "five"))
+	require.Equal(t, []int{3, 1}, evicted)
+
+	clock.Advance(700 * time.Millisecond)
+	require.NoError(t, c.Put(ctx, 6, "six"))
+	require.Equal(t, []int{3, 1, 2}, evicted)
+}
+
+func TestExpiredEntriesAreRemoved(t *testing.T) {
+	ctx := context.Background()
+	clock := testhelper.NewFakeClock(time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC))
+	c, err := ttllru.New(
+		ttllru.WithCapacity[int, string](2),
+		ttllru.WithDefaultTTL[int, string](100*time.Millisecond),
+		ttllru.WithBucketSize[int, string](time.Second),
+		ttllru.WithClock[int, string](clock),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	_, found, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	clock.Advance(time.Second)
+	require.Eventually(t, func() bool {
+		size, err := c.Size()
+		return err == nil && size == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBackgroundSweep(t *testing.T) {
+	ctx := context.Background()
+	clock := testhelper.NewFakeClock(time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC))
+	var mu sync.Mutex
+	var evicted []int
+	c, err := ttllru.New(
+		ttllru.WithCapacity[int, string](10),
+		ttllru.WithBucketSize[int, string](time.Minute),
+		ttllru.WithBackgroundSweep[int, string](100*time.Millisecond),
+		ttllru.WithClock[int, string](clock),
+		ttllru.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			mu.Lock()
+			evicted = append(evicted, k)
+			mu.Unlock()
+		}),
+	)
+	require.NoError(t, err)
+	defer c.Shutdown(ctx)
+
+	require.NoError(t, c.PutWithTTL(ctx, 1, "one", 50*time.Millisecond))
+	require.NoError(t, c.PutWithTTL(ctx, 2, "two", 80*time.Millisecond))
+	require.NoError(t, c.PutWithTTL(ctx, 3, "three", 10*time.Second))
+	require.NoError(t, c.Put(ctx, 4, "four"))
+
+	// the one-minute buckets have not passed, but the sweep removes 1 and 2
+	clock.Advance(100 * time.Millisecond)
+	require.Eventually(t, func() bool {
+		size, err := c.Size()
+		return err == nil && size == 2
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	require.ElementsMatch(t, []int{1, 2}, evicted)
+	mu.Unlock()
+
+	// the sweep keeps running; when the bucket fires it finds nothing left.
+	// The clock moves in steps since the sweep rearms its timer
+	// asynchronously.
+	clock.Advance(10 * time.Second)
+	require.Eventually(t, func() bool {
+		clock.Advance(100 * time.Millisecond)
+		size, err := c.Size()
+		return err == nil && size == 1
+	}, time.Second, 5*time.Millisecond)
+	clock.Advance(time.Minute)
+	mu.Lock()
+	require.ElementsMatch(t, []int{1, 2, 3}, evicted)
+	mu.Unlock()
+	found, err := c.Has(ctx, 4)
+	require.NoError(t, err)
+	require.True(t, found)
+}
+
+func TestBackgroundSweepInvalid(t *testing.T) {
+	_, err := ttllru.New(
+		ttllru.WithCapacity[int, string](1),
+		ttllru.WithBackgroundSweep[int, string](-time.Second),
+	)
+	var aerr *cachetypes.InvalidOptionsError
+	require.ErrorAs(t, err, &aerr)
+	require.Equal(t, "sweep interval must not be negative", aerr.Error())
+}
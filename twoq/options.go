@@ -0,0 +1,72 @@
+package twoq
+
+import (
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Options defines configuration for the 2Q cache.
+// It embeds base cache options for capacity and eviction callback,
+// and adds the sizes of the A1in and A1out queues.
+type Options[K comparable, V any] struct {
+	Base cachetypes.Options
+	// InRatio is the share of the capacity reserved for A1in, the FIFO of
+	// entries seen once; it must be in [0, 1), and 0 selects defaultInRatio.
+	InRatio float64
+	// OutRatio sizes A1out, the ghost queue of keys recently evicted from
+	// A1in, relative to the capacity; it must not be negative, and 0 selects
+	// defaultOutRatio.
+	OutRatio float64
+}
+
+// WithCapacity sets the capacity in base options.
+func WithCapacity[K comparable, V any](capacity uint) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.Capacity = capacity }
+}
+
+// WithEvictionCB sets the eviction callback in base options.
+func WithEvictionCB[K comparable, V any](cb cachetypes.CBFunc[K, V]) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.OnEvict = cb }
+}
+
+// WithEvictionChannel sets the eviction channel in base options.
+func WithEvictionChannel[K comparable, V any](ch chan<- cachetypes.Entry[K, V]) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.EvictionChannel = ch }
+}
+
+// WithBatchEvictionCB sets the batch eviction callback in base options.
+func WithBatchEvictionCB[K comparable, V any](cb cachetypes.BatchCBFunc[K, V], chunkSize uint) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { cachetypes.WithBatchEvictionCB(cb, chunkSize)(&o.Base) }
+}
+
+// WithPanicHandler sets the eviction callback panic handler in base options.
+func WithPanicHandler[K comparable, V any](h func(recovered any)) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.PanicHandler = h }
+}
+
+// WithEvictionPanicPolicy sets the eviction callback panic policy in base options.
+func WithEvictionPanicPolicy[K comparable, V any](policy cachetypes.EvictionPanicPolicy) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.Base.EvictionPanicPolicy = policy }
+}
+
+// WithMaxKeyBytes sets the maximum key size and its sizer in base options.
+func WithMaxKeyBytes[K comparable, V any](maxBytes uint, sizer func(K) int) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { cachetypes.WithMaxKeyBytes(maxBytes, sizer)(&o.Base) }
+}
+
+// WithMaxValueBytes sets the maximum value size and its sizer in base options.
+func WithMaxValueBytes[K comparable, V any](maxBytes uint, sizer func(V) int) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { cachetypes.WithMaxValueBytes(maxBytes, sizer)(&o.Base) }
+}
+
+// WithInRatio sets the share of the capacity given to A1in. A larger A1in
+// lets entries that are re-referenced after a longer gap reach the main LRU;
+// a smaller one protects the main LRU better against scans.
+func WithInRatio[K comparable, V any](ratio float64) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.InRatio = ratio }
+}
+
+// WithOutRatio sets how many evicted keys A1out remembers, relative to the
+// capacity. A key put again while remembered goes straight to the main LRU.
+func WithOutRatio[K comparable, V any](ratio float64) func(*Options[K, V]) {
+	return func(o *Options[K, V]) { o.OutRatio = ratio }
+}
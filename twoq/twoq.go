@@ -0,0 +1,368 @@
+// Package twoq provides a fixed-capacity cache evicted with the 2Q algorithm
+// (Johnson and Shasha). A key seen for the first time enters A1in, a small
+// FIFO, and leaves it in insertion order regardless of hits. The keys it
+// evicts are remembered, without their values, in the ghost queue A1out; a
+// key put again while remembered has proven reuse and enters Am, the main
+// LRU. A sequential scan therefore only churns A1in and cannot flush the
+// frequently used entries in Am, at a fraction of the bookkeeping of ARC.
+package twoq
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal"
+	"github.com/mcphone2004/cache/internal/list"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+const (
+	// defaultInRatio is the share of the capacity given to A1in when
+	// InRatio is unset, as recommended by the 2Q paper.
+	defaultInRatio = 0.25
+	// defaultOutRatio sizes A1out when OutRatio is unset, as recommended by
+	// the 2Q paper.
+	defaultOutRatio = 0.5
+)
+
+// entry is a resident key-value pair; hot records whether it lives in Am.
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	hot   bool
+}
+
+// Cache is a thread-safe 2Q cache.
+type Cache[K comparable, V any] struct {
+	mu         sync.Mutex
+	isShutdown bool
+
+	items  map[K]*list.Entry[entry[K, V]]
+	in     list.List[entry[K, V]] // A1in, newest first
+	am     list.List[entry[K, V]] // Am, most recently used first
+	ghosts map[K]*list.Entry[K]
+	out    list.List[K] // A1out, newest first
+
+	capacity int
+	kin      int // share of A1in once the cache is full
+	kout     int // maximum number of keys in A1out
+
+	evictor   *internal.Evictor[K, V]
+	sizeLimit internal.SizeLimit[K, V]
+}
+
+// Ensure Cache implements the Cache interface.
+var _ iface.Cache[string, int] = (*Cache[string, int])(nil)
+
+// New creates a new 2Q cache.
+func New[K comparable, V any](options ...func(o *Options[K, V])) (*Cache[K, V], error) {
+	var o Options[K, V]
+	for _, cb := range options {
+		cb(&o)
+	}
+
+	base, err := internal.ToOptions[K, V](o.Base)
+	if err != nil {
+		return nil, err
+	}
+	if o.InRatio < 0 || o.InRatio >= 1 {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "in ratio must be in [0, 1)",
+		}
+	}
+	if o.OutRatio < 0 {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "out ratio must not be negative",
+		}
+	}
+	inRatio := o.InRatio
+	if inRatio == 0 {
+		inRatio = defaultInRatio
+	}
+	outRatio := o.OutRatio
+	if outRatio == 0 {
+		outRatio = defaultOutRatio
+	}
+
+	capacity := int(base.Capacity) //nolint:gosec // capacity is validated positive above
+	c := &Cache[K, V]{
+		items:     make(map[K]*list.Entry[entry[K, V]], capacity),
+		ghosts:    make(map[K]*list.Entry[K]),
+		capacity:  capacity,
+		kin:       int(float64(capacity) * inRatio),
+		kout:      int(float64(capacity) * outRatio),
+		evictor:   internal.NewEvictor(base),
+		sizeLimit: base.SizeLimit,
+	}
+	c.in.Init()
+	c.am.Init()
+	c.out.Init()
+	return c, nil
+}
+
+// Get retrieves a value from the cache. A hit in Am makes the entry the most
+// recently used; a hit in A1in leaves its FIFO position unchanged.
+func (c *Cache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero V
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	if elem.Value.hot {
+		_ = c.am.MoveToFront(elem)
+	}
+	return elem.Value.value, true, nil
+}
+
+// Has reports whether the key is present without updating its position.
+func (c *Cache[K, V]) Has(_ context.Context, key K) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return false, cachetypes.ErrShutdown
+	}
+	_, ok := c.items[key]
+	return ok, nil
+}
+
+// Put inserts or updates a value in the cache. A new key enters Am if A1out
+// remembers it and A1in otherwise; when the cache is full, A1in gives up its
+// oldest entry if it is over its share of the capacity, and Am its least
+// recently used one otherwise. It returns cachetypes.ErrEntryTooLarge if the
+// entry exceeds a configured size limit.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	if elem, ok := c.items[key]; ok {
+		elem.Value.value = value
+		if elem.Value.hot {
+			_ = c.am.MoveToFront(elem)
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	ghost, hot := c.ghosts[key]
+	if hot {
+		delete(c.ghosts, key)
+		c.out.Remove(ghost)
+	}
+	var evicted entry[K, V]
+	var hasEvicted bool
+	if len(c.items) >= c.capacity {
+		evicted = c.reclaim(!hot)
+		hasEvicted = true
+	}
+	if hot {
+		c.items[key] = c.am.PushFront(entry[K, V]{key: key, value: value, hot: true})
+	} else {
+		c.items[key] = c.in.PushFront(entry[K, V]{key: key, value: value})
+	}
+	c.mu.Unlock()
+	if hasEvicted {
+		c.evictor.Evict(ctx, evicted.key, evicted.value)
+	}
+	return nil
+}
+
+// reclaim removes and returns one entry to make room for a new one, which
+// goes to A1in if toIn is set, remembering it in A1out if it came from A1in.
+// A1in gives up an entry if it would otherwise exceed kin or Am is empty.
+// It must be called with the lock held and the cache full.
+func (c *Cache[K, V]) reclaim(toIn bool) entry[K, V] {
+	n := c.in.Size()
+	if n > 0 && (n > c.kin || (toIn && n == c.kin) || c.am.Size() == 0) {
+		en := c.remove(c.in.Back())
+		c.remember(en.key)
+		return en
+	}
+	return c.remove(c.am.Back())
+}
+
+// remember adds key to A1out, forgetting the oldest key beyond kout.
+func (c *Cache[K, V]) remember(key K) {
+	if c.kout == 0 {
+		return
+	}
+	c.ghosts[key] = c.out.PushFront(key)
+	for c.out.Size() > c.kout {
+		old, _ := c.out.PopBack()
+		delete(c.ghosts, old)
+	}
+}
+
+// remove unlinks elem from its queue and returns its entry. It must be called
+// with the lock held.
+func (c *Cache[K, V]) remove(elem *list.Entry[entry[K, V]]) entry[K, V] {
+	en := elem.Value
+	delete(c.items, en.key)
+	if en.hot {
+		c.am.Remove(elem)
+	} else {
+		c.in.Remove(elem)
+	}
+	return en
+}
+
+// Replace updates the value of an existing key and returns the previous
+// value, touching the entry as Put does. It does nothing if the key is absent.
+func (c *Cache[K, V]) Replace(_ context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if err := c.sizeLimit.Check(key, value); err != nil {
+		return zero, false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	old := elem.Value.value
+	elem.Value.value = value
+	if elem.Value.hot {
+		_ = c.am.MoveToFront(elem)
+	}
+	return old, true, nil
+}
+
+// Delete removes the entry with the specified key from the cache.
+// If the entry exists and is removed, it triggers the onEvict callback.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	_, found, err := c.GetAndDelete(ctx, key)
+	return found, err
+}
+
+// GetAndDelete atomically removes the entry and returns its value.
+// If the entry exists and is removed, it triggers the onEvict callback.
+// Deleted keys are not remembered in A1out.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	var zero V
+	if c.isShutdown {
+		c.mu.Unlock()
+		return zero, false, cachetypes.ErrShutdown
+	}
+	elem, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return zero, false, nil
+	}
+	en := c.remove(elem)
+	c.mu.Unlock() // Unlock before callback to avoid deadlock
+	c.evictor.Evict(ctx, en.key, en.value)
+	return en.value, true, nil
+}
+
+// Size returns the current number of items in the cache.
+func (c *Cache[K, V]) Size() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return len(c.items), nil
+}
+
+// Capacity returns the maximum number of items the cache can hold.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShutdown {
+		return 0, cachetypes.ErrShutdown
+	}
+	return c.capacity, nil
+}
+
+// Reset clears the cache, including A1out, and calls the eviction callback
+// for each removed item.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := c.drain()
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	return nil
+}
+
+// drain empties all queues and returns the resident entries; the caller
+// holds mu.
+func (c *Cache[K, V]) drain() []cachetypes.Entry[K, V] {
+	entries := c.snapshot()
+	c.in.Clear()
+	c.am.Clear()
+	c.out.Clear()
+	clear(c.items)
+	clear(c.ghosts)
+	return entries
+}
+
+// snapshot returns the resident entries, Am from most to least recently used
+// followed by A1in from newest to oldest; the caller holds mu.
+func (c *Cache[K, V]) snapshot() []cachetypes.Entry[K, V] {
+	entries := make([]cachetypes.Entry[K, V], 0, len(c.items))
+	for elem := range c.am.Seq() {
+		entries = append(entries, cachetypes.Entry[K, V]{Key: elem.Value.key, Value: elem.Value.value})
+	}
+	for elem := range c.in.Seq() {
+		entries = append(entries, cachetypes.Entry[K, V]{Key: elem.Value.key, Value: elem.Value.value})
+	}
+	return entries
+}
+
+// Traverse calls fn for each entry until fn returns false, visiting Am from
+// most to least recently used and then A1in from newest to oldest, without
+// updating positions. fn runs on a snapshot taken under the lock, so it may
+// call back into the cache.
+func (c *Cache[K, V]) Traverse(ctx context.Context,
+	fn func(context.Context, K, V) bool) error {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return cachetypes.ErrShutdown
+	}
+	entries := c.snapshot()
+	c.mu.Unlock()
+	for _, en := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(ctx, en.Key, en.Value) {
+			break
+		}
+	}
+	return nil
+}
+
+// Shutdown clears the cache, calling the eviction callback for each item.
+// Every later operation returns ErrShutdown.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.mu.Lock()
+	if c.isShutdown {
+		c.mu.Unlock()
+		return
+	}
+	c.isShutdown = true
+	entries := c.drain()
+	c.items = nil
+	c.ghosts = nil
+	c.mu.Unlock()
+	c.evictor.EvictAll(ctx, entries)
+	c.evictor.Close(ctx)
+}
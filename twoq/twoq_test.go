@@ -0,0 +1,205 @@
+package twoq_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/iface"
+	"github.com/mcphone2004/cache/internal/testhelper"
+	"github.com/mcphone2004/cache/twoq"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func newCache[K comparable, T any](capacity uint, evictionCB func(context.Context, K, T)) (iface.Cache[K, T], error) {
+	return twoq.New(
+		twoq.WithCapacity[K, T](capacity),
+		twoq.WithEvictionCB[K, T](evictionCB),
+	)
+}
+
+func TestNewCache(t *testing.T) {
+	cache, err := twoq.New[int, string]()
+	require.Nil(t, cache)
+	var aerr *cachetypes.InvalidOptionsError
+	require.True(t, errors.As(err, &aerr))
+	require.Equal(t, "capacity must be positive", aerr.Error())
+}
+
+func TestReset(t *testing.T) {
+	testhelper.CommonLRUResetTest(t, newCache)
+}
+
+// TestBasic relies on entries seen once being evicted in insertion order,
+// which the A1in FIFO shares with LRU.
+func TestBasic(t *testing.T) {
+	testhelper.CommonLRUCacheBasicTest(t, newCache)
+}
+
+func TestUpdate(t *testing.T) {
+	testhelper.CommonLRUCacheUpdateTest(t, newCache)
+}
+
+func TestTraverse(t *testing.T) {
+	testhelper.CommonTraverseTest(t, newCache)
+}
+
+func TestTraverseReentrant(t *testing.T) {
+	testhelper.CommonTraverseReentrantTest(t, newCache)
+}
+
+func TestTraverseCancel(t *testing.T) {
+	testhelper.CommonTraverseCancelTest(t, newCache)
+}
+
+func TestDelete(t *testing.T) {
+	testhelper.CommonDeleteTest(t, newCache)
+	testhelper.CommonDeleteNonExistentTest(t, newCache)
+}
+
+func TestGetMultiIter(t *testing.T) {
+	testhelper.CommonGetMultiIterTest(t, newCache)
+}
+
+func TestHas(t *testing.T) {
+	testhelper.CommonHasTest(t, newCache)
+}
+
+func TestGetAndDelete(t *testing.T) {
+	testhelper.CommonGetAndDeleteTest(t, newCache)
+}
+
+func TestReplace(t *testing.T) {
+	testhelper.CommonReplaceTest(t, newCache)
+}
+
+func TestShutdown(t *testing.T) {
+	testhelper.CommonShutdownTest(t, newCache)
+}
+
+func TestEvictionCallback(t *testing.T) {
+	testhelper.CommonEvictionCallbackTest(t, newCache)
+	testhelper.CommonUpdateNoEvictionTest(t, newCache)
+	testhelper.CommonEvictionCallbackPanicTest(t, newCache)
+}
+
+func TestConcurrent(t *testing.T) {
+	testhelper.CommonConcurrentTest(t, newCache)
+	testhelper.CommonConcurrentStressTest(t, newCache)
+}
+
+func TestCapacity(t *testing.T) {
+	testhelper.CommonCapacityTest(t, newCache, testhelper.ExactCapacity)
+}
+
+func TestStressShutdown(t *testing.T) {
+	testhelper.CommonStressShutdownTest(t, newCache[int, string])
+}
+
+func TestInvalidOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		option  func(*twoq.Options[int, string])
+		message string
+	}{
+		{"negative in ratio", twoq.WithInRatio[int, string](-0.1), "in ratio must be in [0, 1)"},
+		{"in ratio of one", twoq.WithInRatio[int, string](1), "in ratio must be in [0, 1)"},
+		{"negative out ratio", twoq.WithOutRatio[int, string](-1), "out ratio must not be negative"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cache, err := twoq.New(twoq.WithCapacity[int, string](4), tc.option)
+			require.Nil(t, cache)
+			var aerr *cachetypes.InvalidOptionsError
+			require.ErrorAs(t, err, &aerr)
+			require.Equal(t, tc.message, aerr.Error())
+		})
+	}
+}
+
+// newRecordingCache returns a 2Q cache of capacity 4 with a single A1in
+// slot, and the keys it evicts in order.
+func newRecordingCache(t *testing.T, options ...func(*twoq.Options[int, string])) (
+	*twoq.Cache[int, string], *[]int) {
+	t.Helper()
+	var evicted []int
+	options = append([]func(*twoq.Options[int, string]){
+		twoq.WithCapacity[int, string](4),
+		twoq.WithInRatio[int, string](0.25),
+		twoq.WithEvictionCB(func(_ context.Context, k int, _ string) {
+			evicted = append(evicted, k)
+		}),
+	}, options...)
+	cache, err := twoq.New(options...)
+	require.NoError(t, err)
+	return cache, &evicted
+}
+
+func TestGhostPromotion(t *testing.T) {
+	ctx := context.Background()
+	cache, evicted := newRecordingCache(t)
+	defer cache.Shutdown(ctx)
+
+	for k := 1; k <= 5; k++ {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	require.Equal(t, []int{1}, *evicted)
+
+	// 1 is remembered in A1out, so putting it again admits it to Am and A1in
+	// gives up its oldest entry
+	require.NoError(t, cache.Put(ctx, 1, "v"))
+	require.Equal(t, []int{1, 2}, *evicted)
+
+	// with Am no longer empty and A1in over its share, A1in keeps losing its
+	// oldest entries while 1 stays
+	require.NoError(t, cache.Put(ctx, 6, "v"))
+	require.NoError(t, cache.Put(ctx, 7, "v"))
+	require.Equal(t, []int{1, 2, 3, 4}, *evicted)
+	found, err := cache.Has(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+}
+
+func TestScanResistance(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newRecordingCache(t, twoq.WithOutRatio[int, string](1))
+	defer cache.Shutdown(ctx)
+
+	// make 1, 2 and 3 hot: seen, evicted to A1out, then seen again
+	for _, k := range []int{1, 2, 3, 100, 101, 102, 103, 1, 2, 3} {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	// a long scan of keys seen once only cycles through A1in
+	for k := 1000; k < 2000; k++ {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	for _, k := range []int{1, 2, 3} {
+		_, found, err := cache.Get(ctx, k)
+		require.NoError(t, err)
+		require.True(t, found, "key %d", k)
+	}
+}
+
+func TestAmEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache, evicted := newRecordingCache(t, twoq.WithOutRatio[int, string](1))
+	defer cache.Shutdown(ctx)
+
+	// promote 1, 2 and 3 to Am, leaving A1in with 103
+	for _, k := range []int{1, 2, 3, 100, 101, 102, 103, 1, 2, 3} {
+		require.NoError(t, cache.Put(ctx, k, "v"))
+	}
+	*evicted = nil
+	// reading 1 leaves 2 as the least recently used entry of Am; 102 is in
+	// A1out, so it goes to Am and A1in keeps its single entry
+	_, _, err := cache.Get(ctx, 1)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, 102, "v")) // in A1out: goes to Am
+	require.Equal(t, []int{2}, *evicted)
+}
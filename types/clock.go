@@ -0,0 +1,20 @@
+package cachetypes
+
+import "time"
+
+// Clock abstracts the passage of time for TTL expiry so that tests can
+// control it. Implementations must be safe for concurrent use.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a Timer that fires once after d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the subset of *time.Timer used by the caches. Stop and Reset must
+// discard a pending tick, as *time.Timer does since Go 1.23.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
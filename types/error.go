@@ -1,6 +1,8 @@
 // Package cachetypes defines types used in the LRU cache implementation.
 package cachetypes
 
+import "fmt"
+
 // InvalidOptionsError represents an error for invalid options in the LRU cache.
 type InvalidOptionsError struct {
 	Message string
@@ -26,5 +28,109 @@ func (e *ShutdownError) Error() string {
 // ErrShutdown is a sentinel error returned by all cache operations after Shutdown is called.
 var ErrShutdown error = &ShutdownError{}
 
+// NotFoundError represents that the requested key is not in the cache
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Message == "" {
+		return "The key was not found in the cache"
+	}
+	return e.Message
+}
+
+// ErrNotFound is a sentinel error returned by error-based lookups when the key is absent.
+var ErrNotFound error = &NotFoundError{}
+
+// EntryTooLargeError represents that an entry exceeds the configured
+// maximum key or value size and was not admitted
+type EntryTooLargeError struct {
+	Message string
+}
+
+func (e *EntryTooLargeError) Error() string {
+	if e.Message == "" {
+		return "The entry exceeds the maximum size of the cache"
+	}
+	return e.Message
+}
+
+// ErrEntryTooLarge is a sentinel error returned by Put when the key or value
+// exceeds the limit set by WithMaxKeyBytes or WithMaxValueBytes.
+var ErrEntryTooLarge error = &EntryTooLargeError{}
+
+// CapacityExceededError reports which part of an entry was too large, its
+// measured size and the configured limit. It unwraps to ErrEntryTooLarge, so
+// errors.Is checks against the sentinel keep matching.
+type CapacityExceededError struct {
+	// Field is "key" or "value".
+	Field string
+	// Size is the size reported by the sizer for the rejected entry.
+	Size int
+	// Limit is the maximum size set by WithMaxKeyBytes or WithMaxValueBytes.
+	Limit int
+}
+
+func (e *CapacityExceededError) Error() string {
+	return fmt.Sprintf("%s size %d exceeds the limit of %d", e.Field, e.Size, e.Limit)
+}
+
+// Unwrap returns ErrEntryTooLarge.
+func (e *CapacityExceededError) Unwrap() error {
+	return ErrEntryTooLarge
+}
+
+// ReadOnlyError represents an attempt to modify a cache through a read-only
+// view
+type ReadOnlyError struct {
+	Message string
+}
+
+func (e *ReadOnlyError) Error() string {
+	if e.Message == "" {
+		return "The cache is read-only"
+	}
+	return e.Message
+}
+
+// ErrReadOnly is a sentinel error returned by the mutating operations of a
+// read-only view, such as the one created by readonly.Wrap.
+var ErrReadOnly error = &ReadOnlyError{}
+
+// SnapshotError represents a failure to encode or decode a cache snapshot,
+// typically because a key or value type is not gob-encodable.
+type SnapshotError struct {
+	Message string
+	Err     error
+}
+
+func (e *SnapshotError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying encoding error.
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+// ShardError represents a failure to create one shard of a sharded cache.
+type ShardError struct {
+	Index uint
+	Err   error
+}
+
+func (e *ShardError) Error() string {
+	return fmt.Sprintf("cannot create shard %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error reported by the shard constructor.
+func (e *ShardError) Unwrap() error {
+	return e.Err
+}
+
 // Ensure ErrorInvalidOptions implements the error interface.
 var _ error = (*InvalidOptionsError)(nil)
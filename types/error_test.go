@@ -27,3 +27,27 @@ func TestErrShutdownSentinel(t *testing.T) {
 	var target *cachetypes.ShutdownError
 	require.ErrorAs(t, cachetypes.ErrShutdown, &target)
 }
+
+func TestNotFoundError(t *testing.T) {
+	err := &cachetypes.NotFoundError{}
+	require.Equal(t, "The key was not found in the cache", err.Error())
+
+	err2 := &cachetypes.NotFoundError{Message: "no such user"}
+	require.Equal(t, "no such user", err2.Error())
+
+	var target *cachetypes.NotFoundError
+	require.ErrorAs(t, cachetypes.ErrNotFound, &target)
+	require.NotErrorIs(t, cachetypes.ErrNotFound, cachetypes.ErrShutdown)
+}
+
+func TestReadOnlyError(t *testing.T) {
+	err := &cachetypes.ReadOnlyError{}
+	require.Equal(t, "The cache is read-only", err.Error())
+
+	err2 := &cachetypes.ReadOnlyError{Message: "plugins cannot write"}
+	require.Equal(t, "plugins cannot write", err2.Error())
+
+	var target *cachetypes.ReadOnlyError
+	require.ErrorAs(t, cachetypes.ErrReadOnly, &target)
+	require.NotErrorIs(t, cachetypes.ErrReadOnly, cachetypes.ErrShutdown)
+}
@@ -2,6 +2,7 @@ package cachetypes
 
 import (
 	"context"
+	"time"
 )
 
 // CBFunc is the type of a callback function that is invoked when an item
@@ -9,6 +10,30 @@ import (
 // of the evicted entry.
 type CBFunc[K comparable, V any] func(context.Context, K, V)
 
+// BatchCBFunc is the batch eviction callback. It receives the entries
+// removed together by Reset or Shutdown.
+type BatchCBFunc[K comparable, V any] func(context.Context, []Entry[K, V])
+
+// LoaderFunc fetches the value for key on a cache miss. It reports
+// found=false when the key does not exist in the source of truth.
+type LoaderFunc[K comparable, V any] func(ctx context.Context, key K) (value V, found bool, err error)
+
+// Entry is a key-value pair removed from the cache, as delivered on an
+// eviction channel.
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// EntryMeta is the metadata kept for each entry of a cache created with
+// WithEntryMetadata.
+type EntryMeta struct {
+	// CreatedAt is when the key was inserted; updating the value keeps it.
+	CreatedAt time.Time
+	// Hits counts the Gets that found the entry.
+	Hits uint64
+}
+
 // Options defines the configuration options for the LRU cache.
 type Options struct {
 	// Capacity is the maximum number of items the cache can hold.
@@ -17,6 +42,56 @@ type Options struct {
 	Capacity uint
 	// OnEvict is a callback function that is called when an item is evicted from the cache.
 	OnEvict any // Will cast to evictionCB[K, V] inside Cache
+	// EvictionChannel receives every evicted entry without blocking the cache.
+	EvictionChannel any // Will cast to chan<- Entry[K, V] inside Cache
+	// AsyncEviction runs OnEvict on a background worker instead of the caller.
+	AsyncEviction bool
+	// AsyncEvictionQueue is the number of pending callbacks buffered for the
+	// worker before evicting callers run them inline.
+	AsyncEvictionQueue uint
+	// OnBatchEvict is called with the entries removed by Reset and Shutdown.
+	OnBatchEvict any // Will cast to BatchCBFunc[K, V] inside Cache
+	// BatchEvictionSize limits the number of entries per OnBatchEvict call; 0 means no limit.
+	BatchEvictionSize uint
+	// PanicHandler receives values recovered from panicking eviction callbacks.
+	PanicHandler func(recovered any)
+	// EvictionPanicPolicy decides what happens when an eviction callback
+	// panics; the zero value is EvictionPanicRecover.
+	EvictionPanicPolicy EvictionPanicPolicy
+	// EntryPoolLimit caps the number of idle list entries kept for reuse; 0 means unbounded.
+	EntryPoolLimit uint
+	// MaxKeyBytes is the largest key size, as measured by KeySizer, that Put
+	// admits; 0 means no limit.
+	MaxKeyBytes uint
+	KeySizer    any // Will cast to func(K) int inside Cache
+	// MaxValueBytes is the largest value size, as measured by ValueSizer,
+	// that Put admits; 0 means no limit.
+	MaxValueBytes uint
+	ValueSizer    any // Will cast to func(V) int inside Cache
+	// ExpiryBucket is the granularity at which caches with a TTL group
+	// expirations; 0 selects the cache's default. It must not be negative.
+	ExpiryBucket time.Duration
+	// ValueCodec encodes values for Snapshot and Restore instead of gob.
+	ValueCodec any // Will cast to ValueCodec[V] inside Cache
+	// AdmissionPolicy enables TinyLFU admission: a new key that would evict
+	// the least recently used entry is dropped if that entry is used more
+	// often.
+	AdmissionPolicy bool
+	// Loader is called by Get on a miss to fetch and store the value.
+	Loader any // Will cast to LoaderFunc[K, V] inside Cache
+	// ResetHighWaterMark makes Reset also clear the recorded high-water mark.
+	ResetHighWaterMark bool
+	// OnResize is called with the old and new capacity after a Resize.
+	OnResize func(oldCapacity, newCapacity uint)
+	// EntryMetadata tracks an EntryMeta for every entry.
+	EntryMetadata bool
+}
+
+// ValueCodec converts values to and from bytes, e.g. with a protobuf
+// marshaler, for caches that persist their contents.
+type ValueCodec[V any] struct {
+	Marshal   func(V) ([]byte, error)
+	Unmarshal func([]byte) (V, error)
 }
 
 // WithCapacity sets the maximum capacity of the cache.
@@ -32,3 +107,195 @@ func WithEvictionCB[K comparable, V any](cb CBFunc[K, V]) func(o *Options) {
 		o.OnEvict = cb
 	}
 }
+
+// WithEvictionChannel sets a channel that receives every entry removed from the
+// cache (capacity eviction, expiry, Delete, Reset, and Shutdown). Sends never
+// block: when the channel is full the entry is dropped and counted instead.
+// It can be combined with WithEvictionCB; the channel is sent to first.
+// The channel must not be closed while the cache is in use.
+func WithEvictionChannel[K comparable, V any](ch chan<- Entry[K, V]) func(o *Options) {
+	return func(o *Options) {
+		o.EvictionChannel = ch
+	}
+}
+
+// WithAsyncEvictionCB sets an eviction callback that runs on a dedicated worker
+// goroutine, so a slow callback does not slow down Put. Up to queueSize
+// callbacks are buffered; beyond that, or with a queueSize of 0 while the
+// worker is busy, the evicting caller runs the callback itself, so callbacks
+// may run out of order. Shutdown waits for all pending callbacks, so caches
+// using this option must be shut down to release the worker.
+//
+// The callback may use the cache it is registered with. If it calls Shutdown
+// it must pass on the context it was called with, so that Shutdown returns
+// without waiting for the worker it is running on; with any other context it
+// deadlocks.
+func WithAsyncEvictionCB[K comparable, V any](cb CBFunc[K, V], queueSize uint) func(o *Options) {
+	return func(o *Options) {
+		o.OnEvict = cb
+		o.AsyncEviction = true
+		o.AsyncEvictionQueue = queueSize
+	}
+}
+
+// WithBatchEvictionCB sets a callback that receives the entries removed by
+// Reset and Shutdown in batches of at most chunkSize entries (0 means a single
+// batch) instead of one OnEvict call per entry. Capacity evictions, expiry,
+// and deletes still use the per-entry callback.
+func WithBatchEvictionCB[K comparable, V any](cb BatchCBFunc[K, V], chunkSize uint) func(o *Options) {
+	return func(o *Options) {
+		o.OnBatchEvict = cb
+		o.BatchEvictionSize = chunkSize
+	}
+}
+
+// WithPanicHandler sets the function that receives the value recovered when an
+// eviction callback panics, e.g. to route it to a logger or a metric. By
+// default the recovered value is printed to stdout.
+func WithPanicHandler(h func(recovered any)) func(o *Options) {
+	return func(o *Options) {
+		o.PanicHandler = h
+	}
+}
+
+// EvictionPanicPolicy selects how a panic raised by an eviction callback is
+// handled.
+type EvictionPanicPolicy int
+
+const (
+	// EvictionPanicRecover recovers the panic and passes the value to the
+	// PanicHandler, or prints it when none is set. It is the default.
+	EvictionPanicRecover EvictionPanicPolicy = iota
+	// EvictionPanicLog recovers the panic and writes the value with the stack
+	// of the panicking callback to the standard logger, then passes the value
+	// to the PanicHandler if one is set.
+	EvictionPanicLog
+	// EvictionPanicPropagate re-panics with the recovered value after passing
+	// it to the PanicHandler if one is set, so that bugs in callbacks surface
+	// in tests and crash reporters. Callbacks never run under the cache lock,
+	// so the cache stays usable, but the callbacks still pending in the same
+	// Reset or Shutdown are skipped.
+	EvictionPanicPropagate
+)
+
+// WithEvictionPanicPolicy sets how a panicking eviction callback is handled.
+// Swallowing every panic, as the default EvictionPanicRecover does, can hide
+// real bugs such as writes to a nil map; EvictionPanicPropagate lets them
+// crash instead. With an async eviction callback the panic is raised on the
+// worker goroutine and therefore terminates the program.
+func WithEvictionPanicPolicy(policy EvictionPanicPolicy) func(o *Options) {
+	return func(o *Options) {
+		o.EvictionPanicPolicy = policy
+	}
+}
+
+// WithEntryPoolLimit caps how many idle entries the cache keeps for reuse.
+// By default every removed entry is pooled, which for very large caches can
+// pin memory after a Reset; with a limit, the surplus is garbage collected.
+func WithEntryPoolLimit(limit uint) func(o *Options) {
+	return func(o *Options) {
+		o.EntryPoolLimit = limit
+	}
+}
+
+// WithMaxKeyBytes makes Put reject keys whose size, as reported by sizer,
+// exceeds maxBytes with a *CapacityExceededError matching ErrEntryTooLarge.
+// A limit of 0 disables the check.
+func WithMaxKeyBytes[K comparable](maxBytes uint, sizer func(K) int) func(o *Options) {
+	return func(o *Options) {
+		o.MaxKeyBytes = maxBytes
+		o.KeySizer = sizer
+	}
+}
+
+// WithMaxValueBytes makes Put reject values whose size, as reported by sizer,
+// exceeds maxBytes with a *CapacityExceededError matching ErrEntryTooLarge,
+// so a single giant value cannot evict everything else. A limit of 0
+// disables the check.
+func WithMaxValueBytes[V any](maxBytes uint, sizer func(V) int) func(o *Options) {
+	return func(o *Options) {
+		o.MaxValueBytes = maxBytes
+		o.ValueSizer = sizer
+	}
+}
+
+// WithExpiryBucket sets the granularity at which caches with a TTL group
+// expirations. Expiry times are rounded up to a multiple of d: smaller
+// buckets expire entries more precisely but create more timers and heap
+// churn. Only caches with a TTL, such as tlru and ttllru, support it; other
+// caches reject it with an InvalidOptionsError.
+func WithExpiryBucket(d time.Duration) func(o *Options) {
+	return func(o *Options) {
+		o.ExpiryBucket = d
+	}
+}
+
+// WithValueCodec sets the functions Snapshot and Restore use to encode values,
+// so that values which gob cannot encode, such as protobuf messages, can be
+// persisted without being registered with gob. Keys are still encoded with
+// gob. A snapshot must be restored with the same codec it was written with.
+func WithValueCodec[V any](marshal func(V) ([]byte, error),
+	unmarshal func([]byte) (V, error)) func(o *Options) {
+	return func(o *Options) {
+		o.ValueCodec = ValueCodec[V]{Marshal: marshal, Unmarshal: unmarshal}
+	}
+}
+
+// WithAdmissionPolicy enables a TinyLFU-style admission filter, which reduces
+// pollution from scans and other one-off keys. Access frequencies are
+// estimated with a small count-min sketch that is periodically aged. When a
+// Put of a new key would evict the least recently used entry, the key is
+// dropped instead if that entry is estimated to be used more often. Put
+// still returns nil in that case. Only lru supports it; other caches reject it
+// with an InvalidOptionsError.
+func WithAdmissionPolicy() func(o *Options) {
+	return func(o *Options) {
+		o.AdmissionPolicy = true
+	}
+}
+
+// WithLoader makes Get call loader on a miss. When the loader reports the key
+// as found, the value is stored with Put and returned; otherwise Get reports
+// a miss. Loader errors are returned and nothing is cached. The loader runs
+// without the cache lock held, so concurrent misses for the same key may each
+// call it; wrap the loader with singleflight if that matters. Hits take the
+// same single-lock path as without a loader. It is supported by lru and lru2,
+// and by shard when its shards are built from either.
+func WithLoader[K comparable, V any](loader LoaderFunc[K, V]) func(o *Options) {
+	return func(o *Options) {
+		o.Loader = loader
+	}
+}
+
+// WithResetHighWaterMark makes Reset and ResetQuiet also clear the largest
+// size the cache has reached, so that HighWaterMark reports the peak since the
+// last reset rather than since creation. Only lru supports it; other caches
+// reject it with an InvalidOptionsError.
+func WithResetHighWaterMark() func(o *Options) {
+	return func(o *Options) {
+		o.ResetHighWaterMark = true
+	}
+}
+
+// WithResizeCB sets a callback that Resize calls with the old and new
+// capacity once the new capacity is applied and any entries beyond it are
+// evicted, e.g. so that a coordinator sharing a memory budget across caches
+// can rebalance. It runs without the cache lock held and is not called when
+// the capacity does not change. Only lru supports it; other caches reject it
+// with an InvalidOptionsError.
+func WithResizeCB(cb func(oldCapacity, newCapacity uint)) func(o *Options) {
+	return func(o *Options) {
+		o.OnResize = cb
+	}
+}
+
+// WithEntryMetadata records the insertion time and hit count of every entry,
+// reported by TraverseMeta, e.g. to compute the average entry age or the hit
+// distribution. It costs an allocation per inserted key and a pointer per
+// entry, so it is off by default. Only lru supports it; other caches reject it
+// with an InvalidOptionsError.
+func WithEntryMetadata() func(o *Options) {
+	return func(o *Options) {
+		o.EntryMetadata = true
+	}
+}
@@ -3,17 +3,43 @@ package cacheutils
 
 import (
 	"context"
+	"errors"
 	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mcphone2004/cache/iface"
+	cachetypes "github.com/mcphone2004/cache/types"
 )
 
+// GetErr retrieves a value from the cache like Get, but reports a miss as
+// [cachetypes.ErrNotFound] instead of a false boolean. Use errors.Is to
+// distinguish a miss from other failures such as [cachetypes.ErrShutdown].
+func GetErr[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], key K) (V, error) {
+
+	v, found, err := c.Get(ctx, key)
+	if err != nil {
+		return v, err
+	}
+	if !found {
+		return v, cachetypes.ErrNotFound
+	}
+	return v, nil
+}
+
 // GetMultiIter retrieves multiple values from the cache using an iterator.
+// It checks ctx before each key and returns the context error once ctx is
+// cancelled.
 func GetMultiIter[K comparable, V any](ctx context.Context,
 	c iface.Cache[K, V], keys iter.Seq[K],
 	hitCB func(K, V), missCB func(K)) error {
 
 	for k := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		v, found, err := c.Get(ctx, k)
 		if err != nil {
 			return err
@@ -27,11 +53,318 @@ func GetMultiIter[K comparable, V any](ctx context.Context,
 	return nil
 }
 
+// GetMultiIterContinue is like [GetMultiIter] but does not stop at a failed
+// Get: it passes the key and error to errCB and moves on to the next key, so
+// a flaky backing store still yields partial results. It returns the errors
+// joined with errors.Join, or nil if every Get succeeded. A cancelled ctx
+// still ends the iteration; its error is joined to those already seen.
+func GetMultiIterContinue[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], keys iter.Seq[K],
+	hitCB func(K, V), missCB func(K), errCB func(K, error)) error {
+
+	var errs []error
+	for k := range keys {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		v, found, err := c.Get(ctx, k)
+		switch {
+		case err != nil:
+			errCB(k, err)
+			errs = append(errs, err)
+		case found:
+			hitCB(k, v)
+		default:
+			missCB(k)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetMultiIterTimeout is like [GetMultiIter] but bounds the whole call to
+// timeout, or to the deadline of ctx if that comes first. Once the deadline
+// passes no further Gets are issued: the keys not yet attempted are passed to
+// skipCB and nil is returned, so a large fan-out to a network-backed cache
+// ends in bounded time with partial results. The Gets receive a context
+// carrying the deadline, and one that fails with context.DeadlineExceeded
+// after it passes is reported as skipped too. Any other Get error, or a
+// cancelled ctx, aborts the call and is returned.
+func GetMultiIterTimeout[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], keys iter.Seq[K], timeout time.Duration,
+	hitCB func(K, V), missCB func(K), skipCB func(K)) error {
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	expired := false
+	for k := range keys {
+		if expired {
+			skipCB(k)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			if !errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
+			expired = true
+			skipCB(k)
+			continue
+		}
+		v, found, err := c.Get(ctx, k)
+		switch {
+		case err != nil:
+			if !errors.Is(err, context.DeadlineExceeded) ||
+				!errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return err
+			}
+			expired = true
+			skipCB(k)
+		case found:
+			hitCB(k, v)
+		default:
+			missCB(k)
+		}
+	}
+	return nil
+}
+
+// Result is the outcome of looking up one key with [GetMultiSeq].
+type Result[V any] struct {
+	Value V
+	Found bool
+	// Err is set when the lookup failed; it is the last result yielded.
+	Err error
+}
+
+// GetMultiSeq returns a lazy iterator over the lookups of keys. Each key is
+// fetched only when the consumer asks for it, so breaking out of the range
+// loop leaves the remaining keys untouched. A failed Get, or a cancelled
+// ctx, is yielded as a Result with Err set and ends the iteration.
+func GetMultiSeq[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], keys iter.Seq[K]) iter.Seq2[K, Result[V]] {
+
+	return func(yield func(K, Result[V]) bool) {
+		for k := range keys {
+			if err := ctx.Err(); err != nil {
+				yield(k, Result[V]{Err: err})
+				return
+			}
+			v, found, err := c.Get(ctx, k)
+			if err != nil {
+				yield(k, Result[V]{Err: err})
+				return
+			}
+			if !yield(k, Result[V]{Value: v, Found: found}) {
+				return
+			}
+		}
+	}
+}
+
+// FilterPresent returns a lazy iterator over the keys that are present in
+// the cache, e.g. to drop already cached keys from a work list before
+// scheduling loads. It checks each key with Has, so the recency of cached
+// entries is not changed. Keys are checked only as the consumer asks for
+// them. A failed Has, or a cancelled ctx, ends the iteration; use
+// [FilterPresentErr] when such errors must be told apart from the end of keys.
+func FilterPresent[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], keys iter.Seq[K]) iter.Seq[K] {
+
+	return func(yield func(K) bool) {
+		for k, err := range FilterPresentErr(ctx, c, keys) {
+			if err != nil || !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// FilterPresentErr is like [FilterPresent] but reports errors: present keys
+// are yielded with a nil error, and a failed Has, or a cancelled ctx, is
+// yielded with the key being checked and ends the iteration.
+func FilterPresentErr[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], keys iter.Seq[K]) iter.Seq2[K, error] {
+
+	return func(yield func(K, error) bool) {
+		for k := range keys {
+			if err := ctx.Err(); err != nil {
+				yield(k, err)
+				return
+			}
+			found, err := c.Has(ctx, k)
+			if err != nil {
+				yield(k, err)
+				return
+			}
+			if found && !yield(k, nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetMultiIterParallel retrieves multiple values from the cache, issuing up to
+// concurrency Gets at the same time. It is most useful in front of a sharded
+// cache, where Gets for keys on different shards do not contend.
+//
+// hitCB and missCB are called concurrently from multiple goroutines and must
+// be safe for concurrent use. The order of callbacks is unspecified.
+//
+// On the first Get error, or once ctx is cancelled, no further keys are
+// dispatched; Gets already in flight are allowed to finish and the first
+// error is returned.
+// A concurrency of 1 or less behaves like [GetMultiIter].
+func GetMultiIterParallel[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], keys iter.Seq[K], concurrency int,
+	hitCB func(K, V), missCB func(K)) error {
+
+	if concurrency <= 1 {
+		return GetMultiIter(ctx, c, keys, hitCB, missCB)
+	}
+
+	return forEachParallel(ctx, withoutValues(keys), concurrency,
+		func(k K, _ struct{}) error {
+			v, found, err := c.Get(ctx, k)
+			if err != nil {
+				return err
+			}
+			if found {
+				hitCB(k, v)
+			} else {
+				missCB(k)
+			}
+			return nil
+		})
+}
+
+// PutMultiIter inserts every key/value pair yielded by entries.
+// It stops and returns the error of the first Put that fails.
+func PutMultiIter[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], entries iter.Seq2[K, V]) error {
+
+	for k, v := range entries {
+		if err := c.Put(ctx, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Warm populates the cache from src, issuing up to concurrency Puts at the
+// same time, e.g. to pre-fill a cache on startup faster than a single loop.
+// In front of a sharded cache, Puts for keys on different shards do not
+// contend, so the fill parallelizes across shards.
+//
+// src is consumed from the calling goroutine. On the first Put error, or once
+// ctx is cancelled, no further entries are dispatched; Puts already in flight
+// are allowed to finish and the first error is returned.
+// A concurrency of 1 or less puts the entries one at a time.
+func Warm[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], src iter.Seq2[K, V], concurrency int) error {
+
+	if concurrency <= 1 {
+		for k, v := range src {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := c.Put(ctx, k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return forEachParallel(ctx, src, concurrency, func(k K, v V) error {
+		return c.Put(ctx, k, v)
+	})
+}
+
+// forEachParallel calls fn for every pair yielded by seq, running up to
+// concurrency calls at the same time. seq is consumed from the calling
+// goroutine. On the first error returned by fn, or once ctx is cancelled, no
+// further pairs are dispatched; calls already in flight are allowed to finish
+// and the first error is returned.
+func forEachParallel[K, V any](ctx context.Context, seq iter.Seq2[K, V],
+	concurrency int, fn func(K, V) error) error {
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		failed   atomic.Bool
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			failed.Store(true)
+		})
+	}
+	sem := make(chan struct{}, concurrency)
+	for k, v := range seq {
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			fail(err)
+		}
+		if failed.Load() {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(k, v); err != nil {
+				fail(err)
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// withoutValues adapts keys to an iter.Seq2 with empty values.
+func withoutValues[K any](keys iter.Seq[K]) iter.Seq2[K, struct{}] {
+	return func(yield func(K, struct{}) bool) {
+		for k := range keys {
+			if !yield(k, struct{}{}) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteMultiIter deletes every key yielded by keys and returns how many of
+// them were present in the cache. It stops on the first Delete that fails and
+// returns the number deleted so far along with the error.
+func DeleteMultiIter[K comparable, V any](ctx context.Context,
+	c iface.Cache[K, V], keys iter.Seq[K]) (deleted int, err error) {
+
+	for k := range keys {
+		found, err := c.Delete(ctx, k)
+		if err != nil {
+			return deleted, err
+		}
+		if found {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
 // GetMulti retrieves multiple keys from the cache in one call.
-// It returns a map of hits and a slice of keys that were not found.
+// It returns a map of hits and a slice of keys that were not found; the hits
+// map is pre-sized to len(keys). It is the collecting counterpart of
+// [GetMultiIter] for callers that do not need per-key callbacks.
+// The first error from Get aborts the call and nil results are returned.
+// If c implements [iface.BatchGetter], all keys are looked up with a single
+// GetBatch call instead.
 func GetMulti[K comparable, V any](ctx context.Context,
 	c iface.Cache[K, V], keys []K) (hits map[K]V, misses []K, err error) {
 
+	if bg, ok := c.(iface.BatchGetter[K, V]); ok {
+		return getBatch(ctx, bg, keys)
+	}
 	hits = make(map[K]V, len(keys))
 	for _, k := range keys {
 		v, found, e := c.Get(ctx, k)
@@ -47,6 +380,27 @@ func GetMulti[K comparable, V any](ctx context.Context,
 	return hits, misses, nil
 }
 
+// getBatch implements GetMulti for a cache that implements
+// [iface.BatchGetter].
+func getBatch[K comparable, V any](ctx context.Context,
+	bg iface.BatchGetter[K, V], keys []K) (map[K]V, []K, error) {
+
+	hits, err := bg.GetBatch(ctx, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hits == nil {
+		hits = make(map[K]V)
+	}
+	var misses []K
+	for _, k := range keys {
+		if _, ok := hits[k]; !ok {
+			misses = append(misses, k)
+		}
+	}
+	return hits, misses, nil
+}
+
 // GetAndDelete atomically fetches a value and removes it from the cache in a
 // single operation. Returns the value and true if the key existed, or the zero
 // value and false if it did not.
@@ -54,15 +408,11 @@ func GetMulti[K comparable, V any](ctx context.Context,
 // Prefer this over a separate Get + Delete when you want to consume an entry
 // exactly once (e.g. work-queue or one-time token patterns), as it avoids a
 // second lock acquisition and eliminates the race between the two calls.
+// It is equivalent to calling [iface.Cache.GetAndDelete] directly.
 func GetAndDelete[K comparable, V any](ctx context.Context,
 	c iface.Cache[K, V], key K) (V, bool, error) {
 
-	v, found, err := c.Get(ctx, key)
-	if err != nil || !found {
-		return v, false, err
-	}
-	_, err = c.Delete(ctx, key)
-	return v, true, err
+	return c.GetAndDelete(ctx, key)
 }
 
 // PutIfNotExists inserts key/value only when the key is not already present.
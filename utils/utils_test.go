@@ -3,8 +3,15 @@ package cacheutils_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/mcphone2004/cache/iface"
@@ -190,6 +197,52 @@ func TestGetMulti_Error(t *testing.T) {
 	require.Nil(t, misses)
 }
 
+// batchCache is a cache that also implements iface.BatchGetter.
+type batchCache struct {
+	*iface.MockCache[int, string]
+	*iface.MockBatchGetter[int, string]
+}
+
+func TestGetMulti_UsesBatchGetter(t *testing.T) {
+	ctx := context.Background()
+	// Get must not be called: the mock fails the test on an unexpected call
+	bg := iface.NewMockBatchGetter[int, string](t)
+	bg.EXPECT().GetBatch(ctx, []int{1, 2, 3}).
+		Return(map[int]string{1: "one", 3: "three"}, nil).Once()
+	c := batchCache{iface.NewMockCache[int, string](t), bg}
+
+	hits, misses, err := cacheutils.GetMulti[int, string](ctx, c, []int{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "one", 3: "three"}, hits)
+	require.Equal(t, []int{2}, misses)
+}
+
+func TestGetMulti_BatchGetterError(t *testing.T) {
+	ctx := context.Background()
+	sentinel := errors.New("backend down")
+	bg := iface.NewMockBatchGetter[int, string](t)
+	bg.EXPECT().GetBatch(ctx, []int{1}).Return(nil, sentinel).Once()
+	c := batchCache{iface.NewMockCache[int, string](t), bg}
+
+	hits, misses, err := cacheutils.GetMulti[int, string](ctx, c, []int{1})
+	require.ErrorIs(t, err, sentinel)
+	require.Nil(t, hits)
+	require.Nil(t, misses)
+}
+
+func TestGetMulti_BatchGetterNilMap(t *testing.T) {
+	ctx := context.Background()
+	bg := iface.NewMockBatchGetter[int, string](t)
+	bg.EXPECT().GetBatch(ctx, []int{1, 2}).Return(nil, nil).Once()
+	c := batchCache{iface.NewMockCache[int, string](t), bg}
+
+	hits, misses, err := cacheutils.GetMulti[int, string](ctx, c, []int{1, 2})
+	require.NoError(t, err)
+	require.NotNil(t, hits)
+	require.Empty(t, hits)
+	require.Equal(t, []int{1, 2}, misses)
+}
+
 func TestPutIfNotExists_Insert(t *testing.T) {
 	ctx := context.Background()
 	c := newLRU(t)
@@ -282,3 +335,521 @@ func TestGetAndDelete_DeleteOnlyOnce(t *testing.T) {
 	require.Equal(t, "one", v)
 	require.Equal(t, 1, deletions) // eviction callback fired exactly once
 }
+
+func seq2Of[K comparable, V any](keys []K, vals []V) func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		for i, k := range keys {
+			if !yield(k, vals[i]) {
+				return
+			}
+		}
+	}
+}
+
+func TestPutMultiIter(t *testing.T) {
+	ctx := context.Background()
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Put(ctx, 1, "one").Return(nil).Once()
+	m.EXPECT().Put(ctx, 2, "two").Return(nil).Once()
+
+	err := cacheutils.PutMultiIter(ctx, m, seq2Of([]int{1, 2}, []string{"one", "two"}))
+	require.NoError(t, err)
+}
+
+func TestPutMultiIter_StopsOnError(t *testing.T) {
+	ctx := context.Background()
+	sentinel := errors.New("put failed")
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Put(ctx, 1, "one").Return(nil).Once()
+	m.EXPECT().Put(ctx, 2, "two").Return(sentinel).Once()
+	// key 3 must never be attempted; the mock fails the test on an unexpected call
+
+	err := cacheutils.PutMultiIter(ctx, m,
+		seq2Of([]int{1, 2, 3}, []string{"one", "two", "three"}))
+	require.ErrorIs(t, err, sentinel)
+}
+
+func TestDeleteMultiIter(t *testing.T) {
+	ctx := context.Background()
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Delete(ctx, 1).Return(true, nil).Once()
+	m.EXPECT().Delete(ctx, 2).Return(false, nil).Once()
+	m.EXPECT().Delete(ctx, 3).Return(true, nil).Once()
+
+	deleted, err := cacheutils.DeleteMultiIter(ctx, m, seqOf(1, 2, 3))
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+}
+
+func TestDeleteMultiIter_StopsOnError(t *testing.T) {
+	ctx := context.Background()
+	sentinel := errors.New("delete failed")
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Delete(ctx, 1).Return(true, nil).Once()
+	m.EXPECT().Delete(ctx, 2).Return(false, sentinel).Once()
+
+	deleted, err := cacheutils.DeleteMultiIter(ctx, m, seqOf(1, 2, 3))
+	require.ErrorIs(t, err, sentinel)
+	require.Equal(t, 1, deleted)
+}
+
+func TestGetMultiIterParallel_HitsAndMisses(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	for i := range 5 {
+		require.NoError(t, c.Put(ctx, i, strconv.Itoa(i)))
+	}
+
+	var mu sync.Mutex
+	hits := map[int]string{}
+	misses := []int{}
+	err := cacheutils.GetMultiIterParallel(ctx, c, seqOf(0, 1, 2, 3, 4, 5, 6), 3,
+		func(k int, v string) {
+			mu.Lock()
+			hits[k] = v
+			mu.Unlock()
+		},
+		func(k int) {
+			mu.Lock()
+			misses = append(misses, k)
+			mu.Unlock()
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{0: "0", 1: "1", 2: "2", 3: "3", 4: "4"}, hits)
+	require.ElementsMatch(t, []int{5, 6}, misses)
+}
+
+func TestGetMultiIterParallel_RespectsConcurrency(t *testing.T) {
+	ctx := context.Background()
+	const limit = 2
+	var inFlight, peak atomic.Int32
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Get(ctx, mock.Anything).RunAndReturn(
+		func(_ context.Context, _ int) (string, bool, error) {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+			return "", false, nil
+		}).Times(10)
+
+	var misses atomic.Int32
+	err := cacheutils.GetMultiIterParallel(ctx, m, seqOf(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), limit,
+		func(_ int, _ string) { t.Error("unexpected hit") },
+		func(_ int) { misses.Add(1) },
+	)
+	require.NoError(t, err)
+	require.Equal(t, int32(10), misses.Load())
+	require.LessOrEqual(t, peak.Load(), int32(limit))
+}
+
+func TestGetMultiIterParallel_PropagatesError(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	c.Shutdown(ctx)
+
+	var calls atomic.Int32
+	err := cacheutils.GetMultiIterParallel(ctx, c, seqOf(1, 2, 3, 4, 5, 6, 7, 8), 4,
+		func(_ int, _ string) { calls.Add(1) },
+		func(_ int) { calls.Add(1) },
+	)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	require.Zero(t, calls.Load())
+}
+
+func TestGetMultiIterParallel_Sequential(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	require.NoError(t, c.Put(ctx, 1, "one"))
+
+	hits := map[int]string{}
+	misses := []int{}
+	err := cacheutils.GetMultiIterParallel(ctx, c, seqOf(1, 2), 1,
+		func(k int, v string) { hits[k] = v },
+		func(k int) { misses = append(misses, k) },
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "one"}, hits)
+	require.Equal(t, []int{2}, misses)
+}
+
+func TestGetErr(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	require.NoError(t, c.Put(ctx, 1, "one"))
+
+	v, err := cacheutils.GetErr(ctx, c, 1)
+	require.NoError(t, err)
+	require.Equal(t, "one", v)
+
+	v, err = cacheutils.GetErr(ctx, c, 2)
+	require.ErrorIs(t, err, cachetypes.ErrNotFound)
+	require.Empty(t, v)
+
+	// a wrapped miss is still detectable
+	wrapped := fmt.Errorf("load user: %w", err)
+	require.ErrorIs(t, wrapped, cachetypes.ErrNotFound)
+
+	c.Shutdown(ctx)
+	_, err = cacheutils.GetErr(ctx, c, 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	require.NotErrorIs(t, err, cachetypes.ErrNotFound)
+}
+
+func TestGetMultiIter_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newLRU(t)
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.NoError(t, c.Put(ctx, 2, "two"))
+
+	hits := 0
+	err := cacheutils.GetMultiIter(ctx, c, seqOf(1, 2),
+		func(int, string) {
+			hits++
+			cancel()
+		},
+		func(int) { t.Fatal("unexpected miss") },
+	)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, hits)
+}
+
+func TestGetMultiIterContinue(t *testing.T) {
+	ctx := context.Background()
+	errA := errors.New("store unavailable")
+	errB := errors.New("timeout")
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Get(ctx, 1).Return("one", true, nil).Once()
+	m.EXPECT().Get(ctx, 2).Return("", false, errA).Once()
+	m.EXPECT().Get(ctx, 3).Return("", false, nil).Once()
+	m.EXPECT().Get(ctx, 4).Return("", false, errB).Once()
+	m.EXPECT().Get(ctx, 5).Return("five", true, nil).Once()
+
+	hits := map[int]string{}
+	var misses []int
+	failed := map[int]error{}
+	err := cacheutils.GetMultiIterContinue(ctx, m, seqOf(1, 2, 3, 4, 5),
+		func(k int, v string) { hits[k] = v },
+		func(k int) { misses = append(misses, k) },
+		func(k int, err error) { failed[k] = err },
+	)
+	require.ErrorIs(t, err, errA)
+	require.ErrorIs(t, err, errB)
+	require.Equal(t, map[int]string{1: "one", 5: "five"}, hits)
+	require.Equal(t, []int{3}, misses)
+	require.Equal(t, map[int]error{2: errA, 4: errB}, failed)
+}
+
+func TestGetMultiIterContinue_NoErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	require.NoError(t, c.Put(ctx, 1, "one"))
+
+	err := cacheutils.GetMultiIterContinue(ctx, c, seqOf(1, 2),
+		func(int, string) {},
+		func(int) {},
+		func(int, error) { t.Fatal("unexpected error callback") },
+	)
+	require.NoError(t, err)
+}
+
+func TestGetMultiIterContinue_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errA := errors.New("store unavailable")
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Get(ctx, 1).Return("", false, errA).Once()
+	// key 2 must never be attempted once ctx is cancelled
+
+	err := cacheutils.GetMultiIterContinue(ctx, m, seqOf(1, 2),
+		func(int, string) {},
+		func(int) {},
+		func(int, error) { cancel() },
+	)
+	require.ErrorIs(t, err, errA)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// slowCache blocks Get on the keys in slow until the context is done.
+type slowCache struct {
+	iface.Cache[int, string]
+	slow map[int]bool
+}
+
+func (c slowCache) Get(ctx context.Context, key int) (string, bool, error) {
+	if c.slow[key] {
+		<-ctx.Done()
+		return "", false, ctx.Err()
+	}
+	return c.Cache.Get(ctx, key)
+}
+
+func TestGetMultiIterTimeout(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.NoError(t, c.Put(ctx, 3, "three"))
+
+	hits := map[int]string{}
+	var misses, skipped []int
+	err := cacheutils.GetMultiIterTimeout(ctx,
+		slowCache{Cache: c, slow: map[int]bool{3: true}}, seqOf(1, 2, 3, 4, 5),
+		10*time.Millisecond,
+		func(k int, v string) { hits[k] = v },
+		func(k int) { misses = append(misses, k) },
+		func(k int) { skipped = append(skipped, k) },
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "one"}, hits)
+	require.Equal(t, []int{2}, misses)
+	require.Equal(t, []int{3, 4, 5}, skipped)
+}
+
+func TestGetMultiIterTimeout_NotReached(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	require.NoError(t, c.Put(ctx, 1, "one"))
+
+	var misses []int
+	err := cacheutils.GetMultiIterTimeout(ctx, c, seqOf(1, 2), time.Minute,
+		func(int, string) {},
+		func(k int) { misses = append(misses, k) },
+		func(int) { t.Fatal("unexpected skip") },
+	)
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, misses)
+}
+
+func TestGetMultiIterTimeout_Errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errA := errors.New("store unavailable")
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Get(mock.Anything, 1).Return("", false, errA).Once()
+
+	err := cacheutils.GetMultiIterTimeout(ctx, m, seqOf(1, 2), time.Minute,
+		func(int, string) {}, func(int) {}, func(int) {})
+	require.ErrorIs(t, err, errA)
+
+	// a cancelled ctx is an error, not a deadline
+	cancel()
+	err = cacheutils.GetMultiIterTimeout(ctx, m, seqOf(1, 2), time.Minute,
+		func(int, string) {}, func(int) {}, func(int) {})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGetMultiIterParallel_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := newLRU(t)
+
+	err := cacheutils.GetMultiIterParallel(ctx, c, seqOf(1, 2, 3), 2,
+		func(int, string) { t.Fatal("unexpected hit") },
+		func(int) { t.Fatal("unexpected miss") },
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGetMultiSeq(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.NoError(t, c.Put(ctx, 2, "two"))
+
+	got := map[int]cacheutils.Result[string]{}
+	for k, r := range cacheutils.GetMultiSeq(ctx, c, seqOf(1, 2, 3)) {
+		got[k] = r
+	}
+	require.Equal(t, map[int]cacheutils.Result[string]{
+		1: {Value: "one", Found: true},
+		2: {Value: "two", Found: true},
+		3: {},
+	}, got)
+}
+
+func TestGetMultiSeq_Lazy(t *testing.T) {
+	ctx := context.Background()
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Get(ctx, 1).Return("one", true, nil).Once()
+
+	for k, r := range cacheutils.GetMultiSeq[int, string](ctx, m, seqOf(1, 2, 3)) {
+		require.Equal(t, 1, k)
+		require.True(t, r.Found)
+		break // keys 2 and 3 must not be fetched
+	}
+}
+
+func TestGetMultiSeq_Error(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	c.Shutdown(ctx)
+
+	var results []cacheutils.Result[string]
+	for _, r := range cacheutils.GetMultiSeq(ctx, c, seqOf(1, 2)) {
+		results = append(results, r)
+	}
+	require.Len(t, results, 1)
+	require.ErrorIs(t, results[0].Err, cachetypes.ErrShutdown)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	results = results[:0]
+	for _, r := range cacheutils.GetMultiSeq(cctx, newLRU(t), seqOf(1, 2)) {
+		results = append(results, r)
+	}
+	require.Len(t, results, 1)
+	require.ErrorIs(t, results[0].Err, context.Canceled)
+}
+
+func TestFilterPresent(t *testing.T) {
+	ctx := context.Background()
+	c := newLRU(t)
+	for _, k := range []int{1, 3, 5} {
+		require.NoError(t, c.Put(ctx, k, strconv.Itoa(k)))
+	}
+
+	present := slices.Collect(cacheutils.FilterPresent(ctx, c, seqOf(1, 2, 3, 4, 5)))
+	require.Equal(t, []int{1, 3, 5}, present)
+
+	// Has does not promote: 1 is still the least recently used entry
+	lc := c.(*lru.Cache[int, string])
+	k, _, ok, err := lc.Oldest(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, k)
+}
+
+func TestFilterPresent_Lazy(t *testing.T) {
+	ctx := context.Background()
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Has(ctx, 1).Return(false, nil).Once()
+	m.EXPECT().Has(ctx, 2).Return(true, nil).Once()
+	// key 3 must not be checked once the consumer stops
+
+	for k := range cacheutils.FilterPresent(ctx, m, seqOf(1, 2, 3)) {
+		require.Equal(t, 2, k)
+		break
+	}
+}
+
+func TestFilterPresent_StopsOnError(t *testing.T) {
+	ctx := context.Background()
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Has(ctx, 1).Return(true, nil).Once()
+	m.EXPECT().Has(ctx, 2).Return(false, cachetypes.ErrShutdown).Once()
+
+	// the iteration ends at the error, and key 3 is not checked
+	require.Equal(t, []int{1}, slices.Collect(cacheutils.FilterPresent(ctx, m, seqOf(1, 2, 3))))
+}
+
+func TestFilterPresentErr(t *testing.T) {
+	ctx := context.Background()
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Has(ctx, 1).Return(true, nil).Once()
+	m.EXPECT().Has(ctx, 2).Return(false, cachetypes.ErrShutdown).Once()
+
+	// the error is yielded with its key, and key 3 is not checked
+	var keys []int
+	var errs []error
+	for k, err := range cacheutils.FilterPresentErr(ctx, m, seqOf(1, 2, 3)) {
+		keys = append(keys, k)
+		errs = append(errs, err)
+	}
+	require.Equal(t, []int{1, 2}, keys)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], cachetypes.ErrShutdown)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	yielded := 0
+	for k, err := range cacheutils.FilterPresentErr(cctx, m, seqOf(1, 2)) {
+		yielded++
+		require.Equal(t, 1, k)
+		require.ErrorIs(t, err, context.Canceled)
+	}
+	require.Equal(t, 1, yielded)
+}
+
+// entriesOf yields the pairs k, "v"+k for each k in [0, n).
+func entriesOf(n int) func(yield func(int, string) bool) {
+	return func(yield func(int, string) bool) {
+		for k := range n {
+			if !yield(k, "v"+strconv.Itoa(k)) {
+				return
+			}
+		}
+	}
+}
+
+func TestWarm(t *testing.T) {
+	ctx := context.Background()
+	for _, concurrency := range []int{0, 1, 4} {
+		t.Run(strconv.Itoa(concurrency), func(t *testing.T) {
+			c := newLRU(t)
+			require.NoError(t, cacheutils.Warm(ctx, c, entriesOf(10), concurrency))
+			size, err := c.Size()
+			require.NoError(t, err)
+			require.Equal(t, 10, size)
+			for k := range 10 {
+				v, found, err := c.Get(ctx, k)
+				require.NoError(t, err)
+				require.True(t, found)
+				require.Equal(t, "v"+strconv.Itoa(k), v)
+			}
+		})
+	}
+}
+
+func TestWarm_RespectsConcurrency(t *testing.T) {
+	ctx := context.Background()
+	const limit = 3
+	var inFlight, peak atomic.Int32
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Put(ctx, mock.Anything, mock.Anything).RunAndReturn(
+		func(context.Context, int, string) error {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+			return nil
+		}).Times(20)
+
+	require.NoError(t, cacheutils.Warm(ctx, m, entriesOf(20), limit))
+	require.LessOrEqual(t, peak.Load(), int32(limit))
+}
+
+func TestWarm_StopsOnError(t *testing.T) {
+	ctx := context.Background()
+	m := iface.NewMockCache[int, string](t)
+	m.EXPECT().Put(ctx, 0, "v0").Return(nil).Once()
+	m.EXPECT().Put(ctx, 1, "v1").Return(cachetypes.ErrShutdown).Once()
+	// later entries must not be put after the failure
+	err := cacheutils.Warm(ctx, m, entriesOf(5), 1)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+
+	var puts atomic.Int32
+	m2 := iface.NewMockCache[int, string](t)
+	m2.EXPECT().Put(ctx, mock.Anything, mock.Anything).RunAndReturn(
+		func(context.Context, int, string) error {
+			puts.Add(1)
+			return cachetypes.ErrShutdown
+		}).Maybe()
+	err = cacheutils.Warm(ctx, m2, entriesOf(1000), 2)
+	require.ErrorIs(t, err, cachetypes.ErrShutdown)
+	require.Less(t, puts.Load(), int32(1000))
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = cacheutils.Warm(cctx, iface.NewMockCache[int, string](t), entriesOf(5), 2)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,36 @@
+package writethrough
+
+import "context"
+
+// Writer persists a value to the backing store.
+type Writer[K comparable, V any] func(ctx context.Context, key K, value V) error
+
+// Deleter removes a key from the backing store.
+type Deleter[K comparable] func(ctx context.Context, key K) error
+
+// Options defines the configuration of the write-through cache.
+type Options[K comparable] struct {
+	// WriteAfter makes Put update the in-memory cache before calling the
+	// writer instead of after it. If the writer fails the in-memory update
+	// is rolled back.
+	WriteAfter bool
+	// Deleter, when set, is called by Delete and GetAndDelete before the key
+	// is removed from the in-memory cache.
+	Deleter Deleter[K]
+}
+
+// WithWriteAfter makes Put update the in-memory cache first and call the
+// writer afterwards.
+func WithWriteAfter[K comparable]() func(o *Options[K]) {
+	return func(o *Options[K]) {
+		o.WriteAfter = true
+	}
+}
+
+// WithDeleter sets the function that removes deleted keys from the backing
+// store.
+func WithDeleter[K comparable](d Deleter[K]) func(o *Options[K]) {
+	return func(o *Options[K]) {
+		o.Deleter = d
+	}
+}
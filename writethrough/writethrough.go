@@ -0,0 +1,171 @@
+// Package writethrough provides a cache decorator that writes every Put to a
+// backing store, the write-side counterpart of a read-through cache.
+package writethrough
+
+import (
+	"context"
+
+	"github.com/mcphone2004/cache/iface"
+	cachetypes "github.com/mcphone2004/cache/types"
+)
+
+// Ensure Cache satisfies iface.Cache at compile time.
+var _ iface.Cache[struct{}, struct{}] = (*Cache[struct{}, struct{}])(nil)
+
+// Cache wraps an iface.Cache and a Writer. Put writes the value to the
+// backing store and to the wrapped cache, and reports the writer's error
+// without leaving the wrapped cache updated. By default the writer runs
+// first; with WithWriteAfter the wrapped cache is updated first and rolled
+// back if the writer fails. The two steps are not atomic with respect to
+// concurrent writers of the same key.
+type Cache[K comparable, V any] struct {
+	inner      iface.Cache[K, V]
+	writer     Writer[K, V]
+	deleter    Deleter[K]
+	writeAfter bool
+}
+
+// New returns a Cache that writes every Put of c through writer. The Cache
+// takes ownership of c: Shutdown shuts it down.
+func New[K comparable, V any](c iface.Cache[K, V], writer Writer[K, V],
+	options ...func(o *Options[K])) (*Cache[K, V], error) {
+	var o Options[K]
+	for _, cb := range options {
+		cb(&o)
+	}
+	if writer == nil {
+		return nil, &cachetypes.InvalidOptionsError{
+			Message: "writer cannot be nil",
+		}
+	}
+	return &Cache[K, V]{
+		inner:      c,
+		writer:     writer,
+		deleter:    o.Deleter,
+		writeAfter: o.WriteAfter,
+	}, nil
+}
+
+// Get returns the value from the wrapped cache.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	return c.inner.Get(ctx, key)
+}
+
+// Has reports whether the key is present in the wrapped cache.
+func (c *Cache[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	return c.inner.Has(ctx, key)
+}
+
+// Put writes the value to the backing store and the wrapped cache. If the
+// writer fails its error is returned and the wrapped cache keeps its
+// previous content for key.
+func (c *Cache[K, V]) Put(ctx context.Context, key K, value V) error {
+	if !c.writeAfter {
+		if err := c.writer(ctx, key, value); err != nil {
+			return err
+		}
+		return c.inner.Put(ctx, key, value)
+	}
+
+	prev, hadPrev, err := c.inner.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := c.inner.Put(ctx, key, value); err != nil {
+		return err
+	}
+	if err := c.writer(ctx, key, value); err != nil {
+		c.rollback(ctx, key, prev, hadPrev)
+		return err
+	}
+	return nil
+}
+
+// rollback restores the wrapped cache content for key after a failed write.
+// Errors are ignored: the writer's error is the one reported to the caller.
+func (c *Cache[K, V]) rollback(ctx context.Context, key K, prev V, hadPrev bool) {
+	if hadPrev {
+		_ = c.inner.Put(ctx, key, prev)
+		return
+	}
+	_, _ = c.inner.Delete(ctx, key)
+}
+
+// Replace writes the value to the backing store and the wrapped cache if
+// the key is cached, and returns the previous cached value. It does nothing
+// if the key is absent. If the writer fails its error is returned and the
+// wrapped cache keeps its previous value.
+func (c *Cache[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	var zero V
+	if !c.writeAfter {
+		if found, err := c.inner.Has(ctx, key); err != nil || !found {
+			return zero, false, err
+		}
+		if err := c.writer(ctx, key, value); err != nil {
+			return zero, false, err
+		}
+		return c.inner.Replace(ctx, key, value)
+	}
+
+	old, found, err := c.inner.Replace(ctx, key, value)
+	if err != nil || !found {
+		return old, found, err
+	}
+	if err := c.writer(ctx, key, value); err != nil {
+		_, _, _ = c.inner.Replace(ctx, key, old)
+		return zero, false, err
+	}
+	return old, true, nil
+}
+
+// Delete removes the key from the backing store, if a Deleter is set, and
+// then from the wrapped cache. If the deleter fails the wrapped cache is
+// left untouched.
+func (c *Cache[K, V]) Delete(ctx context.Context, key K) (bool, error) {
+	if err := c.deleteFromStore(ctx, key); err != nil {
+		return false, err
+	}
+	return c.inner.Delete(ctx, key)
+}
+
+// GetAndDelete removes the key from the backing store, if a Deleter is set,
+// and then from the wrapped cache, returning the cached value.
+func (c *Cache[K, V]) GetAndDelete(ctx context.Context, key K) (V, bool, error) {
+	if err := c.deleteFromStore(ctx, key); err != nil {
+		var zero V
+		return zero, false, err
+	}
+	return c.inner.GetAndDelete(ctx, key)
+}
+
+func (c *Cache[K, V]) deleteFromStore(ctx context.Context, key K) error {
+	if c.deleter == nil {
+		return nil
+	}
+	return c.deleter(ctx, key)
+}
+
+// Size returns the number of entries in the wrapped cache.
+func (c *Cache[K, V]) Size() (int, error) {
+	return c.inner.Size()
+}
+
+// Capacity returns the capacity of the wrapped cache.
+func (c *Cache[K, V]) Capacity() (int, error) {
+	return c.inner.Capacity()
+}
+
+// Reset clears the wrapped cache. The backing store is not modified.
+func (c *Cache[K, V]) Reset(ctx context.Context) error {
+	return c.inner.Reset(ctx)
+}
+
+// Traverse visits the entries of the wrapped cache.
+func (c *Cache[K, V]) Traverse(ctx context.Context, fn func(context.Context, K, V) bool) error {
+	return c.inner.Traverse(ctx, fn)
+}
+
+// Shutdown shuts down the wrapped cache.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) {
+	c.inner.Shutdown(ctx)
+}
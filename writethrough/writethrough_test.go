@@ -0,0 +1,185 @@
+package writethrough_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mcphone2004/cache/lru"
+	cachetypes "github.com/mcphone2004/cache/types"
+	"github.com/mcphone2004/cache/writethrough"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// store is an in-memory backing store that can be made to fail.
+type store struct {
+	data map[int]string
+	err  error
+}
+
+func newStore() *store {
+	return &store{data: map[int]string{}}
+}
+
+func (s *store) write(_ context.Context, key int, value string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.data[key] = value
+	return nil
+}
+
+func (s *store) delete(_ context.Context, key int) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.data, key)
+	return nil
+}
+
+func newWriteThrough(t *testing.T, s *store,
+	options ...func(o *writethrough.Options[int])) *writethrough.Cache[int, string] {
+	t.Helper()
+	inner, err := lru.New[int, string](cachetypes.WithCapacity(4))
+	require.NoError(t, err)
+	c, err := writethrough.New(inner, s.write, options...)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Shutdown(context.Background()) })
+	return c
+}
+
+func TestNewNilWriter(t *testing.T) {
+	inner, err := lru.New[int, string](cachetypes.WithCapacity(1))
+	require.NoError(t, err)
+	defer inner.Shutdown(context.Background())
+
+	var aerr *cachetypes.InvalidOptionsError
+	_, err = writethrough.New[int, string](inner, nil)
+	require.ErrorAs(t, err, &aerr)
+}
+
+func TestPutWritesThrough(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []func(o *writethrough.Options[int])
+	}{
+		{"before", nil},
+		{"after", []func(o *writethrough.Options[int]){writethrough.WithWriteAfter[int]()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newStore()
+			c := newWriteThrough(t, s, tc.options...)
+
+			require.NoError(t, c.Put(ctx, 1, "one"))
+			require.Equal(t, "one", s.data[1])
+			v, ok, err := c.Get(ctx, 1)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, "one", v)
+
+			// a failed write leaves both the store and the cache unchanged
+			errStore := errors.New("store down")
+			s.err = errStore
+			require.ErrorIs(t, c.Put(ctx, 1, "uno"), errStore)
+			require.ErrorIs(t, c.Put(ctx, 2, "two"), errStore)
+			require.Equal(t, map[int]string{1: "one"}, s.data)
+
+			v, ok, err = c.Get(ctx, 1)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, "one", v)
+			ok, err = c.Has(ctx, 2)
+			require.NoError(t, err)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestReplaceWritesThrough(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []func(o *writethrough.Options[int])
+	}{
+		{"before", nil},
+		{"after", []func(o *writethrough.Options[int]){writethrough.WithWriteAfter[int]()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newStore()
+			c := newWriteThrough(t, s, tc.options...)
+
+			// an absent key is neither cached nor written
+			_, found, err := c.Replace(ctx, 1, "one")
+			require.NoError(t, err)
+			require.False(t, found)
+			require.Empty(t, s.data)
+
+			require.NoError(t, c.Put(ctx, 1, "one"))
+			old, found, err := c.Replace(ctx, 1, "uno")
+			require.NoError(t, err)
+			require.True(t, found)
+			require.Equal(t, "one", old)
+			require.Equal(t, "uno", s.data[1])
+
+			errStore := errors.New("store down")
+			s.err = errStore
+			_, _, err = c.Replace(ctx, 1, "eins")
+			require.ErrorIs(t, err, errStore)
+			v, _, err := c.Get(ctx, 1)
+			require.NoError(t, err)
+			require.Equal(t, "uno", v)
+		})
+	}
+}
+
+func TestDeleteCallsDeleter(t *testing.T) {
+	ctx := context.Background()
+	s := newStore()
+	c := newWriteThrough(t, s, writethrough.WithDeleter[int](s.delete))
+
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.NoError(t, c.Put(ctx, 2, "two"))
+
+	found, err := c.Delete(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NotContains(t, s.data, 1)
+
+	v, found, err := c.GetAndDelete(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "two", v)
+	require.Empty(t, s.data)
+
+	// a failed delete leaves the cache untouched
+	require.NoError(t, c.Put(ctx, 3, "three"))
+	errStore := errors.New("store down")
+	s.err = errStore
+	_, err = c.Delete(ctx, 3)
+	require.ErrorIs(t, err, errStore)
+	_, _, err = c.GetAndDelete(ctx, 3)
+	require.ErrorIs(t, err, errStore)
+	ok, err := c.Has(ctx, 3)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestResetKeepsStore(t *testing.T) {
+	ctx := context.Background()
+	s := newStore()
+	c := newWriteThrough(t, s)
+
+	require.NoError(t, c.Put(ctx, 1, "one"))
+	require.NoError(t, c.Reset(ctx))
+	size, err := c.Size()
+	require.NoError(t, err)
+	require.Zero(t, size)
+	require.Equal(t, "one", s.data[1])
+}